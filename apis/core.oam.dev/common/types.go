@@ -255,6 +255,14 @@ type WorkflowStepStatus struct {
 	FirstExecuteTime metav1.Time `json:"firstExecuteTime,omitempty"`
 	// LastExecuteTime is the last time this step execution.
 	LastExecuteTime metav1.Time `json:"lastExecuteTime,omitempty"`
+	// Attempts is the number of times this step has been executed, including retries.
+	Attempts int `json:"attempts,omitempty"`
+	// NextRetryTime is the earliest time the failed step will be retried.
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+	// ResumeTime is the time the suspended step will be resumed automatically.
+	ResumeTime *metav1.Time `json:"resumeTime,omitempty"`
+	// Approval records the approval of the suspend step in approval mode.
+	Approval *WorkflowStepApproval `json:"approval,omitempty"`
 }
 
 // WorkflowSubStepStatus record the status of a workflow step
@@ -267,6 +275,32 @@ type WorkflowSubStepStatus struct {
 	Message string `json:"message,omitempty"`
 	// A brief CamelCase message indicating details about why the workflowStep is in this state.
 	Reason string `json:"reason,omitempty"`
+	// FirstExecuteTime is the first time this step execution.
+	FirstExecuteTime metav1.Time `json:"firstExecuteTime,omitempty"`
+	// LastExecuteTime is the last time this step execution.
+	LastExecuteTime metav1.Time `json:"lastExecuteTime,omitempty"`
+	// Attempts is the number of times this step has been executed, including retries.
+	Attempts int `json:"attempts,omitempty"`
+	// NextRetryTime is the earliest time the failed step will be retried.
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+	// ResumeTime is the time the suspended step will be resumed automatically.
+	ResumeTime *metav1.Time `json:"resumeTime,omitempty"`
+	// Approval records the approval of the suspend step in approval mode.
+	Approval *WorkflowStepApproval `json:"approval,omitempty"`
+}
+
+// WorkflowStepApproval records the approval of a suspend step
+type WorkflowStepApproval struct {
+	// Approver is the identity who approved the step.
+	Approver string `json:"approver,omitempty"`
+	// Unverified indicates the approver is only claimed by the client which resumed the workflow
+	// and is not an authenticated identity.
+	Unverified bool `json:"unverified,omitempty"`
+	// ApproveTime is the time the step is approved.
+	ApproveTime *metav1.Time `json:"approveTime,omitempty"`
+	// ExpireTime is the deadline of the approval, the step is failed or the workflow is terminated
+	// if the step isn't approved before it.
+	ExpireTime *metav1.Time `json:"expireTime,omitempty"`
 }
 
 // AppStatus defines the observed state of Application
@@ -304,6 +338,18 @@ type AppStatus struct {
 
 	// PolicyStatus records the status of policy
 	PolicyStatus []PolicyStatus `json:"policy,omitempty"`
+
+	// DriftedResources record the applied resources whose live states differ from the applied configurations
+	DriftedResources []DriftedResource `json:"driftedResources,omitempty"`
+}
+
+// DriftedResource records an applied resource that has drifted from its applied configuration
+type DriftedResource struct {
+	ClusterObjectReference `json:",inline"`
+	// Missing indicates the resource is no longer found
+	Missing bool `json:"missing,omitempty"`
+	// Fields are the paths of the drifted fields
+	Fields []string `json:"fields,omitempty"`
 }
 
 // PolicyStatus records the status of policy
@@ -348,6 +394,10 @@ const (
 	WorkflowStepPhaseStopped WorkflowStepPhase = "stopped"
 	// WorkflowStepPhaseRunning will make the controller continue the workflow.
 	WorkflowStepPhaseRunning WorkflowStepPhase = "running"
+	// WorkflowStepPhaseTimedOut will make the controller stop the workflow as the step exceeds its timeout.
+	WorkflowStepPhaseTimedOut WorkflowStepPhase = "timedOut"
+	// WorkflowStepPhaseSkipped will make the controller skip the step and continue the workflow.
+	WorkflowStepPhaseSkipped WorkflowStepPhase = "skipped"
 )
 
 // DefinitionType describes the type of DefinitionRevision.
@@ -89,6 +89,13 @@ func (in *AppStatus) DeepCopyInto(out *AppStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DriftedResources != nil {
+		in, out := &in.DriftedResources, &out.DriftedResources
+		*out = make([]DriftedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppStatus.
@@ -337,6 +344,27 @@ func (in *Distribution) DeepCopy() *Distribution {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftedResource) DeepCopyInto(out *DriftedResource) {
+	*out = *in
+	out.ClusterObjectReference = in.ClusterObjectReference
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftedResource.
+func (in *DriftedResource) DeepCopy() *DriftedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Helm) DeepCopyInto(out *Helm) {
 	*out = *in
@@ -572,7 +600,9 @@ func (in *SubStepsStatus) DeepCopyInto(out *SubStepsStatus) {
 	if in.Steps != nil {
 		in, out := &in.Steps, &out.Steps
 		*out = make([]WorkflowSubStepStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -630,6 +660,29 @@ func (in *WorkflowStatus) DeepCopy() *WorkflowStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkflowStepApproval) DeepCopyInto(out *WorkflowStepApproval) {
+	*out = *in
+	if in.ApproveTime != nil {
+		in, out := &in.ApproveTime, &out.ApproveTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpireTime != nil {
+		in, out := &in.ExpireTime, &out.ExpireTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowStepApproval.
+func (in *WorkflowStepApproval) DeepCopy() *WorkflowStepApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkflowStepApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowStepStatus) DeepCopyInto(out *WorkflowStepStatus) {
 	*out = *in
@@ -640,6 +693,19 @@ func (in *WorkflowStepStatus) DeepCopyInto(out *WorkflowStepStatus) {
 	}
 	in.FirstExecuteTime.DeepCopyInto(&out.FirstExecuteTime)
 	in.LastExecuteTime.DeepCopyInto(&out.LastExecuteTime)
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ResumeTime != nil {
+		in, out := &in.ResumeTime, &out.ResumeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(WorkflowStepApproval)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowStepStatus.
@@ -655,6 +721,21 @@ func (in *WorkflowStepStatus) DeepCopy() *WorkflowStepStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkflowSubStepStatus) DeepCopyInto(out *WorkflowSubStepStatus) {
 	*out = *in
+	in.FirstExecuteTime.DeepCopyInto(&out.FirstExecuteTime)
+	in.LastExecuteTime.DeepCopyInto(&out.LastExecuteTime)
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ResumeTime != nil {
+		in, out := &in.ResumeTime, &out.ResumeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(WorkflowStepApproval)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkflowSubStepStatus.
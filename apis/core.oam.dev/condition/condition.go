@@ -42,6 +42,10 @@ const (
 	// TypeSynced resources are believed to be in sync with the
 	// Kubernetes resources that manage their lifecycle.
 	TypeSynced ConditionType = "Synced"
+
+	// TypeDrifted resources have drifted from the configurations
+	// applied by their managers.
+	TypeDrifted ConditionType = "Drifted"
 )
 
 // A ConditionReason represents the reason a resource is in a condition.
@@ -62,6 +66,12 @@ const (
 	ReasonReconcileError   ConditionReason = "ReconcileError"
 )
 
+// Reasons a resource is or is not drifted.
+const (
+	ReasonDriftDetected ConditionReason = "DriftDetected"
+	ReasonNoDrift       ConditionReason = "NoDrift"
+)
+
 // A Condition that may apply to a resource.
 type Condition struct {
 	// Type of this condition. At most one of each condition type may apply to
@@ -260,6 +270,30 @@ func ReconcileError(err error) Condition {
 	}
 }
 
+// Drifted returns a condition indicating that the resources managed by the
+// resource have drifted from their applied configurations.
+func Drifted(msg string) Condition {
+	return Condition{
+		Type:               TypeDrifted,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDriftDetected,
+		Message:            msg,
+	}
+}
+
+// NoDrift returns a condition indicating that the resources managed by the
+// resource are in line with their applied configurations.
+func NoDrift(msg string) Condition {
+	return Condition{
+		Type:               TypeDrifted,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNoDrift,
+		Message:            msg,
+	}
+}
+
 // ReadyCondition generate ready condition for conditionType
 func ReadyCondition(tpy string) Condition {
 	return Condition{
@@ -28,6 +28,9 @@ const (
 
 	// GarbageCollectPolicyType refers to the type of garbage-collect
 	GarbageCollectPolicyType = "garbage-collect"
+
+	// PreserveFieldsPolicyType refers to the type of preserve-fields
+	PreserveFieldsPolicyType = "preserve-fields"
 )
 
 // EnvTraitPatch is the patch to trait
@@ -62,6 +62,85 @@ type WorkflowStep struct {
 	Inputs common.StepInputs `json:"inputs,omitempty"`
 
 	Outputs common.StepOutputs `json:"outputs,omitempty"`
+
+	// If is a CUE expression evaluated before the step is executed, the step will be skipped if it evaluates to false.
+	// The expression can reference `context` (the application metadata), `outputs` (the outputs of the previous steps)
+	// and `status` (the status of the previous steps, e.g. `status.deploy.phase == "failed"`).
+	// Steps whose names are not valid CUE identifiers are referenced with brackets, e.g. `status["my-step"].phase`.
+	// The step will be failed if the expression can't be evaluated.
+	// Steps without `if` will be skipped once any previous step is failed.
+	If string `json:"if,omitempty"`
+
+	// Timeout is the max duration (e.g. "10m") the step can take since its first execution,
+	// the step will be marked as timedOut and the workflow will be terminated once exceeded.
+	Timeout string `json:"timeout,omitempty"`
+
+	// Retries is the max number of retries after the step failed, the workflow will be terminated
+	// once the retries are exhausted. If not specified, the failed step will be retried endlessly.
+	Retries *int `json:"retries,omitempty"`
+
+	// Backoff defines how long to wait before retrying the failed step.
+	Backoff *WorkflowStepBackoff `json:"backoff,omitempty"`
+
+	// SubSteps are the nested steps executed by the `step-group` step.
+	SubSteps []WorkflowSubStep `json:"subSteps,omitempty"`
+}
+
+// WorkflowSubStep defines how to execute a sub step of the step group.
+type WorkflowSubStep struct {
+	// Name is the unique name of the sub step in the step group.
+	Name string `json:"name"`
+
+	Type string `json:"type"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Properties *runtime.RawExtension `json:"properties,omitempty"`
+
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	Inputs common.StepInputs `json:"inputs,omitempty"`
+
+	Outputs common.StepOutputs `json:"outputs,omitempty"`
+
+	// If is a CUE expression evaluated before the sub step is executed, see WorkflowStep.If.
+	If string `json:"if,omitempty"`
+
+	// Timeout is the max duration the sub step can take since its first execution.
+	Timeout string `json:"timeout,omitempty"`
+
+	// Retries is the max number of retries after the sub step failed.
+	Retries *int `json:"retries,omitempty"`
+
+	// Backoff defines how long to wait before retrying the failed sub step.
+	Backoff *WorkflowStepBackoff `json:"backoff,omitempty"`
+}
+
+// WorkflowStep converts the sub step to a workflow step.
+func (s WorkflowSubStep) WorkflowStep() WorkflowStep {
+	return WorkflowStep{
+		Name:       s.Name,
+		Type:       s.Type,
+		Properties: s.Properties,
+		DependsOn:  s.DependsOn,
+		Inputs:     s.Inputs,
+		Outputs:    s.Outputs,
+		If:         s.If,
+		Timeout:    s.Timeout,
+		Retries:    s.Retries,
+		Backoff:    s.Backoff,
+	}
+}
+
+// WorkflowStepBackoff defines the backoff policy for retrying a failed workflow step.
+type WorkflowStepBackoff struct {
+	// Duration is the interval (e.g. "10s") to wait before the first retry.
+	Duration string `json:"duration,omitempty"`
+
+	// Factor multiplies the interval after each retry, defaults to 1.
+	Factor int `json:"factor,omitempty"`
+
+	// MaxDuration is the upper limit of the interval.
+	MaxDuration string `json:"maxDuration,omitempty"`
 }
 
 // Workflow defines workflow steps and other attributes
@@ -144,6 +144,14 @@ var (
 	ClusterKindVersionKind = SchemeGroupVersion.WithKind(ClusterKind)
 )
 
+// WorkflowRun type metadata.
+var (
+	WorkflowRunKind             = reflect.TypeOf(WorkflowRun{}).Name()
+	WorkflowRunGroupKind        = schema.GroupKind{Group: Group, Kind: WorkflowRunKind}.String()
+	WorkflowRunKindAPIVersion   = WorkflowRunKind + "." + SchemeGroupVersion.String()
+	WorkflowRunGroupVersionKind = SchemeGroupVersion.WithKind(WorkflowRunKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&ComponentDefinition{}, &ComponentDefinitionList{})
 	SchemeBuilder.Register(&WorkloadDefinition{}, &WorkloadDefinitionList{})
@@ -158,6 +166,7 @@ func init() {
 	SchemeBuilder.Register(&AppDeployment{}, &AppDeploymentList{})
 	SchemeBuilder.Register(&Cluster{}, &ClusterList{})
 	SchemeBuilder.Register(&ResourceTracker{}, &ResourceTrackerList{})
+	SchemeBuilder.Register(&WorkflowRun{}, &WorkflowRunList{})
 }
 
 // Resource takes an unqualified resource and returns a Group qualified GroupResource
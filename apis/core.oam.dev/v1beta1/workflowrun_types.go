@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+// WorkflowRunSpec is the snapshot of the application workflow when it runs.
+type WorkflowRunSpec struct {
+	// AppName is the name of the application which runs the workflow.
+	AppName string `json:"appName"`
+	// AppRevision is the name of the application revision the workflow runs for.
+	AppRevision string `json:"appRevision,omitempty"`
+	// Workflow is the workflow defined in the application.
+	Workflow *Workflow `json:"workflow,omitempty"`
+}
+
+// WorkflowRunStatus is the result of the workflow execution.
+type WorkflowRunStatus struct {
+	// Phase is the final state of the workflow execution, succeeded or terminated.
+	Phase common.WorkflowState `json:"phase,omitempty"`
+	// Mode is the execution mode of the workflow, StepByStep or DAG.
+	Mode common.WorkflowMode `json:"mode,omitempty"`
+	// StartTime is the time the workflow starts.
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// EndTime is the time the workflow is finished.
+	EndTime metav1.Time `json:"endTime,omitempty"`
+	// Steps record the status of the workflow steps.
+	Steps []common.WorkflowStepStatus `json:"steps,omitempty"`
+	// Outputs are the variables exported by the workflow steps, in CUE format.
+	Outputs string `json:"outputs,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WorkflowRun records a finished execution of the application workflow
+// +kubebuilder:resource:categories={oam},shortName=wfrun
+// +kubebuilder:printcolumn:name="APP",type=string,JSONPath=`.spec.appName`
+// +kubebuilder:printcolumn:name="REVISION",type=string,JSONPath=`.spec.appRevision`
+// +kubebuilder:printcolumn:name="PHASE",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=".metadata.creationTimestamp"
+type WorkflowRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WorkflowRunSpec   `json:"spec,omitempty"`
+	Status WorkflowRunStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WorkflowRunList contains a list of WorkflowRun
+type WorkflowRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WorkflowRun `json:"items"`
+}
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
@@ -199,6 +200,48 @@ type RolloutWebhook struct {
 	// Metadata (key-value pairs) for this webhook
 	// +optional
 	Metadata *map[string]string `json:"metadata,omitempty"`
+
+	// SigningSecretRef references the key of a Secret in the namespace of the rollout, its value is used to
+	// sign the request body with HMAC-SHA256, the signature is sent in the X-Vela-Signature header as sha256=<hex>
+	// +optional
+	SigningSecretRef *corev1.SecretKeySelector `json:"signingSecretRef,omitempty"`
+
+	// BearerTokenRef references the key of a Secret in the namespace of the rollout, its value is sent
+	// as the bearer token in the Authorization header
+	// +optional
+	BearerTokenRef *corev1.SecretKeySelector `json:"bearerTokenRef,omitempty"`
+
+	// TimeoutSeconds is the timeout of each request to the webhook, default is 10 seconds
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// Retries is the number of the retries if the request failed or the webhook returns a server error, default is 3
+	// +optional
+	Retries *int32 `json:"retries,omitempty"`
+}
+
+// RolloutWebhookStatus records the last response of a rollout webhook
+type RolloutWebhookStatus struct {
+	// Name of the webhook
+	Name string `json:"name"`
+
+	// Phase of the rollout when the webhook is called
+	Phase string `json:"phase"`
+
+	// StatusCode is the http status code returned by the webhook
+	// +optional
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// Response is the body returned by the webhook, it is truncated if it's too long
+	// +optional
+	Response string `json:"response,omitempty"`
+
+	// Message explains why the call failed
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastCallTime is the last time the webhook is called
+	LastCallTime metav1.Time `json:"lastCallTime"`
 }
 
 // RolloutWebhookPayload holds the info and metadata sent to webhooks
@@ -229,10 +272,55 @@ type CanaryMetric struct {
 	MetricsRange *MetricsExpectedRange `json:"metricsRange,omitempty"`
 
 	// TemplateRef references a metric template object
+	// it is a ConfigMap with the `query`, `provider` and `address` keys,
+	// the fields set in the metric itself take precedence over the template
 	// +optional
 	TemplateRef *corev1.ObjectReference `json:"templateRef,omitempty"`
+
+	// Query is the query sent to the metrics provider, it is rendered as a go template
+	// with the `.Name`, `.Namespace` and `.Interval` of the rollout target
+	// +optional
+	Query string `json:"query,omitempty"`
+
+	// Provider is the metrics provider to evaluate the query
+	// +optional
+	Provider *MetricProvider `json:"provider,omitempty"`
+
+	// FailurePolicy decides what to do when the metric is out of the expected range,
+	// the rollout is paused by default
+	// +optional
+	FailurePolicy MetricFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// MetricProviderType is the type of the metrics provider
+type MetricProviderType string
+
+const (
+	// PrometheusMetricProvider queries the metric from the prometheus http api
+	PrometheusMetricProvider MetricProviderType = "prometheus"
+)
+
+// MetricProvider holds the address of the metrics provider
+type MetricProvider struct {
+	// Type of the provider, default is prometheus
+	// +optional
+	Type MetricProviderType `json:"type,omitempty"`
+
+	// Address of the provider, ex: http://prometheus.monitoring:9090
+	Address string `json:"address"`
 }
 
+// MetricFailurePolicy is the action taken when the metric is out of the expected range
+type MetricFailurePolicy string
+
+const (
+	// PauseOnMetricFailure holds the rollout at the current batch until the metric is back in range
+	PauseOnMetricFailure MetricFailurePolicy = "Pause"
+	// RollbackOnMetricFailure reverts the upgraded pods to the source revision and then fails the rollout,
+	// the workloads that can't be reverted are only released
+	RollbackOnMetricFailure MetricFailurePolicy = "Rollback"
+)
+
 // MetricsExpectedRange defines the range used for metrics validation
 type MetricsExpectedRange struct {
 	// Minimum value
@@ -282,4 +370,8 @@ type RolloutStatus struct {
 
 	// UpgradedReadyReplicas is the number of Pods upgraded by the rollout controller that have a Ready Condition.
 	UpgradedReadyReplicas int32 `json:"upgradedReadyReplicas"`
+
+	// WebhookStatus records the last response of each rollout webhook
+	// +optional
+	WebhookStatus []RolloutWebhookStatus `json:"webhookStatus,omitempty"`
 }
@@ -91,6 +91,8 @@ const (
 	RolloutFinalizing condition.ConditionType = "RolloutFinalizing"
 	// RolloutFailing means the rollout is failing
 	RolloutFailing condition.ConditionType = "RolloutFailing"
+	// RolloutRollingBack means the upgraded pods are being reverted to the source revision before the rollout fails
+	RolloutRollingBack condition.ConditionType = "RolloutRollingBack"
 	// RolloutAbandoning means that the rollout is being abandoned.
 	RolloutAbandoning condition.ConditionType = "RolloutAbandoning"
 	// RolloutDeleting means that the rollout is being deleted.
@@ -39,6 +39,11 @@ func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.Provider != nil {
+		in, out := &in.Provider, &out.Provider
+		*out = new(MetricProvider)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetric.
@@ -67,6 +72,21 @@ func (in *CompRolloutStatus) DeepCopy() *CompRolloutStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricProvider) DeepCopyInto(out *MetricProvider) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricProvider.
+func (in *MetricProvider) DeepCopy() *MetricProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricsExpectedRange) DeepCopyInto(out *MetricsExpectedRange) {
 	*out = *in
@@ -267,6 +287,13 @@ func (in *RolloutSpec) DeepCopy() *RolloutSpec {
 func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
 	*out = *in
 	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.WebhookStatus != nil {
+		in, out := &in.WebhookStatus, &out.WebhookStatus
+		*out = make([]RolloutWebhookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
@@ -298,6 +325,26 @@ func (in *RolloutWebhook) DeepCopyInto(out *RolloutWebhook) {
 			}
 		}
 	}
+	if in.SigningSecretRef != nil {
+		in, out := &in.SigningSecretRef, &out.SigningSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BearerTokenRef != nil {
+		in, out := &in.BearerTokenRef, &out.BearerTokenRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutWebhook.
@@ -331,3 +378,19 @@ func (in *RolloutWebhookPayload) DeepCopy() *RolloutWebhookPayload {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutWebhookStatus) DeepCopyInto(out *RolloutWebhookStatus) {
+	*out = *in
+	in.LastCallTime.DeepCopyInto(&out.LastCallTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutWebhookStatus.
+func (in *RolloutWebhookStatus) DeepCopy() *RolloutWebhookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutWebhookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -226,6 +226,8 @@ type AddonDeployTo struct {
 // AddonDependency defines the other addons it depends on
 type AddonDependency struct {
 	Name string `json:"name,omitempty"`
+	// Version is the semver constraint of the dependent addon, e.g. ">=1.2.0, <2.0.0", any version is accepted if not specified
+	Version string `json:"version,omitempty"`
 }
 
 // AddonElementFile can be addon's definition or addon's component
@@ -18,22 +18,26 @@ package types
 
 // reason for Application
 const (
-	ReasonParsed      = "Parsed"
-	ReasonRendered    = "Rendered"
-	ReasonRevisoned   = "Revisioned"
-	ReasonApplied     = "Applied"
-	ReasonHealthCheck = "HealthChecked"
-	ReasonDeployed    = "Deployed"
-	ReasonRollout     = "Rollout"
-
-	ReasonFailedParse       = "FailedParse"
-	ReasonFailedRender      = "FailedRender"
-	ReasonFailedRevision    = "FailedRevision"
-	ReasonFailedWorkflow    = "FailedWorkflow"
-	ReasonFailedApply       = "FailedApply"
-	ReasonFailedHealthCheck = "FailedHealthCheck"
-	ReasonFailedGC          = "FailedGC"
-	ReasonFailedRollout     = "FailedRollout"
+	ReasonParsed         = "Parsed"
+	ReasonRendered       = "Rendered"
+	ReasonRevisoned      = "Revisioned"
+	ReasonApplied        = "Applied"
+	ReasonHealthCheck    = "HealthChecked"
+	ReasonDeployed       = "Deployed"
+	ReasonRollout        = "Rollout"
+	ReasonDrifted        = "Drifted"
+	ReasonDriftCorrected = "DriftCorrected"
+	ReasonDriftUntracked = "DriftUntracked"
+
+	ReasonFailedParse          = "FailedParse"
+	ReasonFailedRender         = "FailedRender"
+	ReasonFailedRevision       = "FailedRevision"
+	ReasonFailedWorkflow       = "FailedWorkflow"
+	ReasonFailedApply          = "FailedApply"
+	ReasonFailedHealthCheck    = "FailedHealthCheck"
+	ReasonFailedGC             = "FailedGC"
+	ReasonFailedRollout        = "FailedRollout"
+	ReasonFailedDriftDetection = "FailedDriftDetection"
 )
 
 // event message for Application
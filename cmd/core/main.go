@@ -101,6 +101,10 @@ func main() {
 		"RevisionLimit is the maximum number of revisions that will be maintained. The default value is 50.")
 	flag.IntVar(&controllerArgs.AppRevisionLimit, "application-revision-limit", 10,
 		"application-revision-limit is the maximum number of application useless revisions that will be maintained, if the useless revisions exceed this number, older ones will be GCed first.The default value is 10.")
+	flag.IntVar(&controllerArgs.WorkflowRunLimit, "workflow-run-limit", 10,
+		"workflow-run-limit is the maximum number of finished workflow runs that will be maintained for each application, older ones will be GCed first. Set it to 0 to disable recording workflow runs. The default value is 10.")
+	flag.DurationVar(&controllerArgs.DriftDetectionInterval, "drift-detection-interval", 0,
+		"drift-detection-interval is the interval to compare the resources applied by applications with their applied configurations and report the drifted fields in the application status. Set it to 0 to disable the drift detection. The default value is 0.")
 	flag.IntVar(&controllerArgs.DefRevisionLimit, "definition-revision-limit", 20,
 		"definition-revision-limit is the maximum number of component/trait definition useless revisions that will be maintained, if the useless revisions exceed this number, older ones will be GCed first.The default value is 20.")
 	flag.StringVar(&controllerArgs.CustomRevisionHookURL, "custom-revision-hook-url", "",
@@ -23,7 +23,6 @@ import (
 	"net/url"
 	"path"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -37,7 +36,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	k8syaml "k8s.io/apimachinery/pkg/runtime/serializer/yaml"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
 	common2 "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
@@ -123,7 +121,7 @@ func (r *asyncReader) SetReadContent(content *github.RepositoryContent) {
 }
 
 // GetAddon get a addon info from GitAddonSource, can be used for get or enable
-func GetAddon(name string, git *GitAddonSource, opt ListOptions) (*types.Addon, error) {
+func (git *GitAddonSource) GetAddon(name string, opt ListOptions) (*types.Addon, error) {
 	addon, err := getSingleAddonFromGit(git.URL, git.Path, name, git.Token, opt)
 	if err != nil {
 		return nil, err
@@ -132,7 +130,7 @@ func GetAddon(name string, git *GitAddonSource, opt ListOptions) (*types.Addon,
 }
 
 // ListAddons list addons' info from GitAddonSource
-func ListAddons(git *GitAddonSource, opt ListOptions) ([]*types.Addon, error) {
+func (git *GitAddonSource) ListAddons(opt ListOptions) ([]*types.Addon, error) {
 	gitAddons, err := getAddonsFromGit(git.URL, git.Path, git.Token, opt)
 	if err != nil {
 		return nil, err
@@ -242,15 +240,22 @@ func readTemplate(wg *sync.WaitGroup, reader asyncReader) {
 		reader.errChan <- err
 		return
 	}
-	dec := k8syaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
-	reader.addon.AppTemplate = &v1beta1.Application{}
-	_, _, err = dec.Decode([]byte(data), nil, reader.addon.AppTemplate)
+	reader.addon.AppTemplate, err = decodeAppTemplate(data)
 	if err != nil {
 		reader.errChan <- err
 		return
 	}
 }
 
+func decodeAppTemplate(data string) (*v1beta1.Application, error) {
+	dec := k8syaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+	app := &v1beta1.Application{}
+	if _, _, err := dec.Decode([]byte(data), nil, app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
 func readResources(wg *sync.WaitGroup, reader asyncReader) {
 	defer wg.Done()
 	dirPath := strings.Split(reader.item.GetPath(), "/")
@@ -455,7 +460,8 @@ func cutPathUntil(path []string, end string) ([]string, error) {
 
 // RenderApplication render a K8s application
 func RenderApplication(addon *types.Addon, args map[string]interface{}) (*v1beta1.Application, []*unstructured.Unstructured, error) {
-	app := addon.AppTemplate
+	// copy the template to keep the addon unchanged, since the addon may be cached and rendered again
+	app := addon.AppTemplate.DeepCopy()
 	if app == nil {
 		app = &v1beta1.Application{
 			TypeMeta: metav1.TypeMeta{APIVersion: "core.oam.dev/v1beta1", Kind: "Application"},
@@ -473,6 +479,15 @@ func RenderApplication(addon *types.Addon, args map[string]interface{}) (*v1beta
 	}
 	app.Name = Convert2AppName(addon.Name)
 	app.Labels = util.MergeMapOverrideWithDst(app.Labels, map[string]string{oam.LabelAddonName: addon.Name})
+	if addon.Version != "" {
+		app.Labels[oam.LabelAddonVersion] = addon.Version
+	}
+	// the annotation is always recorded, so that the addons without dependencies can be told from the legacy ones
+	var deps []string
+	for _, dep := range addon.Dependencies {
+		deps = append(deps, dep.Name)
+	}
+	app.Annotations = util.MergeMapOverrideWithDst(app.Annotations, map[string]string{oam.AnnotationAddonDependencies: strings.Join(deps, ",")})
 	if app.Spec.Workflow == nil {
 		app.Spec.Workflow = &v1beta1.Workflow{}
 	}
@@ -629,16 +644,22 @@ func Convert2AddonName(name string) string {
 	return strings.TrimPrefix(name, addonAppPrefix)
 }
 
-// RenderArgsSecret TODO add desc
+// RenderArgsSecret renders the secret recording the args of the addon. The strings are recorded as is and the other
+// values are JSON encoded, so that the objects and arrays can be read back by GetArgsFromSecret.
 func RenderArgsSecret(addon *types.Addon, args map[string]interface{}) *v1.Secret {
 	data := make(map[string]string)
 	for k, v := range args {
-		switch v := v.(type) {
-		case bool:
-			data[k] = strconv.FormatBool(v)
-		default:
+		if s, ok := v.(string); ok {
+			data[k] = s
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			// only the values such as NaN can't be encoded
 			data[k] = fmt.Sprintf("%v", v)
+			continue
 		}
+		data[k] = string(b)
 	}
 	sec := v1.Secret{
 		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
@@ -656,18 +677,3 @@ func RenderArgsSecret(addon *types.Addon, args map[string]interface{}) *v1.Secre
 func Convert2SecName(name string) string {
 	return addonSecPrefix + name
 }
-
-// CheckDependencies checks if addon's dependent addons is enabled
-func CheckDependencies(ctx context.Context, clt client.Client, addon *types.Addon) bool {
-	var app v1beta1.Application
-	for _, dep := range addon.Dependencies {
-		err := clt.Get(ctx, client.ObjectKey{
-			Namespace: types.DefaultKubeVelaNS,
-			Name:      Convert2AppName(dep.Name),
-		}, &app)
-		if err != nil {
-			return false
-		}
-	}
-	return true
-}
@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	common2 "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/pkg/policy/envbinding"
+)
+
+// ClusterPolicyName is the name of the env-binding policy which places the addon application to the target clusters
+const ClusterPolicyName = "addon-clusters"
+
+// ClusterStatus is the status of the addon in one of the target clusters
+type ClusterStatus struct {
+	Cluster string `json:"cluster"`
+	Env     string `json:"env"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+// NewClusterSelectors builds the cluster selectors by cluster names and labels, one selector for each name and one for the labels
+func NewClusterSelectors(names []string, labels map[string]string) []common2.ClusterSelector {
+	var selectors []common2.ClusterSelector
+	for _, name := range names {
+		selectors = append(selectors, common2.ClusterSelector{Name: name})
+	}
+	if len(labels) != 0 {
+		selectors = append(selectors, common2.ClusterSelector{Labels: labels})
+	}
+	return selectors
+}
+
+// ValidateClusterSelectors checks if the clusters selected by name have been joined
+func ValidateClusterSelectors(ctx context.Context, clt client.Client, selectors []common2.ClusterSelector) error {
+	for _, s := range selectors {
+		if s.Name == "" && len(s.Labels) == 0 {
+			return errors.New("either name or labels should be specified in the cluster selector")
+		}
+		if s.Name == "" || s.Name == multicluster.ClusterLocalName {
+			continue
+		}
+		err := clt.Get(ctx, client.ObjectKey{Namespace: multicluster.ClusterGatewaySecretNamespace, Name: s.Name}, &v1.Secret{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return errors.Errorf("cluster %s not found", s.Name)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderClusterPlacement makes the addon application deploy to the selected clusters. An env-binding policy with one env
+// for each selector is added, and the steps deploying to all the runtime clusters are replaced by deploy2env steps.
+// The definitions rendered as components are moved to the returned definition objects, since they are only needed
+// in the control plane. The application and the definition objects are unchanged if no selector is given.
+func RenderClusterPlacement(app *v1beta1.Application, defObjs []*unstructured.Unstructured, selectors []common2.ClusterSelector) ([]*unstructured.Unstructured, error) {
+	if len(selectors) == 0 {
+		return defObjs, nil
+	}
+	var comps []common2.ApplicationComponent
+	for _, comp := range app.Spec.Components {
+		obj, err := definitionOfComponent(comp)
+		if err != nil {
+			return nil, err
+		}
+		if obj != nil {
+			defObjs = append(defObjs, obj)
+			continue
+		}
+		comps = append(comps, comp)
+	}
+	app.Spec.Components = comps
+
+	spec := v1alpha1.EnvBindingSpec{}
+	for i, s := range selectors {
+		name := s.Name
+		if name == "" {
+			name = fmt.Sprintf("selector-%d", i)
+		}
+		selector := s
+		spec.Envs = append(spec.Envs, v1alpha1.EnvConfig{
+			Name:      name,
+			Placement: v1alpha1.EnvPlacement{ClusterSelector: &selector},
+		})
+	}
+
+	var policies []v1beta1.AppPolicy
+	for _, p := range app.Spec.Policies {
+		if p.Name != ClusterPolicyName {
+			policies = append(policies, p)
+		}
+	}
+	app.Spec.Policies = append(policies, v1beta1.AppPolicy{
+		Name:       ClusterPolicyName,
+		Type:       v1alpha1.EnvBindingPolicyType,
+		Properties: util.Object2RawExtension(spec),
+	})
+
+	if app.Spec.Workflow == nil {
+		app.Spec.Workflow = &v1beta1.Workflow{}
+	}
+	var steps []v1beta1.WorkflowStep
+	for _, step := range app.Spec.Workflow.Steps {
+		if step.Type != "deploy2runtime" {
+			steps = append(steps, step)
+		}
+	}
+	for _, env := range spec.Envs {
+		steps = append(steps, v1beta1.WorkflowStep{
+			Name: "deploy-" + env.Name,
+			Type: "deploy2env",
+			Properties: util.Object2RawExtension(map[string]string{
+				"policy": ClusterPolicyName,
+				"env":    env.Name,
+			}),
+		})
+	}
+	app.Spec.Workflow.Steps = steps
+	return defObjs, nil
+}
+
+// definitionOfComponent returns the definition object if the component is a raw component of an X-Definition
+func definitionOfComponent(comp common2.ApplicationComponent) (*unstructured.Unstructured, error) {
+	if comp.Type != "raw" || comp.Properties == nil {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(comp.Properties.Raw, &obj.Object); err != nil {
+		return nil, errors.Wrapf(err, "fail to decode component %s", comp.Name)
+	}
+	if obj.GroupVersionKind().Group != v1beta1.Group || !strings.HasSuffix(obj.GetKind(), "Definition") {
+		return nil, nil
+	}
+	return obj, nil
+}
+
+// GetClusterSelectors returns the cluster selectors of the enabled addon application, it's used to keep
+// the target clusters when the addon is upgraded
+func GetClusterSelectors(app *v1beta1.Application) ([]common2.ClusterSelector, error) {
+	spec, err := envbinding.GetEnvBindingPolicy(app, ClusterPolicyName)
+	if err != nil || spec == nil {
+		return nil, err
+	}
+	var selectors []common2.ClusterSelector
+	for _, env := range spec.Envs {
+		if env.Placement.ClusterSelector != nil {
+			selectors = append(selectors, *env.Placement.ClusterSelector)
+		}
+	}
+	return selectors, nil
+}
+
+// GetClusterStatus returns the status of the addon in each target cluster, the health of a cluster is aggregated from
+// the components deployed by its env. Nil is returned if the addon is not deployed by the cluster policy.
+func GetClusterStatus(app *v1beta1.Application) ([]ClusterStatus, error) {
+	status, err := envbinding.GetEnvBindingPolicyStatus(app, ClusterPolicyName)
+	if err != nil || status == nil {
+		return nil, err
+	}
+	var res []ClusterStatus
+	for _, env := range status.Envs {
+		healthy, deployed := true, false
+		var messages []string
+		for _, svc := range app.Status.Services {
+			if svc.Env != env.Env {
+				continue
+			}
+			deployed = true
+			if !svc.Healthy {
+				healthy = false
+				messages = append(messages, fmt.Sprintf("%s: %s", svc.Name, svc.Message))
+			}
+			for _, trait := range svc.Traits {
+				if !trait.Healthy {
+					healthy = false
+					messages = append(messages, fmt.Sprintf("%s/%s: %s", svc.Name, trait.Type, trait.Message))
+				}
+			}
+		}
+		if !deployed {
+			healthy = false
+			messages = append(messages, "not deployed yet")
+		}
+		for _, placement := range env.Placements {
+			res = append(res, ClusterStatus{
+				Cluster: placement.Cluster,
+				Env:     env.Env,
+				Healthy: healthy,
+				Message: strings.Join(messages, "; "),
+			})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Cluster < res[j].Cluster
+	})
+	return res, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	common2 "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestRenderClusterPlacement(t *testing.T) {
+	addon := newTestAddon("example", "1.0.0")
+	addon.DeployTo = &types.AddonDeployTo{RuntimeCluster: true}
+	app, _, err := RenderApplication(addon, nil)
+	assert.NoError(t, err)
+
+	// unchanged without selectors
+	defs, err := RenderClusterPlacement(app, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, defs)
+	assert.Empty(t, app.Spec.Policies)
+	assert.Equal(t, 2, len(app.Spec.Workflow.Steps))
+
+	selectors := NewClusterSelectors([]string{"cluster-1"}, map[string]string{"region": "hangzhou"})
+	_, err = RenderClusterPlacement(app, nil, selectors)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(app.Spec.Policies))
+	assert.Equal(t, ClusterPolicyName, app.Spec.Policies[0].Name)
+	assert.Equal(t, v1alpha1.EnvBindingPolicyType, app.Spec.Policies[0].Type)
+	var stepTypes []string
+	for _, step := range app.Spec.Workflow.Steps {
+		stepTypes = append(stepTypes, step.Name+":"+step.Type)
+	}
+	assert.Equal(t, []string{"deploy-control-plane:apply-application", "deploy-cluster-1:deploy2env", "deploy-selector-1:deploy2env"}, stepTypes)
+
+	got, err := GetClusterSelectors(app)
+	assert.NoError(t, err)
+	assert.Equal(t, selectors, got)
+
+	// render again won't duplicate the policy
+	_, err = RenderClusterPlacement(app, nil, selectors)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(app.Spec.Policies))
+}
+
+func TestRenderClusterPlacementWithDefinitions(t *testing.T) {
+	addon, err := (&LocalAddonSource{Path: "./testdata"}).GetAddon("example", EnableLevelOptions)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, addon.Definitions)
+	app, defs, err := RenderApplication(addon, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, defs)
+	comps := len(app.Spec.Components)
+
+	// the definitions are only deployed to the control plane instead of the selected clusters
+	defs, err = RenderClusterPlacement(app, defs, NewClusterSelectors([]string{"cluster-1"}, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, len(addon.Definitions), len(defs))
+	assert.Equal(t, "TraitDefinition", defs[0].GetKind())
+	assert.Equal(t, comps-len(addon.Definitions), len(app.Spec.Components))
+	for _, comp := range app.Spec.Components {
+		def, err := definitionOfComponent(comp)
+		assert.NoError(t, err)
+		assert.Nil(t, def)
+	}
+}
+
+func TestValidateClusterSelectors(t *testing.T) {
+	ctx := context.Background()
+	clt := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-1", Namespace: multicluster.ClusterGatewaySecretNamespace},
+	}).Build()
+	assert.NoError(t, ValidateClusterSelectors(ctx, clt, NewClusterSelectors([]string{"local", "cluster-1"}, map[string]string{"region": "hangzhou"})))
+	assert.Error(t, ValidateClusterSelectors(ctx, clt, NewClusterSelectors([]string{"cluster-2"}, nil)))
+	assert.Error(t, ValidateClusterSelectors(ctx, clt, []common2.ClusterSelector{{}}))
+}
+
+func TestGetClusterStatus(t *testing.T) {
+	app := &v1beta1.Application{}
+	status, err := GetClusterStatus(app)
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+
+	app.Status.PolicyStatus = []common2.PolicyStatus{{
+		Name: ClusterPolicyName,
+		Type: v1alpha1.EnvBindingPolicyType,
+		Status: util.Object2RawExtension(v1alpha1.EnvBindingStatus{Envs: []v1alpha1.EnvStatus{
+			{Env: "cluster-1", Placements: []v1alpha1.PlacementDecision{{Cluster: "cluster-1"}}},
+			{Env: "selector-1", Placements: []v1alpha1.PlacementDecision{{Cluster: "cluster-3"}, {Cluster: "cluster-2"}}},
+			{Env: "cluster-4", Placements: []v1alpha1.PlacementDecision{{Cluster: "cluster-4"}}},
+		}}),
+	}}
+	app.Status.Services = []common2.ApplicationComponentStatus{
+		{Name: "fluxcd", Env: "cluster-1", Healthy: true},
+		{Name: "fluxcd", Env: "selector-1", Healthy: false, Message: "pods not ready"},
+	}
+	status, err = GetClusterStatus(app)
+	assert.NoError(t, err)
+	assert.Equal(t, []ClusterStatus{
+		{Cluster: "cluster-1", Env: "cluster-1", Healthy: true},
+		{Cluster: "cluster-2", Env: "selector-1", Healthy: false, Message: "fluxcd: pods not ready"},
+		{Cluster: "cluster-3", Env: "selector-1", Healthy: false, Message: "fluxcd: pods not ready"},
+		{Cluster: "cluster-4", Env: "cluster-4", Healthy: false, Message: "not deployed yet"},
+	}, status)
+}
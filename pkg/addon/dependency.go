@@ -0,0 +1,235 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var (
+	// ErrDependencyNotSatisfied means the dependency is not found or its version doesn't satisfy the constraint
+	ErrDependencyNotSatisfied aError = errors.New("addon dependency not satisfied")
+
+	// ErrDependencyCycle means the addon dependencies form a cycle
+	ErrDependencyCycle aError = errors.New("addon dependency cycle")
+)
+
+// MultiSource reads addons from several sources, the former source takes precedence if an addon exists in more than one of them
+type MultiSource []AddonSource
+
+// ListAddons lists addons in all the sources
+func (m MultiSource) ListAddons(opt ListOptions) ([]*types.Addon, error) {
+	var addons []*types.Addon
+	names := map[string]bool{}
+	for _, s := range m {
+		list, err := s.ListAddons(opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range list {
+			if !names[a.Name] {
+				names[a.Name] = true
+				addons = append(addons, a)
+			}
+		}
+	}
+	return addons, nil
+}
+
+// GetAddon gets the addon from the first source which has it
+func (m MultiSource) GetAddon(name string, opt ListOptions) (*types.Addon, error) {
+	for _, s := range m {
+		addon, err := s.GetAddon(name, opt)
+		if err != nil {
+			if errors.Is(err, ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		return addon, nil
+	}
+	return nil, ErrNotExist
+}
+
+// ResolveDependencies resolves the dependencies of the addon recursively, and returns the dependencies which are not enabled
+// in install order, i.e. every addon comes after its own dependencies. The enabled dependencies must satisfy the version
+// constraints, and the missing ones are read from the source.
+func ResolveDependencies(ctx context.Context, clt client.Client, source AddonSource, addon *types.Addon) ([]*types.Addon, error) {
+	r := &dependencyResolver{
+		ctx:      ctx,
+		clt:      clt,
+		source:   source,
+		visiting: map[string]bool{addon.Name: true},
+		versions: map[string]string{},
+	}
+	if err := r.resolve(addon, []string{addon.Name}); err != nil {
+		return nil, err
+	}
+	return r.order, nil
+}
+
+type dependencyResolver struct {
+	ctx    context.Context
+	clt    client.Client
+	source AddonSource
+	// visiting records the addons on the current resolving path to detect cycles
+	visiting map[string]bool
+	// versions records the versions of the resolved addons
+	versions map[string]string
+	order    []*types.Addon
+}
+
+func (r *dependencyResolver) resolve(addon *types.Addon, path []string) error {
+	for _, dep := range addon.Dependencies {
+		if r.visiting[dep.Name] {
+			return errors.WithMessagef(ErrDependencyCycle, "%s", strings.Join(append(path, dep.Name), " -> "))
+		}
+		if version, ok := r.versions[dep.Name]; ok {
+			if err := checkDependencyVersion(addon.Name, dep, version); err != nil {
+				return err
+			}
+			continue
+		}
+
+		app, err := getAddonApplication(r.ctx, r.clt, dep.Name)
+		if err != nil {
+			return err
+		}
+		if app != nil {
+			version := app.Labels[oam.LabelAddonVersion]
+			if err := checkDependencyVersion(addon.Name, dep, version); err != nil {
+				return err
+			}
+			r.versions[dep.Name] = version
+			continue
+		}
+
+		depAddon, err := r.source.GetAddon(dep.Name, EnableLevelOptions)
+		if err != nil {
+			if errors.Is(err, ErrNotExist) {
+				return errors.WithMessagef(ErrDependencyNotSatisfied, "addon %s depends on %s which is not found", addon.Name, dep.Name)
+			}
+			return err
+		}
+		if err := checkDependencyVersion(addon.Name, dep, depAddon.Version); err != nil {
+			return err
+		}
+		r.visiting[dep.Name] = true
+		if err := r.resolve(depAddon, append(path, dep.Name)); err != nil {
+			return err
+		}
+		r.visiting[dep.Name] = false
+		r.versions[dep.Name] = depAddon.Version
+		r.order = append(r.order, depAddon)
+	}
+	return nil
+}
+
+// checkDependencyVersion checks if the version satisfies the version constraint of the dependency. The version is
+// unknown if it's empty, e.g. the addon was enabled before its version is recorded, which is accepted with a warning.
+func checkDependencyVersion(addonName string, dep *types.AddonDependency, version string) error {
+	if dep.Version == "" {
+		return nil
+	}
+	if version == "" {
+		klog.Warningf("the version of addon %s is unknown, assume it satisfies the constraint %q of addon %s", dep.Name, dep.Version, addonName)
+		return nil
+	}
+	constraint, err := semver.NewConstraint(dep.Version)
+	if err != nil {
+		return errors.Wrapf(err, "invalid version constraint %q of dependency %s in addon %s", dep.Version, dep.Name, addonName)
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil || !constraint.Check(v) {
+		return errors.WithMessagef(ErrDependencyNotSatisfied, "addon %s requires %s %s, but the version is %q", addonName, dep.Name, dep.Version, version)
+	}
+	return nil
+}
+
+// getAddonApplication gets the application of the enabled addon, nil is returned if the addon is not enabled
+func getAddonApplication(ctx context.Context, clt client.Client, name string) (*v1beta1.Application, error) {
+	app := &v1beta1.Application{}
+	err := clt.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: Convert2AppName(name)}, app)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return app, nil
+}
+
+// FindDependents finds the enabled addons which depend on the addon. The dependencies of the addons enabled before
+// they are recorded in the application are read from the source, the addons are skipped with a warning if the source
+// is nil or doesn't have them.
+func FindDependents(ctx context.Context, clt client.Client, source AddonSource, name string) ([]string, error) {
+	apps := &v1beta1.ApplicationList{}
+	if err := clt.List(ctx, apps, client.InNamespace(types.DefaultKubeVelaNS), client.HasLabels{oam.LabelAddonName}); err != nil {
+		return nil, err
+	}
+	var dependents []string
+	for _, app := range apps.Items {
+		addonName := app.Labels[oam.LabelAddonName]
+		var deps []string
+		if v, ok := app.Annotations[oam.AnnotationAddonDependencies]; ok {
+			if v != "" {
+				deps = strings.Split(v, ",")
+			}
+		} else {
+			var err error
+			if deps, err = getDependenciesFromSource(source, addonName); err != nil {
+				klog.Warningf("fail to get the dependencies of addon %s, skip checking if it depends on %s: %v", addonName, name, err)
+				continue
+			}
+		}
+		for _, dep := range deps {
+			if dep == name {
+				dependents = append(dependents, addonName)
+				break
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents, nil
+}
+
+func getDependenciesFromSource(source AddonSource, name string) ([]string, error) {
+	if source == nil {
+		return nil, errors.New("no addon source to read the addon")
+	}
+	addon, err := source.GetAddon(name, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var deps []string
+	for _, dep := range addon.Dependencies {
+		deps = append(deps, dep.Name)
+	}
+	return deps, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+// memorySource is an addon source for testing
+type memorySource map[string]*types.Addon
+
+func (m memorySource) ListAddons(opt ListOptions) ([]*types.Addon, error) {
+	var addons []*types.Addon
+	for _, a := range m {
+		addons = append(addons, a)
+	}
+	return addons, nil
+}
+
+func (m memorySource) GetAddon(name string, opt ListOptions) (*types.Addon, error) {
+	if a, ok := m[name]; ok {
+		return a, nil
+	}
+	return nil, ErrNotExist
+}
+
+func newTestAddon(name, version string, deps ...*types.AddonDependency) *types.Addon {
+	return &types.Addon{AddonMeta: types.AddonMeta{Name: name, Version: version, Dependencies: deps}}
+}
+
+func newEnabledAddonApp(name, version string, deps string) *v1beta1.Application {
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        Convert2AppName(name),
+			Namespace:   types.DefaultKubeVelaNS,
+			Labels:      map[string]string{oam.LabelAddonName: name, oam.LabelAddonVersion: version},
+			Annotations: map[string]string{oam.AnnotationAddonDependencies: deps},
+		},
+	}
+	return app
+}
+
+func TestResolveDependencies(t *testing.T) {
+	ctx := context.Background()
+	source := memorySource{
+		"fluxcd":    newTestAddon("fluxcd", "1.1.0"),
+		"terraform": newTestAddon("terraform", "1.0.2", &types.AddonDependency{Name: "fluxcd", Version: ">=1.0.0"}),
+		"terraform-provider-alibaba": newTestAddon("terraform-provider-alibaba", "1.0.0",
+			&types.AddonDependency{Name: "terraform", Version: "~1.0"}, &types.AddonDependency{Name: "fluxcd"}),
+		"old":       newTestAddon("old", "0.1.0"),
+		"needs-new": newTestAddon("needs-new", "1.0.0", &types.AddonDependency{Name: "old", Version: ">=1.0.0"}),
+		"cycle-a":   newTestAddon("cycle-a", "1.0.0", &types.AddonDependency{Name: "cycle-b"}),
+		"cycle-b":   newTestAddon("cycle-b", "1.0.0", &types.AddonDependency{Name: "cycle-a"}),
+		"missing":   newTestAddon("missing", "1.0.0", &types.AddonDependency{Name: "not-exist"}),
+		"invalid":   newTestAddon("invalid", "1.0.0", &types.AddonDependency{Name: "fluxcd", Version: "not-a-constraint"}),
+	}
+	clt := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+
+	deps, err := ResolveDependencies(ctx, clt, source, source["terraform-provider-alibaba"])
+	assert.NoError(t, err)
+	var names []string
+	for _, d := range deps {
+		names = append(names, d.Name)
+	}
+	assert.Equal(t, []string{"fluxcd", "terraform"}, names)
+
+	_, err = ResolveDependencies(ctx, clt, source, source["needs-new"])
+	assert.True(t, errors.Is(err, ErrDependencyNotSatisfied))
+
+	_, err = ResolveDependencies(ctx, clt, source, source["cycle-a"])
+	assert.True(t, errors.Is(err, ErrDependencyCycle))
+	assert.Contains(t, err.Error(), "cycle-a -> cycle-b -> cycle-a")
+
+	_, err = ResolveDependencies(ctx, clt, source, source["missing"])
+	assert.True(t, errors.Is(err, ErrDependencyNotSatisfied))
+
+	_, err = ResolveDependencies(ctx, clt, source, source["invalid"])
+	assert.Error(t, err)
+
+	// the enabled dependencies are skipped if they satisfy the constraints
+	clt = fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(newEnabledAddonApp("fluxcd", "1.2.0", "")).Build()
+	deps, err = ResolveDependencies(ctx, clt, source, source["terraform-provider-alibaba"])
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(deps))
+	assert.Equal(t, "terraform", deps[0].Name)
+
+	clt = fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(newEnabledAddonApp("fluxcd", "0.9.0", "")).Build()
+	_, err = ResolveDependencies(ctx, clt, source, source["terraform"])
+	assert.True(t, errors.Is(err, ErrDependencyNotSatisfied))
+
+	// the version of the addon enabled without the version label is unknown, which is accepted
+	clt = fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(newEnabledAddonApp("fluxcd", "", "")).Build()
+	deps, err = ResolveDependencies(ctx, clt, source, source["terraform"])
+	assert.NoError(t, err)
+	assert.Empty(t, deps)
+}
+
+func TestFindDependents(t *testing.T) {
+	clt := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		newEnabledAddonApp("fluxcd", "1.2.0", ""),
+		newEnabledAddonApp("terraform", "1.0.2", "fluxcd"),
+		newEnabledAddonApp("terraform-provider-alibaba", "1.0.0", "terraform,fluxcd"),
+	).Build()
+	dependents, err := FindDependents(context.Background(), clt, nil, "fluxcd")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"terraform", "terraform-provider-alibaba"}, dependents)
+
+	dependents, err = FindDependents(context.Background(), clt, nil, "terraform-provider-alibaba")
+	assert.NoError(t, err)
+	assert.Empty(t, dependents)
+
+	// the dependencies of the addons enabled before they are recorded are read from the source
+	legacy := newEnabledAddonApp("terraform", "", "")
+	legacy.Annotations = nil
+	clt = fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(newEnabledAddonApp("fluxcd", "1.2.0", ""), legacy).Build()
+	source := memorySource{"terraform": newTestAddon("terraform", "1.0.2", &types.AddonDependency{Name: "fluxcd"})}
+	dependents, err = FindDependents(context.Background(), clt, source, "fluxcd")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"terraform"}, dependents)
+	dependents, err = FindDependents(context.Background(), clt, nil, "fluxcd")
+	assert.NoError(t, err)
+	assert.Empty(t, dependents)
+}
@@ -0,0 +1,391 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+const (
+	// PackageFileExtension is the file extension of the addon package
+	PackageFileExtension = ".tgz"
+
+	// ChecksumFileName is the file in the addon package which records the sha256 checksums of the other files
+	ChecksumFileName = "checksums.txt"
+
+	// DigestFileExtension is the file extension of the file next to the addon package which records the sha256
+	// digest of the whole package
+	DigestFileExtension = ".sha256"
+
+	// maxPackageFileSize limits the size of a single file in the addon package
+	maxPackageFileSize = 10 << 20
+)
+
+// ErrChecksumMismatch means the files in the addon package don't match the checksums recorded in it
+var ErrChecksumMismatch aError = errors.New("addon package checksum mismatch")
+
+// ErrDigestMismatch means the addon package doesn't match the expected sha256 digest
+var ErrDigestMismatch aError = errors.New("addon package digest mismatch")
+
+// ErrDigestNotFound means neither the expected sha256 digest nor the digest file of the addon package is provided
+var ErrDigestNotFound aError = errors.New("addon package digest not found")
+
+// PackageAddon packages the addon in the directory into <name>-<version>.tgz under the output directory, and returns
+// the path of the package. The addon is validated by reading it as enabling, and only the files of the addon layout
+// are packaged along with a checksum file. The sha256 digest of the package is written to <name>-<version>.tgz.sha256
+// next to it.
+func PackageAddon(dir, outputDir string) (string, error) {
+	r := &localReader{root: dir}
+	files, err := r.listFiles("")
+	if err != nil {
+		return "", err
+	}
+	var addonFiles []string
+	for _, f := range files {
+		if isAddonFile(f) {
+			addonFiles = append(addonFiles, f)
+		}
+	}
+	if len(addonFiles) == 0 {
+		return "", errors.Errorf("no addon found in %s", dir)
+	}
+	addon, err := readAddonFiles(r, "", addonFiles, EnableLevelOptions)
+	if err != nil {
+		return "", errors.WithMessagef(err, "fail to read addon in %s", dir)
+	}
+	if addon.Name == "" {
+		return "", errors.Errorf("the name of the addon in %s is not specified in %s", dir, MetadataFileName)
+	}
+	if _, err := semver.NewVersion(addon.Version); err != nil {
+		return "", errors.Errorf("the version %q of addon %s is not a semantic version", addon.Version, addon.Name)
+	}
+
+	contents := map[string][]byte{}
+	for _, f := range addonFiles {
+		data, err := r.readFile(f)
+		if err != nil {
+			return "", err
+		}
+		contents[f] = []byte(data)
+	}
+	buf := &bytes.Buffer{}
+	if err := writePackage(buf, addon.Name, contents); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(outputDir, 0750); err != nil {
+		return "", err
+	}
+	output := filepath.Join(outputDir, fmt.Sprintf("%s-%s%s", addon.Name, addon.Version, PackageFileExtension))
+	if err := ioutil.WriteFile(output, buf.Bytes(), 0600); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	digest := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), filepath.Base(output))
+	if err := ioutil.WriteFile(output+DigestFileExtension, []byte(digest), 0600); err != nil {
+		return "", err
+	}
+	return output, nil
+}
+
+// VerifyPackageDigest verifies the sha256 digest of the whole addon package. If the expected digest is empty, it's read
+// from the digest file next to the package, ErrDigestNotFound is returned if there is no such file.
+// Unlike the checksum file in the package, the digest obtained from a trusted place also detects a replaced package.
+func VerifyPackageDigest(p, digest string) error {
+	if digest == "" {
+		data, err := ioutil.ReadFile(filepath.Clean(p + DigestFileExtension))
+		if os.IsNotExist(err) {
+			return ErrDigestNotFound
+		}
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) == 0 {
+			return errors.WithMessagef(ErrDigestMismatch, "%s is empty", filepath.Base(p)+DigestFileExtension)
+		}
+		digest = fields[0]
+	}
+	f, err := os.Open(filepath.Clean(p))
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint:errcheck
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(actual, strings.TrimPrefix(digest, "sha256:")) {
+		return errors.WithMessagef(ErrDigestMismatch, "the sha256 digest of %s is %s rather than %s", p, actual, digest)
+	}
+	return nil
+}
+
+// isAddonFile checks if the file belongs to the addon layout read by readAddonFiles
+func isAddonFile(p string) bool {
+	segments := strings.Split(p, "/")
+	switch strings.ToLower(segments[0]) {
+	case ReadmeFileName, MetadataFileName, TemplateFileName:
+		return len(segments) == 1
+	case DefinitionsDirName, ResourcesDirName:
+		return len(segments) > 1
+	}
+	return false
+}
+
+// writePackage writes the files into a gzipped tarball under the directory of the addon name, the modification time of
+// the files are zeroed so that the package of the same addon is always the same
+func writePackage(w io.Writer, name string, files map[string][]byte) error {
+	var paths []string
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	checksums := &bytes.Buffer{}
+	for _, p := range paths {
+		sum := sha256.Sum256(files[p])
+		fmt.Fprintf(checksums, "%s  %s\n", hex.EncodeToString(sum[:]), p)
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	write := func(p string, data []byte) error {
+		hdr := &tar.Header{
+			Name:     path.Join(name, p),
+			Mode:     0644,
+			Size:     int64(len(data)),
+			ModTime:  time.Unix(0, 0),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	for _, p := range paths {
+		if err := write(p, files[p]); err != nil {
+			return err
+		}
+	}
+	if err := write(ChecksumFileName, checksums.Bytes()); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// PackageAddonSource reads a single addon from an addon package made by PackageAddon or from an addon directory,
+// no registry is needed so it works in offline environments
+type PackageAddonSource struct {
+	reader sourceReader
+	dir    string
+	name   string
+}
+
+// NewPackageAddonSource creates the addon source by the path of an addon package or an addon directory,
+// the checksums of the package are verified
+func NewPackageAddonSource(p string) (*PackageAddonSource, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	s := &PackageAddonSource{}
+	if info.IsDir() {
+		s.reader = &localReader{root: p}
+	} else {
+		files, dir, err := readPackage(p)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "fail to read addon package %s", p)
+		}
+		s.reader, s.dir = memoryReader(files), dir
+	}
+	addon, err := s.readAddon(ListOptions{})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "fail to read addon from %s", p)
+	}
+	if addon.Name == "" {
+		return nil, errors.Errorf("the name of the addon in %s is not specified in %s", p, MetadataFileName)
+	}
+	s.name = addon.Name
+	return s, nil
+}
+
+// Name returns the name of the addon in the package
+func (s *PackageAddonSource) Name() string {
+	return s.name
+}
+
+// ListAddons returns the addon in the package
+func (s *PackageAddonSource) ListAddons(opt ListOptions) ([]*types.Addon, error) {
+	addon, err := s.readAddon(opt)
+	if err != nil {
+		return nil, err
+	}
+	return []*types.Addon{addon}, nil
+}
+
+// GetAddon returns the addon in the package if the name matches
+func (s *PackageAddonSource) GetAddon(name string, opt ListOptions) (*types.Addon, error) {
+	if name != s.name {
+		return nil, ErrNotExist
+	}
+	return s.readAddon(opt)
+}
+
+func (s *PackageAddonSource) readAddon(opt ListOptions) (*types.Addon, error) {
+	files, err := s.reader.listFiles(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	return readAddonFiles(s.reader, s.dir, files, opt)
+}
+
+// readPackage reads the files of the addon package and verifies them by the checksum file, the files are returned
+// along with the top directory of the package
+func readPackage(p string) (map[string]string, string, error) {
+	f, err := os.Open(filepath.Clean(p))
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close() // nolint:errcheck
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, "", err
+	}
+	tr := tar.NewReader(gr)
+	files := map[string]string{}
+	var dir string
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		segments := strings.SplitN(name, "/", 2)
+		if path.IsAbs(name) || strings.HasPrefix(name, "../") || len(segments) != 2 {
+			return nil, "", errors.Errorf("invalid file %s in the addon package", hdr.Name)
+		}
+		if dir == "" {
+			dir = segments[0]
+		}
+		if segments[0] != dir {
+			return nil, "", errors.Errorf("all the files in the addon package should be under the directory %s", dir)
+		}
+		if hdr.Size > maxPackageFileSize {
+			return nil, "", errors.Errorf("file %s in the addon package is too large", hdr.Name)
+		}
+		data, err := ioutil.ReadAll(io.LimitReader(tr, maxPackageFileSize))
+		if err != nil {
+			return nil, "", err
+		}
+		files[name] = string(data)
+	}
+	if err := verifyChecksums(files, dir); err != nil {
+		return nil, "", err
+	}
+	return files, dir, nil
+}
+
+// verifyChecksums verifies all the files under the directory match the checksum file, and no file is missing or added
+func verifyChecksums(files map[string]string, dir string) error {
+	checksumFile := path.Join(dir, ChecksumFileName)
+	data, ok := files[checksumFile]
+	if !ok {
+		return errors.WithMessagef(ErrChecksumMismatch, "%s not found", ChecksumFileName)
+	}
+	checked := map[string]bool{checksumFile: true}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return errors.WithMessagef(ErrChecksumMismatch, "invalid line %q in %s", line, ChecksumFileName)
+		}
+		name := path.Join(dir, fields[1])
+		content, ok := files[name]
+		if !ok {
+			return errors.WithMessagef(ErrChecksumMismatch, "file %s is missing", fields[1])
+		}
+		sum := sha256.Sum256([]byte(content))
+		if hex.EncodeToString(sum[:]) != fields[0] {
+			return errors.WithMessagef(ErrChecksumMismatch, "file %s is modified", fields[1])
+		}
+		checked[name] = true
+	}
+	for name := range files {
+		if !checked[name] {
+			return errors.WithMessagef(ErrChecksumMismatch, "file %s is not recorded", relativePath(dir, name))
+		}
+	}
+	return nil
+}
+
+// memoryReader reads the files kept in memory, the keys are the slash separated paths
+type memoryReader map[string]string
+
+func (m memoryReader) listFiles(dir string) ([]string, error) {
+	prefix := strings.Trim(dir, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	var files []string
+	for f := range m {
+		if strings.HasPrefix(f, prefix) {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (m memoryReader) readFile(p string) (string, error) {
+	data, ok := m[p]
+	if !ok {
+		return "", errors.Errorf("file %s not found", p)
+	}
+	return data, nil
+}
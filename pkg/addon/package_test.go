@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageAddon(t *testing.T) {
+	dir, err := ioutil.TempDir("", "addon-package")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	output, err := PackageAddon("./testdata/example", dir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "example-1.0.0.tgz"), output)
+
+	// the package of the same addon is always the same
+	data, err := ioutil.ReadFile(output)
+	assert.NoError(t, err)
+	_, err = PackageAddon("./testdata/example", dir)
+	assert.NoError(t, err)
+	data2, err := ioutil.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, data, data2)
+	assert.NoError(t, VerifyPackageDigest(output, ""))
+	assert.NoError(t, VerifyPackageDigest(output, fmt.Sprintf("sha256:%x", sha256.Sum256(data))))
+
+	expected, err := (&LocalAddonSource{Path: "./testdata"}).GetAddon("example", EnableLevelOptions)
+	assert.NoError(t, err)
+	for _, p := range []string{output, "./testdata/example"} {
+		source, err := NewPackageAddonSource(p)
+		assert.NoError(t, err)
+		assert.Equal(t, "example", source.Name())
+		addon, err := source.GetAddon("example", EnableLevelOptions)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, addon)
+		addons, err := source.ListAddons(GetLevelOptions)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(addons))
+		_, err = source.GetAddon("other", EnableLevelOptions)
+		assert.Equal(t, ErrNotExist, err)
+	}
+}
+
+func TestReadTamperedPackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "addon-package")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	metadata := "name: example\nversion: 1.0.0\n"
+	buf := &bytes.Buffer{}
+	assert.NoError(t, writePackage(buf, "example", map[string][]byte{MetadataFileName: []byte(metadata)}))
+	files := readTarball(t, buf.Bytes())
+
+	cases := map[string]map[string]string{
+		"modified":     {"example/" + MetadataFileName: "name: example\nversion: 2.0.0\n", "example/" + ChecksumFileName: files["example/"+ChecksumFileName]},
+		"added":        {"example/" + MetadataFileName: metadata, "example/template.yaml": "", "example/" + ChecksumFileName: files["example/"+ChecksumFileName]},
+		"no checksums": {"example/" + MetadataFileName: metadata},
+	}
+	for name, c := range cases {
+		p := filepath.Join(dir, name+PackageFileExtension)
+		assert.NoError(t, ioutil.WriteFile(p, writeTarball(t, c), 0600))
+		_, err := NewPackageAddonSource(p)
+		assert.True(t, errors.Is(err, ErrChecksumMismatch), name)
+	}
+
+	p := filepath.Join(dir, "escape"+PackageFileExtension)
+	assert.NoError(t, ioutil.WriteFile(p, writeTarball(t, map[string]string{"../" + MetadataFileName: metadata}), 0600))
+	_, err = NewPackageAddonSource(p)
+	assert.Error(t, err)
+}
+
+func TestVerifyPackageDigest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "addon-package")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	output, err := PackageAddon("./testdata/example", dir)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadFile(output)
+	assert.NoError(t, err)
+	digest := fmt.Sprintf("%x", sha256.Sum256(data))
+
+	// the package replaced by another valid package still passes the checksums in it, but not the digest
+	metadata := "name: example\nversion: 1.0.0\n"
+	buf := &bytes.Buffer{}
+	assert.NoError(t, writePackage(buf, "example", map[string][]byte{MetadataFileName: []byte(metadata)}))
+	assert.NoError(t, ioutil.WriteFile(output, buf.Bytes(), 0600))
+	_, err = NewPackageAddonSource(output)
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(VerifyPackageDigest(output, ""), ErrDigestMismatch))
+	assert.True(t, errors.Is(VerifyPackageDigest(output, digest), ErrDigestMismatch))
+
+	assert.NoError(t, os.Remove(output+DigestFileExtension))
+	assert.True(t, errors.Is(VerifyPackageDigest(output, ""), ErrDigestNotFound))
+	assert.NoError(t, VerifyPackageDigest(output, fmt.Sprintf("%x", sha256.Sum256(buf.Bytes()))))
+}
+
+func readTarball(t *testing.T, data []byte) map[string]string {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	tr := tar.NewReader(gr)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		content, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+		files[hdr.Name] = string(content)
+	}
+	return files
+}
+
+func writeTarball(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+	return buf.Bytes()
+}
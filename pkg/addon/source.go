@@ -0,0 +1,482 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+// AddonSource is where the addons are read from
+type AddonSource interface {
+	// ListAddons lists all the addons in the source
+	ListAddons(opt ListOptions) ([]*types.Addon, error)
+	// GetAddon gets the addon by name, ErrNotExist is returned if the addon is not in the source
+	GetAddon(name string, opt ListOptions) (*types.Addon, error)
+}
+
+// RegistrySource defines the sources an addon registry can be backed by, exactly one of them should be set
+type RegistrySource struct {
+	// Git reads addons from a GitHub repository through the GitHub API
+	Git *GitAddonSource `json:"git,omitempty"`
+	// GitRepo reads addons from any git repository over HTTPS, e.g. GitLab or Gitea
+	GitRepo *GitRepoAddonSource `json:"gitRepo,omitempty"`
+	// OSS reads addons from an OSS bucket or any HTTP server serving the bucket index
+	OSS *OSSAddonSource `json:"oss,omitempty"`
+	// Local reads addons from a directory of the local filesystem
+	Local *LocalAddonSource `json:"local,omitempty"`
+}
+
+// BuildSource returns the AddonSource of the registry
+func (r RegistrySource) BuildSource() (AddonSource, error) {
+	var sources []AddonSource
+	if r.Git != nil {
+		sources = append(sources, r.Git)
+	}
+	if r.GitRepo != nil {
+		sources = append(sources, r.GitRepo)
+	}
+	if r.OSS != nil {
+		sources = append(sources, r.OSS)
+	}
+	if r.Local != nil {
+		sources = append(sources, r.Local)
+	}
+	if len(sources) != 1 {
+		return nil, errors.New("exactly one of git, gitRepo, oss and local source should be specified in the addon registry")
+	}
+	return sources[0], nil
+}
+
+// LocalAddonSource defines the information about a local directory as addon source
+type LocalAddonSource struct {
+	Path string `json:"path,omitempty" validate:"required"`
+}
+
+// ListAddons lists addons in the local directory
+func (l *LocalAddonSource) ListAddons(opt ListOptions) ([]*types.Addon, error) {
+	return listAddonsFromReader(&localReader{root: l.Path}, "", opt)
+}
+
+// GetAddon gets the addon from the local directory
+func (l *LocalAddonSource) GetAddon(name string, opt ListOptions) (*types.Addon, error) {
+	return getAddonFromReader(&localReader{root: l.Path}, "", name, opt)
+}
+
+// OSSAddonSource defines the information about an OSS bucket as addon source, the bucket index is
+// read by the ListObjectsV2 API, so any S3 compatible storage or static HTTP server serving the index works
+type OSSAddonSource struct {
+	// URL is the bucket URL, e.g. https://addons.oss-cn-hangzhou.aliyuncs.com/
+	URL  string `json:"url,omitempty" validate:"required"`
+	Path string `json:"path,omitempty"`
+}
+
+// ListAddons lists addons in the OSS bucket
+func (o *OSSAddonSource) ListAddons(opt ListOptions) ([]*types.Addon, error) {
+	return listAddonsFromReader(newOSSReader(o.URL), o.Path, opt)
+}
+
+// GetAddon gets the addon from the OSS bucket
+func (o *OSSAddonSource) GetAddon(name string, opt ListOptions) (*types.Addon, error) {
+	return getAddonFromReader(newOSSReader(o.URL), o.Path, name, opt)
+}
+
+// GitRepoAddonSource defines the information about a generic git repository as addon source,
+// the repository is cloned over HTTPS by the git command, so it works for GitLab, Gitea or self-hosted git servers.
+// The cloned repository is cached for gitRepoCacheTTL and shared by all the sources of the same repository.
+type GitRepoAddonSource struct {
+	URL  string `json:"url,omitempty" validate:"required"`
+	Path string `json:"path,omitempty"`
+	// Ref is the branch or tag to clone, the default branch is used if not specified
+	Ref string `json:"ref,omitempty"`
+	// Username is used along with the token for authentication, it's "oauth2" if not specified
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// gitRepoCacheTTL is how long a cloned repository is reused before it's cloned again
+const gitRepoCacheTTL = 5 * time.Minute
+
+// gitRepoCache keeps the cloned repositories, the key is built from all the fields which affect the clone
+var gitRepoCache = struct {
+	sync.Mutex
+	repos map[string]*gitRepo
+}{repos: map[string]*gitRepo{}}
+
+// gitRepo is a cloned repository, the directory is only removed when it's cloned again
+// and no one is reading it under the read lock
+type gitRepo struct {
+	sync.RWMutex
+	dir      string
+	clonedAt time.Time
+}
+
+// ListAddons lists addons in the git repository
+func (g *GitRepoAddonSource) ListAddons(opt ListOptions) ([]*types.Addon, error) {
+	var addons []*types.Addon
+	err := g.withRepo(func(dir string) (err error) {
+		addons, err = listAddonsFromReader(&localReader{root: dir}, g.Path, opt)
+		return err
+	})
+	return addons, err
+}
+
+// GetAddon gets the addon from the git repository
+func (g *GitRepoAddonSource) GetAddon(name string, opt ListOptions) (*types.Addon, error) {
+	var addon *types.Addon
+	err := g.withRepo(func(dir string) (err error) {
+		addon, err = getAddonFromReader(&localReader{root: dir}, g.Path, name, opt)
+		return err
+	})
+	return addon, err
+}
+
+// withRepo reads the cached repository, it's cloned first if not cached or expired
+func (g *GitRepoAddonSource) withRepo(read func(dir string) error) error {
+	key := strings.Join([]string{g.URL, g.Ref, g.Username, g.Token}, "\x00")
+	gitRepoCache.Lock()
+	repo, ok := gitRepoCache.repos[key]
+	if !ok {
+		repo = &gitRepo{}
+		gitRepoCache.repos[key] = repo
+	}
+	gitRepoCache.Unlock()
+
+	repo.RLock()
+	if repo.dir == "" || time.Since(repo.clonedAt) > gitRepoCacheTTL {
+		repo.RUnlock()
+		repo.Lock()
+		// check again since the repository may be cloned by others while waiting for the lock
+		if repo.dir == "" || time.Since(repo.clonedAt) > gitRepoCacheTTL {
+			dir, err := g.clone()
+			if err != nil {
+				repo.Unlock()
+				return err
+			}
+			if repo.dir != "" {
+				_ = os.RemoveAll(repo.dir)
+			}
+			repo.dir, repo.clonedAt = dir, time.Now()
+		}
+		repo.Unlock()
+		repo.RLock()
+	}
+	defer repo.RUnlock()
+	return read(repo.dir)
+}
+
+// clone shallow clones the repository into a temporary directory
+func (g *GitRepoAddonSource) clone() (string, error) {
+	u, err := url.Parse(g.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", errors.Errorf("invalid git repository url %s, only http(s) is supported", g.URL)
+	}
+	if g.Token != "" {
+		username := g.Username
+		if username == "" {
+			username = "oauth2"
+		}
+		u.User = url.UserPassword(username, g.Token)
+	}
+	dir, err := ioutil.TempDir("", "vela-addon-")
+	if err != nil {
+		return "", err
+	}
+	args := []string{"clone", "--depth", "1"}
+	if g.Ref != "" {
+		args = append(args, "--branch", g.Ref)
+	}
+	args = append(args, u.String(), dir)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	// nolint:gosec
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		_ = os.RemoveAll(dir)
+		msg := strings.TrimSpace(string(out))
+		if g.Token != "" {
+			msg = strings.ReplaceAll(msg, g.Token, "******")
+		}
+		return "", errors.Errorf("fail to clone git repository %s: %s", g.URL, msg)
+	}
+	return dir, nil
+}
+
+// sourceReader reads the files of an addon source
+type sourceReader interface {
+	// listFiles lists the paths of all the files under the dir recursively,
+	// the paths are slash separated and relative to the root of the source
+	listFiles(dir string) ([]string, error)
+	// readFile reads the file by the path relative to the root of the source
+	readFile(path string) (string, error)
+}
+
+func listAddonsFromReader(r sourceReader, dir string, opt ListOptions) ([]*types.Addon, error) {
+	files, err := r.listFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	addonFiles := map[string][]string{}
+	for _, f := range files {
+		segments := strings.SplitN(relativePath(dir, f), "/", 2)
+		// only the files in the sub directories belong to addons
+		if len(segments) == 2 && !strings.HasPrefix(segments[0], ".") {
+			addonFiles[segments[0]] = append(addonFiles[segments[0]], f)
+		}
+	}
+	var names []string
+	for name := range addonFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var addons []*types.Addon
+	for _, name := range names {
+		addon, err := readAddonFiles(r, path.Join(dir, name), addonFiles[name], opt)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "fail to read addon %s", name)
+		}
+		addons = append(addons, addon)
+	}
+	return addons, nil
+}
+
+func getAddonFromReader(r sourceReader, dir, name string, opt ListOptions) (*types.Addon, error) {
+	// the name is joined into the path to read, so it must not escape the directory of the source
+	if name == "" || strings.Contains(name, "..") || strings.ContainsAny(name, "/\\") {
+		return nil, errors.Errorf("invalid addon name %q", name)
+	}
+	addonDir := path.Join(dir, name)
+	files, err := r.listFiles(addonDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, ErrNotExist
+	}
+	return readAddonFiles(r, addonDir, files, opt)
+}
+
+// readAddonFiles reads the addon from its files, the layout of the files is the same as the addon in GitHub
+func readAddonFiles(r sourceReader, addonDir string, files []string, opt ListOptions) (*types.Addon, error) {
+	addon := &types.Addon{}
+	for _, f := range files {
+		segments := strings.Split(relativePath(addonDir, f), "/")
+		name := segments[len(segments)-1]
+		var read bool
+		switch strings.ToLower(segments[0]) {
+		case ReadmeFileName:
+			read = len(segments) == 1 && opt.GetDetail
+		case MetadataFileName:
+			read = len(segments) == 1
+		case TemplateFileName:
+			read = len(segments) == 1 && opt.GetTemplate
+		case DefinitionsDirName:
+			read = len(segments) > 1 && opt.GetDefinition
+		case ResourcesDirName:
+			read = len(segments) > 1 && (opt.GetResource || opt.GetParameter)
+		}
+		if !read {
+			continue
+		}
+		data, err := r.readFile(f)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(segments[0]) {
+		case ReadmeFileName:
+			addon.Detail = data
+		case MetadataFileName:
+			if err := yaml.Unmarshal([]byte(data), &addon.AddonMeta); err != nil {
+				return nil, err
+			}
+		case TemplateFileName:
+			if addon.AppTemplate, err = decodeAppTemplate(data); err != nil {
+				return nil, err
+			}
+		case DefinitionsDirName:
+			addon.Definitions = append(addon.Definitions, types.AddonElementFile{Data: data, Name: name, Path: segments[:len(segments)-1]})
+		case ResourcesDirName:
+			elem := types.AddonElementFile{Data: data, Name: name, Path: segments[:len(segments)-1]}
+			switch {
+			case name == "parameter.cue":
+				addon.Parameters = data
+			case filepath.Ext(name) == ".cue":
+				addon.CUETemplates = append(addon.CUETemplates, elem)
+			default:
+				addon.YAMLTemplates = append(addon.YAMLTemplates, elem)
+			}
+		}
+	}
+
+	if opt.GetParameter && addon.Parameters != "" {
+		if err := genAddonAPISchema(addon); err != nil {
+			return nil, err
+		}
+	}
+	return addon, nil
+}
+
+func relativePath(dir, p string) string {
+	if dir == "" || dir == "." {
+		return p
+	}
+	return strings.TrimPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// localReader reads the files of a local directory
+type localReader struct {
+	root string
+}
+
+func (l *localReader) listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(filepath.Join(l.root, filepath.FromSlash(dir)), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// skip hidden directories such as .git
+			if strings.HasPrefix(info.Name(), ".") && info.Name() != "." {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(l.root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (l *localReader) readFile(p string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(l.root, filepath.FromSlash(p)))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ossListBucketResult is the result of the ListObjectsV2 API
+type ossListBucketResult struct {
+	Files                 []string `xml:"Contents>Key"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	NextContinuationToken string   `xml:"NextContinuationToken"`
+}
+
+// ossReader reads the files of an OSS bucket
+type ossReader struct {
+	client    *http.Client
+	bucketURL string
+}
+
+func newOSSReader(bucketURL string) *ossReader {
+	if !strings.HasSuffix(bucketURL, "/") {
+		bucketURL += "/"
+	}
+	return &ossReader{client: &http.Client{Timeout: time.Second * 10}, bucketURL: bucketURL}
+}
+
+func (o *ossReader) listFiles(dir string) ([]string, error) {
+	prefix := strings.Trim(dir, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	var files []string
+	var token string
+	for {
+		query := url.Values{"list-type": []string{"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		data, err := o.get(o.bucketURL + "?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+		list := &ossListBucketResult{}
+		if err := xml.Unmarshal(data, list); err != nil {
+			return nil, errors.Wrapf(err, "fail to parse the index of bucket %s", o.bucketURL)
+		}
+		for _, f := range list.Files {
+			// the keys ending with slash are directories
+			if strings.HasPrefix(f, prefix) && !strings.HasSuffix(f, "/") {
+				files = append(files, f)
+			}
+		}
+		if !list.IsTruncated || list.NextContinuationToken == "" {
+			break
+		}
+		token = list.NextContinuationToken
+	}
+	return files, nil
+}
+
+func (o *ossReader) readFile(p string) (string, error) {
+	data, err := o.get(o.bucketURL + p)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (o *ossReader) get(u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fail to get %s: %s", u, resp.Status)
+	}
+	return data, nil
+}
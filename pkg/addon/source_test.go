@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+func TestRegistrySourceBuildSource(t *testing.T) {
+	_, err := RegistrySource{}.BuildSource()
+	assert.Error(t, err)
+
+	_, err = RegistrySource{Git: &GitAddonSource{}, Local: &LocalAddonSource{}}.BuildSource()
+	assert.Error(t, err)
+
+	source, err := RegistrySource{OSS: &OSSAddonSource{URL: "https://addons.example.com"}}.BuildSource()
+	assert.NoError(t, err)
+	assert.IsType(t, &OSSAddonSource{}, source)
+}
+
+func TestLocalAddonSource(t *testing.T) {
+	source := &LocalAddonSource{Path: "./testdata"}
+
+	addons, err := source.ListAddons(GetLevelOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(addons))
+	checkExampleAddon(t, addons[0], GetLevelOptions)
+
+	addon, err := source.GetAddon("example", EnableLevelOptions)
+	assert.NoError(t, err)
+	checkExampleAddon(t, addon, EnableLevelOptions)
+
+	_, err = source.GetAddon("not-exist", EnableLevelOptions)
+	assert.Equal(t, ErrNotExist, err)
+
+	for _, name := range []string{"", "..", "../testdata/example", "example/definitions", "..\\example"} {
+		_, err = source.GetAddon(name, EnableLevelOptions)
+		assert.Error(t, err, name)
+		assert.NotEqual(t, ErrNotExist, err, name)
+	}
+}
+
+func TestOSSAddonSource(t *testing.T) {
+	var keys []string
+	err := filepath.Walk("./testdata", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel("./testdata", path)
+		keys = append(keys, "addons/"+filepath.ToSlash(rel))
+		return err
+	})
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			prefix := r.URL.Query().Get("prefix")
+			// return the index in two pages to test the continuation
+			list := ossListBucketResult{}
+			for _, k := range keys {
+				if strings.HasPrefix(k, prefix) {
+					list.Files = append(list.Files, k)
+				}
+			}
+			if r.URL.Query().Get("continuation-token") == "" && len(list.Files) > 1 {
+				list.Files = list.Files[:1]
+				list.IsTruncated = true
+				list.NextContinuationToken = "next"
+			} else if len(list.Files) > 1 {
+				list.Files = list.Files[1:]
+			}
+			data, _ := xml.Marshal(struct {
+				XMLName xml.Name `xml:"ListBucketResult"`
+				ossListBucketResult
+			}{ossListBucketResult: list})
+			_, _ = w.Write(data)
+			return
+		}
+		data, err := ioutil.ReadFile(filepath.Join("./testdata", strings.TrimPrefix(r.URL.Path, "/addons/")))
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	source := &OSSAddonSource{URL: server.URL, Path: "addons"}
+	addons, err := source.ListAddons(GetLevelOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(addons))
+	checkExampleAddon(t, addons[0], GetLevelOptions)
+
+	addon, err := source.GetAddon("example", EnableLevelOptions)
+	assert.NoError(t, err)
+	checkExampleAddon(t, addon, EnableLevelOptions)
+
+	_, err = source.GetAddon("not-exist", EnableLevelOptions)
+	assert.Equal(t, ErrNotExist, err)
+}
+
+func TestGitRepoAddonSource(t *testing.T) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "addon-git")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	git := func(args ...string) {
+		cmd := exec.Command(gitPath, args...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@test", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@test")
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	work := filepath.Join(dir, "work")
+	assert.NoError(t, os.MkdirAll(filepath.Join(work, "addons"), 0750))
+	assert.NoError(t, exec.Command("cp", "-r", "./testdata/example", filepath.Join(work, "addons")).Run())
+	git("-C", work, "init", "-q")
+	git("-C", work, "add", "-A")
+	git("-C", work, "commit", "-q", "-m", "init")
+	git("clone", "-q", "--bare", work, filepath.Join(dir, "repo.git"))
+
+	var requests int32
+	backend := &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + dir, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		backend.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+
+	source := &GitRepoAddonSource{URL: s.URL + "/repo.git", Path: "addons"}
+	addons, err := source.ListAddons(GetLevelOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(addons))
+	checkExampleAddon(t, addons[0], GetLevelOptions)
+	cloned := atomic.LoadInt32(&requests)
+	assert.NotZero(t, cloned)
+
+	// the cloned repository is reused
+	addon, err := source.GetAddon("example", EnableLevelOptions)
+	assert.NoError(t, err)
+	checkExampleAddon(t, addon, EnableLevelOptions)
+	assert.Equal(t, cloned, atomic.LoadInt32(&requests))
+}
+
+func checkExampleAddon(t *testing.T, addon *types.Addon, opt ListOptions) {
+	assert.Equal(t, "example", addon.Name)
+	assert.Equal(t, "1.0.0", addon.Version)
+	assert.Contains(t, addon.Detail, "This is an example addon.")
+	assert.Equal(t, 1, len(addon.Definitions))
+	assert.Equal(t, "example-trait.yaml", addon.Definitions[0].Name)
+	assert.Equal(t, []string{"definitions"}, addon.Definitions[0].Path)
+	assert.Contains(t, addon.Parameters, "image")
+	assert.NotNil(t, addon.APISchema)
+	if opt.GetTemplate {
+		assert.NotNil(t, addon.AppTemplate)
+		assert.Equal(t, "example", addon.AppTemplate.Name)
+	} else {
+		assert.Nil(t, addon.AppTemplate)
+	}
+	if opt.GetResource {
+		assert.Equal(t, 1, len(addon.CUETemplates))
+		assert.Equal(t, []string{"resources"}, addon.CUETemplates[0].Path)
+		assert.Equal(t, 1, len(addon.YAMLTemplates))
+		assert.Equal(t, "namespace.yaml", addon.YAMLTemplates[0].Name)
+	}
+}
@@ -0,0 +1,4 @@
+output: {
+	type: "webservice"
+	properties: image: parameter.image
+}
@@ -0,0 +1,12 @@
+parameter: {
+	// +usage=The image of the example
+	image: *"nginx" | string
+	// +usage=The replicas of the example
+	replicas: *1 | int
+	// +usage=Whether to enable debug mode
+	debug: *false | bool
+	// +usage=The labels of the example
+	labels?: [string]: string
+	// +usage=The ports of the example
+	ports?: [...int]
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aryann/difflib"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	common2 "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// ErrNotEnabled means the addon to upgrade is not enabled
+var ErrNotEnabled aError = errors.New("addon not enabled")
+
+// ResourceDiff is the difference of a resource between the cluster and the rendered addon
+type ResourceDiff struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Diff is the line based diff, the added lines start with "+" and the removed lines start with "-"
+	Diff string `json:"diff"`
+}
+
+// Upgrade is the rendered upgrade of an enabled addon, the resources are applied by the caller after checking the diffs
+type Upgrade struct {
+	PreviousVersion string
+	Version         string
+	// Selectors are the target clusters of the enabled addon, which are kept by the upgrade
+	Selectors []common2.ClusterSelector
+	App       *v1beta1.Application
+	Defs      []*unstructured.Unstructured
+	Secret    *v1.Secret
+	Diffs     []ResourceDiff
+	// Dependencies are the rendered addons depended on but not enabled, they must be applied before the upgrade
+	Dependencies []*Upgrade
+}
+
+// PrepareUpgrade renders the addon to upgrade the enabled one and compares the resources with the ones in the cluster.
+// The args override the ones recorded in the args secret, and the addon is deployed to the same clusters.
+func PrepareUpgrade(ctx context.Context, clt client.Client, addon *types.Addon, args map[string]interface{}) (*Upgrade, error) {
+	existApp, err := getAddonApplication(ctx, clt, addon.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existApp == nil {
+		return nil, errors.WithMessagef(ErrNotEnabled, "addon %s", addon.Name)
+	}
+	selectors, err := GetClusterSelectors(existApp)
+	if err != nil {
+		return nil, errors.WithMessage(err, "fail to get the target clusters of addon")
+	}
+
+	addonArgs, err := GetArgsFromSecret(ctx, clt, addon)
+	if err != nil {
+		return nil, errors.WithMessage(err, "fail to get the args of addon")
+	}
+	if addonArgs == nil {
+		addonArgs = make(map[string]interface{}, len(args))
+	}
+	for k, v := range args {
+		addonArgs[k] = v
+	}
+	app, defs, err := RenderApplication(addon, addonArgs)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "fail to render addon %s", addon.Name)
+	}
+	if defs, err = RenderClusterPlacement(app, defs, selectors); err != nil {
+		return nil, errors.WithMessagef(err, "fail to render addon %s", addon.Name)
+	}
+	sec := RenderArgsSecret(addon, addonArgs)
+	diffs, err := DiffAddon(ctx, clt, app, defs, sec)
+	if err != nil {
+		return nil, errors.WithMessage(err, "fail to diff addon")
+	}
+	return &Upgrade{
+		PreviousVersion: existApp.Labels[oam.LabelAddonVersion],
+		Version:         addon.Version,
+		Selectors:       selectors,
+		App:             app,
+		Defs:            defs,
+		Secret:          sec,
+		Diffs:           diffs,
+	}, nil
+}
+
+// PrepareDependencies renders the dependencies which are not enabled to the clusters of the upgrade with the default args,
+// the diffs of their resources are added to the diffs of the upgrade since they are created along with it
+func (u *Upgrade) PrepareDependencies(ctx context.Context, clt client.Client, deps []*types.Addon) error {
+	for _, dep := range deps {
+		app, defs, err := RenderApplication(dep, nil)
+		if err != nil {
+			return errors.WithMessagef(err, "fail to render addon %s", dep.Name)
+		}
+		if defs, err = RenderClusterPlacement(app, defs, u.Selectors); err != nil {
+			return errors.WithMessagef(err, "fail to render addon %s", dep.Name)
+		}
+		sec := RenderArgsSecret(dep, nil)
+		diffs, err := DiffAddon(ctx, clt, app, defs, sec)
+		if err != nil {
+			return errors.WithMessagef(err, "fail to diff addon %s", dep.Name)
+		}
+		u.Dependencies = append(u.Dependencies, &Upgrade{
+			Version:   dep.Version,
+			Selectors: u.Selectors,
+			App:       app,
+			Defs:      defs,
+			Secret:    sec,
+			Diffs:     diffs,
+		})
+		u.Diffs = append(u.Diffs, diffs...)
+	}
+	return nil
+}
+
+// GetArgsFromSecret reads the args of an enabled addon from its args secret, nil is returned if the secret doesn't exist
+func GetArgsFromSecret(ctx context.Context, clt client.Client, addon *types.Addon) (map[string]interface{}, error) {
+	var sec v1.Secret
+	err := clt.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: Convert2SecName(addon.Name)}, &sec)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	args := make(map[string]string, len(sec.Data))
+	for k, v := range sec.Data {
+		args[k] = string(v)
+	}
+	return ConvertArgs(addon, args), nil
+}
+
+// ConvertArgs converts the string args, e.g. the ones from the command line or the args secret, to the
+// types declared in the addon parameters, the objects and arrays are decoded from JSON. The args not declared
+// or failed to convert are kept as strings.
+func ConvertArgs(addon *types.Addon, args map[string]string) map[string]interface{} {
+	res := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		res[k] = v
+		if addon.APISchema == nil || addon.APISchema.Properties[k] == nil || addon.APISchema.Properties[k].Value == nil {
+			continue
+		}
+		switch addon.APISchema.Properties[k].Value.Type {
+		case "boolean":
+			if b, err := strconv.ParseBool(v); err == nil {
+				res[k] = b
+			}
+		case "integer":
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				res[k] = i
+			}
+		case "number":
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				res[k] = f
+			}
+		case "object", "array":
+			var o interface{}
+			if err := json.Unmarshal([]byte(v), &o); err == nil {
+				res[k] = o
+			}
+		}
+	}
+	return res
+}
+
+// DiffAddon compares the rendered addon resources with the ones in the cluster, only the changed resources are returned.
+// The values in the args secret are masked in the diff.
+func DiffAddon(ctx context.Context, clt client.Client, app *v1beta1.Application, defs []*unstructured.Unstructured, sec *v1.Secret) ([]ResourceDiff, error) {
+	var diffs []ResourceDiff
+
+	existApp := &v1beta1.Application{}
+	oldData := ""
+	err := clt.Get(ctx, client.ObjectKey{Namespace: app.Namespace, Name: app.Name}, existApp)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		if oldData, err = marshalDiffData(existApp.Spec); err != nil {
+			return nil, err
+		}
+	}
+	newData, err := marshalDiffData(app.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if d := diffLines(oldData, newData, nil); d != "" {
+		diffs = append(diffs, ResourceDiff{Kind: v1beta1.ApplicationKind, Name: app.Name, Namespace: app.Namespace, Diff: d})
+	}
+
+	for _, def := range defs {
+		existDef := &unstructured.Unstructured{}
+		existDef.SetGroupVersionKind(def.GroupVersionKind())
+		oldData = ""
+		err := clt.Get(ctx, client.ObjectKey{Namespace: def.GetNamespace(), Name: def.GetName()}, existDef)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			if oldData, err = marshalDiffData(existDef.Object["spec"]); err != nil {
+				return nil, err
+			}
+		}
+		if newData, err = marshalDiffData(def.Object["spec"]); err != nil {
+			return nil, err
+		}
+		if d := diffLines(oldData, newData, nil); d != "" {
+			diffs = append(diffs, ResourceDiff{Kind: def.GetKind(), Name: def.GetName(), Namespace: def.GetNamespace(), Diff: d})
+		}
+	}
+
+	if sec != nil {
+		existSec := &v1.Secret{}
+		var oldLines []string
+		err := clt.Get(ctx, client.ObjectKey{Namespace: sec.Namespace, Name: sec.Name}, existSec)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			for k, v := range existSec.Data {
+				oldLines = append(oldLines, fmt.Sprintf("%s: %s", k, v))
+			}
+		}
+		var newLines []string
+		for k, v := range sec.StringData {
+			newLines = append(newLines, fmt.Sprintf("%s: %s", k, v))
+		}
+		sort.Strings(oldLines)
+		sort.Strings(newLines)
+		if d := diffLines(strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"), maskSecretLine); d != "" {
+			diffs = append(diffs, ResourceDiff{Kind: "Secret", Name: sec.Name, Namespace: sec.Namespace, Diff: d})
+		}
+	}
+	return diffs, nil
+}
+
+func marshalDiffData(data interface{}) (string, error) {
+	if data == nil {
+		return "", nil
+	}
+	b, err := yaml.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// diffLines returns the line based diff of the two texts, empty string is returned if there is no change
+func diffLines(oldData, newData string, mask func(string) string) string {
+	if oldData == newData {
+		return ""
+	}
+	var oldLines, newLines []string
+	if oldData != "" {
+		oldLines = strings.Split(oldData, "\n")
+	}
+	if newData != "" {
+		newLines = strings.Split(newData, "\n")
+	}
+	var sb strings.Builder
+	for _, record := range difflib.Diff(oldLines, newLines) {
+		payload := record.Payload
+		if mask != nil {
+			payload = mask(payload)
+		}
+		switch record.Delta {
+		case difflib.LeftOnly:
+			sb.WriteString("- " + payload + "\n")
+		case difflib.RightOnly:
+			sb.WriteString("+ " + payload + "\n")
+		default:
+			sb.WriteString("  " + payload + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func maskSecretLine(line string) string {
+	if i := strings.Index(line, ": "); i >= 0 {
+		return line[:i] + ": ******"
+	}
+	return line
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestConvertArgs(t *testing.T) {
+	addon, err := (&LocalAddonSource{Path: "./testdata"}).GetAddon("example", GetLevelOptions)
+	assert.NoError(t, err)
+	args := ConvertArgs(addon, map[string]string{"image": "nginx:1.20", "replicas": "2", "debug": "true", "other": "true"})
+	assert.Equal(t, map[string]interface{}{"image": "nginx:1.20", "replicas": int64(2), "debug": true, "other": "true"}, args)
+
+	// keep the string if fail to convert
+	args = ConvertArgs(addon, map[string]string{"replicas": "two"})
+	assert.Equal(t, map[string]interface{}{"replicas": "two"}, args)
+
+	args = ConvertArgs(addon, map[string]string{"labels": `{"app":"example"}`, "ports": "[80,443]"})
+	assert.Equal(t, map[string]interface{}{"labels": map[string]interface{}{"app": "example"}, "ports": []interface{}{float64(80), float64(443)}}, args)
+}
+
+func TestArgsSecret(t *testing.T) {
+	ctx := context.Background()
+	addon, err := (&LocalAddonSource{Path: "./testdata"}).GetAddon("example", GetLevelOptions)
+	assert.NoError(t, err)
+	args := map[string]interface{}{
+		"image":    "nginx:1.20",
+		"replicas": int64(2),
+		"debug":    true,
+		"labels":   map[string]interface{}{"app": "example"},
+		"ports":    []interface{}{float64(80), float64(443)},
+	}
+	sec := RenderArgsSecret(addon, args)
+	assert.Equal(t, map[string]string{
+		"image":    "nginx:1.20",
+		"replicas": "2",
+		"debug":    "true",
+		"labels":   `{"app":"example"}`,
+		"ports":    "[80,443]",
+	}, sec.StringData)
+
+	data := map[string][]byte{}
+	for k, v := range sec.StringData {
+		data[k] = []byte(v)
+	}
+	clt := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: sec.Name, Namespace: sec.Namespace},
+		Data:       data,
+	}).Build()
+	got, err := GetArgsFromSecret(ctx, clt, addon)
+	assert.NoError(t, err)
+	assert.Equal(t, args, got)
+}
+
+func TestPrepareUpgrade(t *testing.T) {
+	ctx := context.Background()
+	addon, err := (&LocalAddonSource{Path: "./testdata"}).GetAddon("example", EnableLevelOptions)
+	assert.NoError(t, err)
+
+	clt := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	_, err = PrepareUpgrade(ctx, clt, addon, nil)
+	assert.True(t, errors.Is(err, ErrNotEnabled))
+
+	// the enabled addon of an old version is deployed to the selected clusters with the recorded args
+	selectors := NewClusterSelectors([]string{"cluster-1"}, nil)
+	oldArgs := map[string]interface{}{"image": "nginx:1.20", "replicas": int64(2)}
+	app, defs, err := RenderApplication(addon, oldArgs)
+	assert.NoError(t, err)
+	_, err = RenderClusterPlacement(app, defs, selectors)
+	assert.NoError(t, err)
+	app.Labels[oam.LabelAddonVersion] = "0.9.0"
+	sec := RenderArgsSecret(addon, oldArgs)
+	data := map[string][]byte{}
+	for k, v := range sec.StringData {
+		data[k] = []byte(v)
+	}
+	clt = fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(app, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: sec.Name, Namespace: sec.Namespace},
+		Data:       data,
+	}).Build()
+
+	upgrade, err := PrepareUpgrade(ctx, clt, addon, map[string]interface{}{"image": "nginx:1.21"})
+	assert.NoError(t, err)
+	assert.Equal(t, "0.9.0", upgrade.PreviousVersion)
+	assert.Equal(t, "1.0.0", upgrade.Version)
+	assert.Equal(t, selectors, upgrade.Selectors)
+	assert.Equal(t, "nginx:1.21", upgrade.Secret.StringData["image"])
+	assert.Equal(t, "2", upgrade.Secret.StringData["replicas"])
+	var kinds []string
+	for _, d := range upgrade.Diffs {
+		kinds = append(kinds, d.Kind)
+	}
+	assert.Equal(t, []string{"Application", "TraitDefinition", "Secret"}, kinds)
+
+	// the dependencies which are not enabled are rendered to the same clusters and listed in the diffs
+	assert.NoError(t, upgrade.PrepareDependencies(ctx, clt, []*types.Addon{newTestAddon("dep", "0.1.0")}))
+	assert.Equal(t, 1, len(upgrade.Dependencies))
+	dep := upgrade.Dependencies[0]
+	assert.Equal(t, "0.1.0", dep.Version)
+	assert.Equal(t, Convert2AppName("dep"), dep.App.Name)
+	assert.Equal(t, selectors, dep.Selectors)
+	assert.Equal(t, 4, len(upgrade.Diffs))
+	assert.Equal(t, dep.Diffs[0], upgrade.Diffs[3])
+	assert.Equal(t, Convert2AppName("dep"), upgrade.Diffs[3].Name)
+}
+
+func TestDiffAddon(t *testing.T) {
+	ctx := context.Background()
+	addon, err := (&LocalAddonSource{Path: "./testdata"}).GetAddon("example", EnableLevelOptions)
+	assert.NoError(t, err)
+
+	app, defs, err := RenderApplication(addon, map[string]interface{}{"image": "nginx:1.20"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", app.Labels[oam.LabelAddonVersion])
+	sec := RenderArgsSecret(addon, map[string]interface{}{"image": "nginx:1.20"})
+
+	// render again to make sure the addon is not changed by rendering
+	app2, _, err := RenderApplication(addon, map[string]interface{}{"image": "nginx:1.20"})
+	assert.NoError(t, err)
+	assert.Equal(t, len(app.Spec.Components), len(app2.Spec.Components))
+
+	// all the resources are new if the addon is not enabled
+	clt := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	diffs, err := DiffAddon(ctx, clt, app, defs, sec)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(diffs))
+	assert.Equal(t, "Application", diffs[0].Kind)
+	assert.Equal(t, "Secret", diffs[1].Kind)
+	assert.Equal(t, "+ image: ******\n", diffs[1].Diff)
+
+	existSec := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: sec.Name, Namespace: types.DefaultKubeVelaNS},
+		Data:       map[string][]byte{"image": []byte("nginx:1.20")},
+	}
+	clt = fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(app.DeepCopy(), existSec).Build()
+	diffs, err = DiffAddon(ctx, clt, app, defs, sec)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(diffs))
+
+	args, err := GetArgsFromSecret(ctx, clt, addon)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"image": "nginx:1.20"}, args)
+
+	newArgs := map[string]interface{}{"image": "nginx:1.21"}
+	app, defs, err = RenderApplication(addon, newArgs)
+	assert.NoError(t, err)
+	diffs, err = DiffAddon(ctx, clt, app, defs, RenderArgsSecret(addon, newArgs))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(diffs))
+	assert.True(t, strings.Contains(diffs[0].Diff, "- ") && strings.Contains(diffs[0].Diff, "+ "))
+	assert.Contains(t, diffs[0].Diff, "nginx:1.21")
+	assert.Equal(t, "- image: ******\n+ image: ******\n", diffs[1].Diff)
+}
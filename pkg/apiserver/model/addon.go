@@ -23,7 +23,7 @@ type AddonRegistry struct {
 	Model
 	Name string `json:"name"`
 
-	Git *addon.GitAddonSource `json:"git,omitempty"`
+	addon.RegistrySource
 }
 
 // TableName return custom table name
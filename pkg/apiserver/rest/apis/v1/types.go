@@ -60,19 +60,19 @@ type EmptyResponse struct{}
 
 // CreateAddonRegistryRequest defines the format for addon registry create request
 type CreateAddonRegistryRequest struct {
-	Name string                `json:"name" validate:"checkname"`
-	Git  *addon.GitAddonSource `json:"git,omitempty" validate:"required"`
+	Name string `json:"name" validate:"checkname"`
+	addon.RegistrySource
 }
 
 // UpdateAddonRegistryRequest defines the format for addon registry update request
 type UpdateAddonRegistryRequest struct {
-	Git *addon.GitAddonSource `json:"git,omitempty" validate:"required"`
+	addon.RegistrySource
 }
 
 // AddonRegistryMeta defines the format for a single addon registry
 type AddonRegistryMeta struct {
-	Name string                `json:"name" validate:"required"`
-	Git  *addon.GitAddonSource `json:"git,omitempty"`
+	Name string `json:"name" validate:"required"`
+	addon.RegistrySource
 }
 
 // ListAddonRegistryResponse list addon registry
@@ -84,6 +84,27 @@ type ListAddonRegistryResponse struct {
 type EnableAddonRequest struct {
 	// Args is the key-value environment variables, e.g. AK/SK credentials.
 	Args map[string]interface{} `json:"args,omitempty"`
+	// Clusters are the names of the clusters to deploy the addon to
+	Clusters []string `json:"clusters,omitempty"`
+	// ClusterSelector selects the clusters to deploy the addon to by labels
+	ClusterSelector map[string]string `json:"clusterSelector,omitempty"`
+}
+
+// UpgradeAddonRequest defines the format for upgrade addon request
+type UpgradeAddonRequest struct {
+	// Args is merged into the args of the enabled addon, the existing args are kept if not specified.
+	Args map[string]interface{} `json:"args,omitempty"`
+	// DryRun only returns the diff without applying the changes
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// UpgradeAddonResponse defines the format for upgrade addon response
+type UpgradeAddonResponse struct {
+	PreviousVersion string               `json:"previousVersion,omitempty"`
+	Version         string               `json:"version,omitempty"`
+	Diffs           []addon.ResourceDiff `json:"diffs"`
+	// Dependencies are the addons which are not enabled, they are enabled along with the upgrade
+	Dependencies []string `json:"dependencies,omitempty"`
 }
 
 // ListAddonResponse defines the format for addon list response
@@ -115,6 +136,8 @@ type AddonDefinition struct {
 type AddonStatusResponse struct {
 	Phase AddonPhase        `json:"phase"`
 	Args  map[string]string `json:"args"`
+	// Clusters is the status of the addon in each target cluster if the addon is deployed to specified clusters
+	Clusters []addon.ClusterStatus `json:"clusters,omitempty"`
 
 	EnablingProgress *EnablingProgress `json:"enabling_progress,omitempty"`
 }
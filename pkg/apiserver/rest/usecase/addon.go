@@ -56,6 +56,7 @@ type AddonUsecase interface {
 	StatusAddon(ctx context.Context, name string) (*apis.AddonStatusResponse, error)
 	GetAddon(ctx context.Context, name string, registry string) (*apis.DetailAddonResponse, error)
 	EnableAddon(ctx context.Context, name string, args apis.EnableAddonRequest) error
+	UpgradeAddon(ctx context.Context, name string, req apis.UpgradeAddonRequest) (*apis.UpgradeAddonResponse, error)
 	DisableAddon(ctx context.Context, name string) error
 }
 
@@ -118,7 +119,7 @@ func (u *addonUsecaseImpl) GetAddon(ctx context.Context, name string, registry s
 		}
 		for _, r := range registries {
 			if addon, exist = u.tryGetAddonFromCache(r.Name, name); !exist {
-				addon, err = pkgaddon.GetAddon(name, r.Git, pkgaddon.GetLevelOptions)
+				addon, err = getAddonFromRegistry(r.RegistrySource, name, pkgaddon.GetLevelOptions)
 			}
 			if err != nil && !errors.Is(err, pkgaddon.ErrNotExist) {
 				return nil, err
@@ -132,7 +133,7 @@ func (u *addonUsecaseImpl) GetAddon(ctx context.Context, name string, registry s
 		if err != nil {
 			return nil, err
 		}
-		addon, err = pkgaddon.GetAddon(name, addonRegistry.Git, pkgaddon.GetLevelOptions)
+		addon, err = getAddonFromRegistry(addonRegistry.RegistrySource, name, pkgaddon.GetLevelOptions)
 		if err != nil && !errors.Is(err, pkgaddon.ErrNotExist) {
 			return nil, err
 		}
@@ -165,11 +166,17 @@ func (u *addonUsecaseImpl) StatusAddon(ctx context.Context, name string) (*apis.
 		return nil, bcode.ErrGetAddonApplication
 	}
 
+	clusters, err := pkgaddon.GetClusterStatus(&app)
+	if err != nil {
+		return nil, bcode.ErrGetAddonApplication
+	}
+
 	switch app.Status.Phase {
 	case common2.ApplicationRunning:
 		res := apis.AddonStatusResponse{
 			Phase:            apis.AddonPhaseEnabled,
 			EnablingProgress: nil,
+			Clusters:         clusters,
 		}
 		var sec v1.Secret
 		err := u.kubeClient.Get(ctx, client.ObjectKey{
@@ -188,6 +195,7 @@ func (u *addonUsecaseImpl) StatusAddon(ctx context.Context, name string) (*apis.
 		return &apis.AddonStatusResponse{
 			Phase:            apis.AddonPhaseEnabling,
 			EnablingProgress: nil,
+			Clusters:         clusters,
 		}, nil
 	}
 }
@@ -207,19 +215,24 @@ func (u *addonUsecaseImpl) ListAddons(ctx context.Context, registry, query strin
 		if u.isRegistryCacheUpToDate(r.Name) {
 			listAddons = u.getRegistryCache(r.Name)
 		} else {
-			listAddons, err = pkgaddon.ListAddons(r.Git, pkgaddon.GetLevelOptions)
+			source, err := r.BuildSource()
+			if err != nil {
+				log.Logger.Errorf("invalid addon registry %s, %v", r.Name, err)
+				continue
+			}
+			listAddons, err = source.ListAddons(pkgaddon.GetLevelOptions)
 			if err != nil {
 				log.Logger.Errorf("fail to get addons from registry %s, %v", r.Name, err)
 				continue
 			}
 			// if list addons, details will be retrieved later
-			go func() {
-				addonDetails, err := pkgaddon.ListAddons(r.Git, pkgaddon.EnableLevelOptions)
+			go func(name string) {
+				addonDetails, err := source.ListAddons(pkgaddon.EnableLevelOptions)
 				if err != nil {
 					return
 				}
-				u.putRegistryCache(r.Name, addonDetails)
-			}()
+				u.putRegistryCache(name, addonDetails)
+			}(r.Name)
 		}
 		addons = mergeAddons(addons, listAddons)
 	}
@@ -259,6 +272,9 @@ func (u *addonUsecaseImpl) DeleteAddonRegistry(ctx context.Context, name string)
 }
 
 func (u *addonUsecaseImpl) CreateAddonRegistry(ctx context.Context, req apis.CreateAddonRegistryRequest) (*apis.AddonRegistryMeta, error) {
+	if _, err := req.BuildSource(); err != nil {
+		return nil, bcode.ErrAddonRegistryInvalid
+	}
 	r := addonRegistryModelFromCreateAddonRegistryRequest(req)
 
 	err := u.addonRegistryDS.Add(ctx, r)
@@ -270,8 +286,8 @@ func (u *addonUsecaseImpl) CreateAddonRegistry(ctx context.Context, req apis.Cre
 	}
 
 	return &apis.AddonRegistryMeta{
-		Name: r.Name,
-		Git:  r.Git,
+		Name:           r.Name,
+		RegistrySource: r.RegistrySource,
 	}, nil
 }
 
@@ -294,15 +310,18 @@ func (u addonUsecaseImpl) UpdateAddonRegistry(ctx context.Context, name string,
 	if err != nil {
 		return nil, bcode.ErrAddonRegistryNotExist
 	}
-	r.Git = req.Git
+	if _, err := req.BuildSource(); err != nil {
+		return nil, bcode.ErrAddonRegistryInvalid
+	}
+	r.RegistrySource = req.RegistrySource
 	err = u.addonRegistryDS.Put(ctx, &r)
 	if err != nil {
 		return nil, err
 	}
 
 	return &apis.AddonRegistryMeta{
-		Name: r.Name,
-		Git:  r.Git,
+		Name:           r.Name,
+		RegistrySource: r.RegistrySource,
 	}, nil
 }
 
@@ -345,7 +364,7 @@ func (u *addonUsecaseImpl) EnableAddon(ctx context.Context, name string, args ap
 	for _, r := range registries {
 		var exist bool
 		if addon, exist = u.tryGetAddonFromCache(r.Name, name); !exist {
-			addon, err = pkgaddon.GetAddon(name, r.Git, pkgaddon.EnableLevelOptions)
+			addon, err = getAddonFromRegistry(r.RegistrySource, name, pkgaddon.EnableLevelOptions)
 		}
 		if err != nil && !errors.Is(err, pkgaddon.ErrNotExist) {
 			return bcode.WrapGithubRateLimitErr(err)
@@ -354,44 +373,163 @@ func (u *addonUsecaseImpl) EnableAddon(ctx context.Context, name string, args ap
 			continue
 		}
 
-		if !pkgaddon.CheckDependencies(ctx, u.kubeClient, addon) {
-			return bcode.ErrAddonDependencyNotSatisfy
+		err = u.kubeClient.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: pkgaddon.Convert2AppName(name)}, &v1beta1.Application{})
+		if err == nil {
+			return bcode.ErrAddonIsEnabled
+		}
+
+		selectors := pkgaddon.NewClusterSelectors(args.Clusters, args.ClusterSelector)
+		if err := pkgaddon.ValidateClusterSelectors(ctx, u.kubeClient, selectors); err != nil {
+			log.Logger.Errorf("invalid target clusters of addon %s: %v", name, err)
+			return bcode.ErrAddonClusterInvalid
+		}
+
+		if err := u.enableDependencies(ctx, registries, addon, selectors); err != nil {
+			return err
 		}
 
 		app, defs, err := pkgaddon.RenderApplication(addon, args.Args)
 		if err != nil {
 			return bcode.ErrAddonRender
 		}
+		if defs, err = pkgaddon.RenderClusterPlacement(app, defs, selectors); err != nil {
+			return bcode.ErrAddonRender
+		}
 
-		err = u.kubeClient.Get(ctx, client.ObjectKey{Namespace: app.GetNamespace(), Name: app.GetName()}, app)
-		if err == nil {
-			return bcode.ErrAddonIsEnabled
+		return u.applyAddon(ctx, app, defs, pkgaddon.RenderArgsSecret(addon, args.Args))
+	}
+	return bcode.ErrAddonNotExist
+}
+
+func (u *addonUsecaseImpl) UpgradeAddon(ctx context.Context, name string, req apis.UpgradeAddonRequest) (*apis.UpgradeAddonResponse, error) {
+	registries, err := u.ListAddonRegistries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range registries {
+		// skip the cache, a newer version may be published after the cache was refreshed
+		addon, err := getAddonFromRegistry(r.RegistrySource, name, pkgaddon.EnableLevelOptions)
+		if err != nil && !errors.Is(err, pkgaddon.ErrNotExist) {
+			return nil, bcode.WrapGithubRateLimitErr(err)
+		}
+		if addon == nil {
+			continue
 		}
 
-		err = u.apply.Apply(ctx, app)
+		upgrade, err := pkgaddon.PrepareUpgrade(ctx, u.kubeClient, addon, req.Args)
 		if err != nil {
-			log.Logger.Errorf("create application fail: %s", err.Error())
-			return bcode.ErrAddonApply
+			if errors.Is(err, pkgaddon.ErrNotEnabled) {
+				return nil, bcode.ErrAddonNotEnabled
+			}
+			log.Logger.Errorf("fail to prepare the upgrade of addon %s: %v", name, err)
+			return nil, err
 		}
-
-		for _, def := range defs {
-			addOwner(def, app)
-			err = u.apply.Apply(ctx, def)
-			if err != nil {
-				log.Logger.Errorf("apply definition fail: %v", err)
-				return bcode.ErrAddonApply
+		deps, err := u.resolveDependencies(ctx, registries, addon)
+		if err != nil {
+			return nil, err
+		}
+		if err := upgrade.PrepareDependencies(ctx, u.kubeClient, deps); err != nil {
+			log.Logger.Errorf("fail to prepare the dependencies of addon %s: %v", name, err)
+			return nil, bcode.ErrAddonRender
+		}
+		res := &apis.UpgradeAddonResponse{
+			PreviousVersion: upgrade.PreviousVersion,
+			Version:         upgrade.Version,
+			Diffs:           upgrade.Diffs,
+		}
+		for _, dep := range deps {
+			res.Dependencies = append(res.Dependencies, dep.Name)
+		}
+		if req.DryRun {
+			return res, nil
+		}
+		for i, dep := range upgrade.Dependencies {
+			log.Logger.Infof("enable addon %s as a dependency of addon %s", deps[i].Name, name)
+			if err := u.applyAddon(ctx, dep.App, dep.Defs, dep.Secret); err != nil {
+				return nil, err
 			}
 		}
+		if err := u.applyAddon(ctx, upgrade.App, upgrade.Defs, upgrade.Secret); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	return nil, bcode.ErrAddonNotExist
+}
+
+// resolveDependencies returns the missing dependencies of the addon in install order
+func (u *addonUsecaseImpl) resolveDependencies(ctx context.Context, registries []*apis.AddonRegistryMeta, addon *types.Addon) ([]*types.Addon, error) {
+	deps, err := pkgaddon.ResolveDependencies(ctx, u.kubeClient, buildRegistrySource(registries), addon)
+	if err != nil {
+		log.Logger.Errorf("fail to resolve the dependencies of addon %s: %v", addon.Name, err)
+		if errors.Is(err, pkgaddon.ErrDependencyNotSatisfied) || errors.Is(err, pkgaddon.ErrDependencyCycle) {
+			return nil, bcode.ErrAddonDependencyNotSatisfy
+		}
+		return nil, bcode.WrapGithubRateLimitErr(err)
+	}
+	return deps, nil
+}
 
-		sec := pkgaddon.RenderArgsSecret(addon, args.Args)
-		err = u.apply.Apply(ctx, sec)
+// enableDependencies enables the missing dependencies of the addon in install order with the default args to the same
+// clusters as the addon
+func (u *addonUsecaseImpl) enableDependencies(ctx context.Context, registries []*apis.AddonRegistryMeta, addon *types.Addon,
+	selectors []common2.ClusterSelector) error {
+	deps, err := u.resolveDependencies(ctx, registries, addon)
+	if err != nil {
+		return err
+	}
+	for _, dep := range deps {
+		log.Logger.Infof("enable addon %s as a dependency of addon %s", dep.Name, addon.Name)
+		app, defs, err := pkgaddon.RenderApplication(dep, nil)
 		if err != nil {
-			return bcode.ErrAddonSecretApply
+			return bcode.ErrAddonRender
+		}
+		if defs, err = pkgaddon.RenderClusterPlacement(app, defs, selectors); err != nil {
+			return bcode.ErrAddonRender
+		}
+		if err := u.applyAddon(ctx, app, defs, pkgaddon.RenderArgsSecret(dep, nil)); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		return nil
+// buildRegistrySource builds the source reading addons from all the registries, the invalid registries are skipped
+func buildRegistrySource(registries []*apis.AddonRegistryMeta) pkgaddon.MultiSource {
+	var source pkgaddon.MultiSource
+	for _, r := range registries {
+		s, err := r.BuildSource()
+		if err != nil {
+			log.Logger.Errorf("invalid addon registry %s, %v", r.Name, err)
+			continue
+		}
+		source = append(source, s)
 	}
-	return bcode.ErrAddonNotExist
+	return source
+}
+
+// applyAddon applies the rendered addon application, definitions and args secret
+func (u *addonUsecaseImpl) applyAddon(ctx context.Context, app *v1beta1.Application, defs []*unstructured.Unstructured, sec *v1.Secret) error {
+	err := u.apply.Apply(ctx, app)
+	if err != nil {
+		log.Logger.Errorf("apply application fail: %s", err.Error())
+		return bcode.ErrAddonApply
+	}
+
+	for _, def := range defs {
+		addOwner(def, app)
+		err = u.apply.Apply(ctx, def)
+		if err != nil {
+			log.Logger.Errorf("apply definition fail: %v", err)
+			return bcode.ErrAddonApply
+		}
+	}
+
+	err = u.apply.Apply(ctx, sec)
+	if err != nil {
+		return bcode.ErrAddonSecretApply
+	}
+	return nil
 }
 
 func addOwner(child *unstructured.Unstructured, app *v1beta1.Application) {
@@ -416,6 +554,18 @@ func (u *addonUsecaseImpl) isRegistryCacheUpToDate(name string) bool {
 }
 
 func (u *addonUsecaseImpl) DisableAddon(ctx context.Context, name string) error {
+	registries, err := u.ListAddonRegistries(ctx)
+	if err != nil {
+		return err
+	}
+	dependents, err := pkgaddon.FindDependents(ctx, u.kubeClient, buildRegistrySource(registries), name)
+	if err != nil {
+		return bcode.ErrGetAddonApplication
+	}
+	if len(dependents) != 0 {
+		log.Logger.Errorf("addon %s is depended on by %s", name, strings.Join(dependents, ", "))
+		return bcode.ErrAddonIsDependedOn
+	}
 	app := &v1beta1.Application{
 		TypeMeta: metav1.TypeMeta{APIVersion: "core.oam.dev/v1beta1", Kind: "Application"},
 		ObjectMeta: metav1.ObjectMeta{
@@ -423,7 +573,7 @@ func (u *addonUsecaseImpl) DisableAddon(ctx context.Context, name string) error
 			Namespace: types.DefaultKubeVelaNS,
 		},
 	}
-	err := u.kubeClient.Delete(ctx, app)
+	err = u.kubeClient.Delete(ctx, app)
 	if err != nil {
 		log.Logger.Errorf("delete application fail: %s", err.Error())
 		return err
@@ -433,9 +583,17 @@ func (u *addonUsecaseImpl) DisableAddon(ctx context.Context, name string) error
 
 func addonRegistryModelFromCreateAddonRegistryRequest(req apis.CreateAddonRegistryRequest) *model.AddonRegistry {
 	return &model.AddonRegistry{
-		Name: req.Name,
-		Git:  req.Git,
+		Name:           req.Name,
+		RegistrySource: req.RegistrySource,
+	}
+}
+
+func getAddonFromRegistry(r pkgaddon.RegistrySource, name string, opt pkgaddon.ListOptions) (*types.Addon, error) {
+	source, err := r.BuildSource()
+	if err != nil {
+		return nil, err
 	}
+	return source.GetAddon(name, opt)
 }
 
 func mergeAddons(a1, a2 []*types.Addon) []*types.Addon {
@@ -460,7 +618,7 @@ func hasAddon(addons []*types.Addon, name string) bool {
 // ConvertAddonRegistryModel2AddonRegistryMeta will convert from model to AddonRegistryMeta
 func ConvertAddonRegistryModel2AddonRegistryMeta(r *model.AddonRegistry) *apis.AddonRegistryMeta {
 	return &apis.AddonRegistryMeta{
-		Name: r.Name,
-		Git:  r.Git,
+		Name:           r.Name,
+		RegistrySource: r.RegistrySource,
 	}
 }
@@ -40,7 +40,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
 )
 
-// DefinitionUsecase definition usecase, Implement the management of ComponentDefinition、TraitDefinition and WorkflowStepDefinition.
+// DefinitionUsecase definition usecase, Implement the management of ComponentDefinition、TraitDefinition、WorkflowStepDefinition and PolicyDefinition.
 type DefinitionUsecase interface {
 	// ListDefinitions list definition base info
 	ListDefinitions(ctx context.Context, envName, defType string) ([]*apisv1.DefinitionBase, error)
@@ -60,6 +60,7 @@ const (
 	kindComponentDefinition    = "ComponentDefinition"
 	kindTraitDefinition        = "TraitDefinition"
 	kindWorkflowStepDefinition = "WorkflowStepDefinition"
+	kindPolicyDefinition       = "PolicyDefinition"
 )
 
 // NewDefinitionUsecase new definition usecase
@@ -89,6 +90,11 @@ func (d *definitionUsecaseImpl) ListDefinitions(ctx context.Context, envName, de
 		defs.SetKind(kindWorkflowStepDefinition)
 		return d.listDefinitions(ctx, defs, kindWorkflowStepDefinition)
 
+	case "policy":
+		defs.SetAPIVersion(definitionAPIVersion)
+		defs.SetKind(kindPolicyDefinition)
+		return d.listDefinitions(ctx, defs, kindPolicyDefinition)
+
 	default:
 		return nil, bcode.ErrDefinitionTypeNotSupport
 	}
@@ -116,7 +122,7 @@ func (d *definitionUsecaseImpl) listDefinitions(ctx context.Context, list *unstr
 
 // DetailDefinition get definition detail
 func (d *definitionUsecaseImpl) DetailDefinition(ctx context.Context, name, defType string) (*apisv1.DetailDefinitionResponse, error) {
-	if !utils.StringsContain([]string{"component", "trait", "workflowstep"}, defType) {
+	if !utils.StringsContain([]string{"component", "trait", "workflowstep", "policy"}, defType) {
 		return nil, bcode.ErrDefinitionTypeNotSupport
 	}
 	var cm v1.ConfigMap
@@ -93,6 +93,20 @@ var _ = Describe("Test namespace usecase functions", func() {
 		Expect(cmp.Diff(len(wfstep), 1)).Should(BeEmpty())
 		Expect(cmp.Diff(wfstep[0].Name, "apply-application")).Should(BeEmpty())
 		Expect(wfstep[0].Description).ShouldNot(BeEmpty())
+
+		By("List policy definitions")
+		envBinding, err := ioutil.ReadFile("./testdata/envbinding-pd.yaml")
+		Expect(err).Should(Succeed())
+		var pd v1beta1.PolicyDefinition
+		err = yaml.Unmarshal(envBinding, &pd)
+		Expect(err).Should(Succeed())
+		err = k8sClient.Create(context.Background(), &pd)
+		Expect(err).Should(Succeed())
+		policies, err := definitionUsecase.ListDefinitions(context.TODO(), "", "policy")
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(len(policies), 1)).Should(BeEmpty())
+		Expect(cmp.Diff(policies[0].Name, "env-binding")).Should(BeEmpty())
+		Expect(policies[0].Description).ShouldNot(BeEmpty())
 	})
 
 	It("Test DetailDefinition function", func() {
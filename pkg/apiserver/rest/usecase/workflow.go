@@ -40,6 +40,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/pkg/utils/apply"
+	wf "github.com/oam-dev/kubevela/pkg/workflow"
 )
 
 // WorkflowUsecase workflow manage api
@@ -56,8 +57,9 @@ type WorkflowUsecase interface {
 	ListWorkflowRecords(ctx context.Context, workflow *model.Workflow, page, pageSize int) (*apisv1.ListWorkflowRecordsResponse, error)
 	DetailWorkflowRecord(ctx context.Context, workflow *model.Workflow, recordName string) (*apisv1.DetailWorkflowRecordResponse, error)
 	SyncWorkflowRecord(ctx context.Context) error
-	ResumeRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName string) error
+	ResumeRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName, approver string) error
 	TerminateRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName string) error
+	RetryRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName, step string) error
 	RollbackRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName, revisionName string) error
 	CountWorkflow(ctx context.Context, app *model.Application) int64
 }
@@ -524,13 +526,15 @@ func (w *workflowUsecaseImpl) CountWorkflow(ctx context.Context, app *model.Appl
 	return count
 }
 
-func (w *workflowUsecaseImpl) ResumeRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName string) error {
+func (w *workflowUsecaseImpl) ResumeRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName, approver string) error {
 	oamApp, err := w.checkRecordRunning(ctx, appModel, workflow.EnvName)
 	if err != nil {
 		return err
 	}
 
 	oamApp.Status.Workflow.Suspend = false
+	// the apiserver doesn't authenticate the requests, so the approver is only what the client claims
+	wf.ApproveSuspendedSteps(oamApp.Status.Workflow, approver, true)
 	if err := w.kubeClient.Status().Patch(ctx, oamApp, client.Merge); err != nil {
 		return err
 	}
@@ -558,6 +562,26 @@ func (w *workflowUsecaseImpl) TerminateRecord(ctx context.Context, appModel *mod
 	return nil
 }
 
+func (w *workflowUsecaseImpl) RetryRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName, step string) error {
+	oamApp, err := w.checkRecordRunning(ctx, appModel, workflow.EnvName)
+	if err != nil {
+		return err
+	}
+
+	if err := wf.RetryStep(oamApp, step); err != nil {
+		return err
+	}
+	// the status of the retried steps are removed, update the whole status as the merge patch can't remove them.
+	if err := w.kubeClient.Status().Update(ctx, oamApp); err != nil {
+		return err
+	}
+	if err := w.syncWorkflowStatus(ctx, oamApp, recordName, oamApp.Name); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (w *workflowUsecaseImpl) RollbackRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName, revisionVersion string) error {
 	if revisionVersion == "" {
 		// find the latest complete revision version
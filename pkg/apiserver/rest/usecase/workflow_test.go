@@ -27,9 +27,11 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/apiserver/model"
 	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
@@ -322,7 +324,7 @@ var _ = Describe("Test workflow usecase functions", func() {
 		err = workflowUsecase.ResumeRecord(ctx, &model.Application{
 			Name:      appName,
 			Namespace: "default",
-		}, &model.Workflow{Name: ResumeWorkflow, EnvName: "resume"}, "workflow-resume-1")
+		}, &model.Workflow{Name: ResumeWorkflow, EnvName: "resume"}, "workflow-resume-1", "")
 		Expect(err).Should(BeNil())
 
 		record, err := workflowUsecase.DetailWorkflowRecord(ctx, &model.Workflow{Name: ResumeWorkflow, AppPrimaryKey: appName}, "workflow-resume-1")
@@ -374,6 +376,65 @@ var _ = Describe("Test workflow usecase functions", func() {
 		Expect(record.Status).Should(Equal(model.RevisionStatusTerminated))
 	})
 
+	It("Test RetryRecord function", func() {
+		ctx := context.TODO()
+
+		workflowName := "retry-workflow"
+		req := apisv1.CreateWorkflowRequest{
+			Name:        workflowName,
+			Description: "this is a workflow",
+			EnvName:     "retry",
+		}
+		workflow := &model.Workflow{Name: workflowName, EnvName: "retry"}
+		base, err := workflowUsecase.CreateOrUpdateWorkflow(context.TODO(), &model.Application{
+			Name:      appName,
+			Namespace: "default",
+		}, req)
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(base.Name, req.Name)).Should(BeEmpty())
+
+		app, err := createTestSuspendApp(ctx, appName, "retry", "revision-retry1", workflow.Name, "test-workflow-retry-1", workflowUsecase.kubeClient)
+		Expect(err).Should(BeNil())
+		app.Status.Workflow.Suspend = false
+		app.Status.Workflow.Terminated = true
+		app.Status.Workflow.Steps = []common.WorkflowStepStatus{
+			{ID: "step-1-id", Name: "step-1", Type: "apply-component", Phase: common.WorkflowStepPhaseSucceeded},
+			{ID: "step-2-id", Name: "step-2", Type: "webhook-notification", Phase: common.WorkflowStepPhaseFailed},
+		}
+		Expect(workflowUsecase.kubeClient.Status().Update(ctx, app)).Should(BeNil())
+
+		err = workflowUsecase.CreateWorkflowRecord(context.TODO(), &model.Application{
+			Name:      appName,
+			Namespace: "default",
+		}, app, workflow)
+		Expect(err).Should(BeNil())
+
+		err = workflowUsecase.createTestApplicationRevision(ctx, &model.ApplicationRevision{
+			AppPrimaryKey: appName,
+			Version:       "revision-retry1",
+			Status:        model.RevisionStatusRunning,
+		})
+		Expect(err).Should(BeNil())
+
+		err = workflowUsecase.RetryRecord(ctx, &model.Application{
+			Name:      appName,
+			Namespace: "default",
+		}, workflow, "test-workflow-retry-1", "step-1")
+		Expect(err).ShouldNot(BeNil())
+
+		err = workflowUsecase.RetryRecord(ctx, &model.Application{
+			Name:      appName,
+			Namespace: "default",
+		}, workflow, "test-workflow-retry-1", "step-2")
+		Expect(err).Should(BeNil())
+
+		oamApp := &v1beta1.Application{}
+		Expect(workflowUsecase.kubeClient.Get(ctx, types.NamespacedName{Name: app.Name, Namespace: app.Namespace}, oamApp)).Should(BeNil())
+		Expect(oamApp.Status.Workflow.Terminated).Should(BeFalse())
+		Expect(len(oamApp.Status.Workflow.Steps)).Should(Equal(1))
+		Expect(oamApp.Status.Workflow.Steps[0].Name).Should(Equal("step-1"))
+	})
+
 	It("Test RollbackRecord function", func() {
 		ctx := context.TODO()
 
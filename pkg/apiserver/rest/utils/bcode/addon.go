@@ -61,6 +61,15 @@ var (
 
 	// ErrAddonDependencyNotSatisfy means addon's dependencies is not enabled
 	ErrAddonDependencyNotSatisfy = NewBcode(500, 50017, "addon's dependencies is not enabled")
+
+	// ErrAddonNotEnabled means addon hasn't been enabled
+	ErrAddonNotEnabled = NewBcode(400, 50018, "addon is not enabled")
+
+	// ErrAddonIsDependedOn means addon is depended on by other enabled addons
+	ErrAddonIsDependedOn = NewBcode(400, 50019, "addon is depended on by other enabled addons")
+
+	// ErrAddonClusterInvalid means the target clusters of addon are invalid
+	ErrAddonClusterInvalid = NewBcode(400, 50020, "addon target clusters invalid")
 )
 
 // isGithubRateLimit check if error is github rate limit
@@ -17,6 +17,9 @@ limitations under the License.
 package webservice
 
 import (
+	"errors"
+	"io"
+
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
 	"github.com/emicklei/go-restful/v3"
 
@@ -87,6 +90,16 @@ func (s *addonWebService) GetWebService() *restful.WebService {
 		Param(ws.PathParameter("name", "addon name to enable").DataType("string").Required(true)).
 		Writes(apis.AddonStatusResponse{}))
 
+	// upgrade addon
+	ws.Route(ws.POST("/{name}/upgrade").To(s.upgradeAddon).
+		Doc("upgrade an enabled addon to the latest version or update its args, the diff is returned").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.UpgradeAddonRequest{}).
+		Returns(200, "", apis.UpgradeAddonResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Param(ws.PathParameter("name", "addon name to upgrade").DataType("string").Required(true)).
+		Writes(apis.UpgradeAddonResponse{}))
+
 	// disable addon
 	ws.Route(ws.POST("/{name}/disable").To(s.disableAddon).
 		Doc("disable an addon").
@@ -161,6 +174,31 @@ func (s *addonWebService) enableAddon(req *restful.Request, res *restful.Respons
 	s.statusAddon(req, res)
 }
 
+func (s *addonWebService) upgradeAddon(req *restful.Request, res *restful.Response) {
+	var upgradeReq apis.UpgradeAddonRequest
+	// the body can be empty to upgrade the addon with the existing args
+	if err := req.ReadEntity(&upgradeReq); err != nil && !errors.Is(err, io.EOF) {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&upgradeReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+
+	name := req.PathParameter("name")
+	upgradeRes, err := s.addonUsecase.UpgradeAddon(req.Request.Context(), name, upgradeReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+
+	if err := res.WriteEntity(upgradeRes); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (s *addonWebService) disableAddon(req *restful.Request, res *restful.Response) {
 	name := req.PathParameter("name")
 	err := s.addonUsecase.DisableAddon(req.Request.Context(), name)
@@ -434,6 +434,7 @@ func (c *applicationWebService) GetWebService() *restful.WebService {
 		Param(ws.PathParameter("name", "identifier of the application.").DataType("string").Required(true)).
 		Param(ws.PathParameter("workflowName", "identifier of the workflow").DataType("string")).
 		Param(ws.PathParameter("record", "identifier of the  workflow record").DataType("string")).
+		Param(ws.QueryParameter("approver", "the approver of the suspend steps waiting for approval, it is recorded as unverified").DataType("string")).
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Filter(c.appCheckFilter).
 		Filter(c.workflowCheckFilter).
@@ -453,6 +454,19 @@ func (c *applicationWebService) GetWebService() *restful.WebService {
 		Returns(400, "", bcode.Bcode{}).
 		Writes(apis.DetailWorkflowRecordResponse{}))
 
+	ws.Route(ws.GET("/{name}/workflows/{workflowName}/records/{record}/retry").To(c.retryWorkflowRecord).
+		Doc("retry the failed step of workflow record").
+		Param(ws.PathParameter("name", "identifier of the application.").DataType("string").Required(true)).
+		Param(ws.PathParameter("workflowName", "identifier of the workflow").DataType("string")).
+		Param(ws.PathParameter("record", "identifier of the workflow record").DataType("string")).
+		Param(ws.QueryParameter("step", "name of the failed step to retry").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.appCheckFilter).
+		Filter(c.workflowCheckFilter).
+		Returns(200, "", nil).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.DetailWorkflowRecordResponse{}))
+
 	ws.Route(ws.GET("/{name}/workflows/{workflowName}/records/{record}/rollback").To(c.rollbackWorkflowRecord).
 		Doc("rollback suspend application record").
 		Param(ws.PathParameter("name", "identifier of the application.").DataType("string").Required(true)).
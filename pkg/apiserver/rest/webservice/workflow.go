@@ -169,7 +169,7 @@ func (w *workflowWebService) detailWorkflowRecord(req *restful.Request, res *res
 func (w *workflowWebService) resumeWorkflowRecord(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	workflow := req.Request.Context().Value(&apis.CtxKeyWorkflow).(*model.Workflow)
-	err := w.workflowUsecase.ResumeRecord(req.Request.Context(), app, workflow, req.PathParameter("record"))
+	err := w.workflowUsecase.ResumeRecord(req.Request.Context(), app, workflow, req.PathParameter("record"), req.QueryParameter("approver"))
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -194,6 +194,20 @@ func (w *workflowWebService) terminateWorkflowRecord(req *restful.Request, res *
 	}
 }
 
+func (w *workflowWebService) retryWorkflowRecord(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	workflow := req.Request.Context().Value(&apis.CtxKeyWorkflow).(*model.Workflow)
+	err := w.workflowUsecase.RetryRecord(req.Request.Context(), app, workflow, req.PathParameter("record"), req.QueryParameter("step"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (w *workflowWebService) rollbackWorkflowRecord(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	workflow := req.Request.Context().Value(&apis.CtxKeyWorkflow).(*model.Workflow)
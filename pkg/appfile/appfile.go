@@ -177,7 +177,7 @@ func (af *Appfile) PrepareWorkflowAndPolicy() ([]*Workload, []*unstructured.Unst
 
 	for _, policy := range af.Policies {
 		switch policy.Type {
-		case v1alpha1.GarbageCollectPolicyType:
+		case v1alpha1.GarbageCollectPolicyType, v1alpha1.PreserveFieldsPolicyType:
 			builtInPolicies = append(builtInPolicies, policy)
 		case v1alpha1.EnvBindingPolicyType:
 		default:
@@ -233,7 +233,7 @@ func (p *Parser) parsePolicies(ctx context.Context, policies []v1beta1.AppPolicy
 	for _, policy := range policies {
 		var w *Workload
 		var err error
-		if policy.Type == "garbage-collect" {
+		if policy.Type == "garbage-collect" || policy.Type == "preserve-fields" {
 			w, err = p.makeBuiltInPolicy(policy.Name, policy.Type, policy.Properties)
 		} else {
 			w, err = p.makeWorkload(ctx, policy.Name, policy.Type, types.TypePolicy, policy.Properties)
@@ -76,7 +76,8 @@ func LoadTemplate(ctx context.Context, dm discoverymapper.DiscoveryMapper, cli c
 		cd := new(v1beta1.ComponentDefinition)
 		err := oamutil.GetCapabilityDefinition(ctx, cli, cd, capName)
 		if err != nil {
-			if kerrors.IsNotFound(err) {
+			// WorkloadDefinition has no revision, so the revision reference only refers to ComponentDefinition
+			if kerrors.IsNotFound(err) && !oamutil.IsDefinitionRevisionRef(capName) {
 				wd := new(v1beta1.WorkloadDefinition)
 				if err := oamutil.GetDefinition(ctx, cli, wd, capName); err != nil {
 					return nil, errors.WithMessagef(err, "LoadTemplate from workloadDefinition [%s] ", capName)
@@ -0,0 +1,318 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// the keys of the metric template ConfigMap
+const (
+	metricTemplateQueryKey    = "query"
+	metricTemplateProviderKey = "provider"
+	metricTemplateAddressKey  = "address"
+)
+
+// the default window size of the metric query
+const defaultMetricInterval = "1m"
+
+const metricQueryTimeout = 10 * time.Second
+
+// metricsProvider evaluates a query to a single value
+type metricsProvider interface {
+	Query(ctx context.Context, query string) (float64, error)
+}
+
+// newMetricsProvider is the factory of the metrics providers, it can be replaced in the tests
+var newMetricsProvider = func(provider v1alpha1.MetricProvider) (metricsProvider, error) {
+	switch provider.Type {
+	case "", v1alpha1.PrometheusMetricProvider:
+		return &prometheusProvider{address: provider.Address, client: &http.Client{Timeout: metricQueryTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("the metric provider type `%s` is not supported", provider.Type)
+	}
+}
+
+// metricViolation describes the metric that is out of the expected range
+type metricViolation struct {
+	metric v1alpha1.CanaryMetric
+	value  float64
+}
+
+func (v *metricViolation) Error() string {
+	return fmt.Sprintf("the value %v of the canary metric %s is out of the expected range %s", v.value, v.metric.Name,
+		formatMetricsRange(v.metric.MetricsRange))
+}
+
+// gatherAllCanaryMetrics returns the rollout level metrics and the metrics of the current batch
+func (r *Controller) gatherAllCanaryMetrics() []v1alpha1.CanaryMetric {
+	metrics := append([]v1alpha1.CanaryMetric{}, r.rolloutSpec.CanaryMetric...)
+	currentBatch := int(r.rolloutStatus.CurrentBatch)
+	if currentBatch < len(r.rolloutSpec.RolloutBatches) {
+		metrics = append(metrics, r.rolloutSpec.RolloutBatches[currentBatch].CanaryMetric...)
+	}
+	return metrics
+}
+
+// checkCanaryMetrics evaluates all the canary metrics of the current batch, it returns the first metric that
+// is out of the expected range or the error if any metric can't be evaluated
+func (r *Controller) checkCanaryMetrics(ctx context.Context) (*metricViolation, error) {
+	for _, metric := range r.gatherAllCanaryMetrics() {
+		value, err := r.evaluateCanaryMetric(ctx, metric)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate the canary metric %s: %w", metric.Name, err)
+		}
+		inRange, err := isInMetricsRange(value, metric.MetricsRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range of the canary metric %s: %w", metric.Name, err)
+		}
+		klog.InfoS("evaluated a canary metric", "metric name", metric.Name, "value", value, "in range", inRange)
+		if !inRange {
+			return &metricViolation{metric: metric, value: value}, nil
+		}
+	}
+	return nil, nil
+}
+
+// evaluateCanaryMetric queries the value of the metric from its provider
+func (r *Controller) evaluateCanaryMetric(ctx context.Context, metric v1alpha1.CanaryMetric) (float64, error) {
+	metric, err := r.resolveMetricTemplate(ctx, metric)
+	if err != nil {
+		return 0, err
+	}
+	if metric.Provider == nil || len(metric.Provider.Address) == 0 {
+		return 0, fmt.Errorf("the metric provider is not set")
+	}
+	if len(metric.Query) == 0 {
+		return 0, fmt.Errorf("the metric query is not set")
+	}
+	query, err := r.renderMetricQuery(metric)
+	if err != nil {
+		return 0, err
+	}
+	provider, err := newMetricsProvider(*metric.Provider)
+	if err != nil {
+		return 0, err
+	}
+	return provider.Query(ctx, query)
+}
+
+// resolveMetricTemplate fills the metric with the query and provider in the template it refers to
+func (r *Controller) resolveMetricTemplate(ctx context.Context, metric v1alpha1.CanaryMetric) (v1alpha1.CanaryMetric, error) {
+	ref := metric.TemplateRef
+	if ref == nil {
+		return metric, nil
+	}
+	if len(ref.Kind) != 0 && ref.Kind != "ConfigMap" {
+		return metric, fmt.Errorf("the metric template kind `%s` is not supported", ref.Kind)
+	}
+	namespace := ref.Namespace
+	if len(namespace) == 0 {
+		namespace = r.parentController.GetNamespace()
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, cm); err != nil {
+		return metric, fmt.Errorf("failed to get the metric template %s/%s: %w", namespace, ref.Name, err)
+	}
+	if len(metric.Query) == 0 {
+		metric.Query = cm.Data[metricTemplateQueryKey]
+	}
+	if metric.Provider == nil {
+		metric.Provider = &v1alpha1.MetricProvider{
+			Type:    v1alpha1.MetricProviderType(cm.Data[metricTemplateProviderKey]),
+			Address: cm.Data[metricTemplateAddressKey],
+		}
+	}
+	return metric, nil
+}
+
+// renderMetricQuery renders the query with the name and namespace of the rollout target and the metric interval
+func (r *Controller) renderMetricQuery(metric v1alpha1.CanaryMetric) (string, error) {
+	tmpl, err := template.New(metric.Name).Option("missingkey=error").Parse(metric.Query)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the metric query: %w", err)
+	}
+	interval := metric.Interval
+	if len(interval) == 0 {
+		interval = defaultMetricInterval
+	}
+	data := map[string]string{
+		"Interval":  interval,
+		"Name":      r.targetWorkload.GetName(),
+		"Namespace": r.targetWorkload.GetNamespace(),
+	}
+	buf := &bytes.Buffer{}
+	if err = tmpl.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("failed to render the metric query: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// isInMetricsRange checks if the value is in the range, a nil range or a nil bound accepts any value
+func isInMetricsRange(value float64, metricsRange *v1alpha1.MetricsExpectedRange) (bool, error) {
+	if metricsRange == nil {
+		return true, nil
+	}
+	if metricsRange.Min != nil {
+		min, err := parseMetricBound(metricsRange.Min)
+		if err != nil {
+			return false, err
+		}
+		if value < min {
+			return false, nil
+		}
+	}
+	if metricsRange.Max != nil {
+		max, err := parseMetricBound(metricsRange.Max)
+		if err != nil {
+			return false, err
+		}
+		if value > max {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// parseMetricBound parses the bound of the range, the string value can be a float like "0.99"
+func parseMetricBound(bound *intstr.IntOrString) (float64, error) {
+	if bound.Type == intstr.Int {
+		return float64(bound.IntVal), nil
+	}
+	return strconv.ParseFloat(strings.TrimSpace(bound.StrVal), 64)
+}
+
+// handleMetricViolation pauses the rollout at the current batch or rolls it back according to the failure policy,
+// the rolled back rollout reverts the upgraded pods to the source revision before it fails
+func (r *Controller) handleMetricViolation(violation *metricViolation) {
+	klog.InfoS("the canary metric is out of the expected range", "metric name", violation.metric.Name,
+		"value", violation.value, "failure policy", violation.metric.FailurePolicy)
+	if violation.metric.FailurePolicy == v1alpha1.RollbackOnMetricFailure {
+		r.recorder.Event(r.parentController, event.Warning("Canary metric failed", violation))
+		r.rolloutStatus.RolloutFailing(violation.Error())
+		r.rolloutStatus.SetConditions(v1alpha1.NewPositiveCondition(v1alpha1.RolloutRollingBack))
+		return
+	}
+	r.recorder.Event(r.parentController, event.Normal("Rollout paused", violation.Error()))
+	r.rolloutStatus.SetConditions(v1alpha1.NewNegativeCondition(v1alpha1.BatchPaused, violation.Error()))
+}
+
+func formatMetricsRange(metricsRange *v1alpha1.MetricsExpectedRange) string {
+	min, max := "-inf", "+inf"
+	if metricsRange != nil && metricsRange.Min != nil {
+		min = metricsRange.Min.String()
+	}
+	if metricsRange != nil && metricsRange.Max != nil {
+		max = metricsRange.Max.String()
+	}
+	return fmt.Sprintf("[%s, %s]", min, max)
+}
+
+// prometheusProvider queries the instant value through the prometheus http api
+type prometheusProvider struct {
+	address string
+	client  *http.Client
+}
+
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// Query returns the value of the scalar result or the first sample of the vector result
+func (p *prometheusProvider) Query(ctx context.Context, query string) (float64, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(p.address, "/") + "/api/v1/query")
+	if err != nil {
+		return 0, err
+	}
+	endpoint.RawQuery = url.Values{"query": []string{query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	result := &prometheusResponse{}
+	if err = json.Unmarshal(body, result); err != nil {
+		return 0, fmt.Errorf("failed to decode the prometheus response, http status = %d: %w", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return 0, fmt.Errorf("prometheus query failed, %s: %s", result.ErrorType, result.Error)
+	}
+
+	var sample []interface{}
+	switch result.Data.ResultType {
+	case "scalar":
+		if err = json.Unmarshal(result.Data.Result, &sample); err != nil {
+			return 0, err
+		}
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err = json.Unmarshal(result.Data.Result, &vector); err != nil {
+			return 0, err
+		}
+		if len(vector) == 0 {
+			return 0, fmt.Errorf("no value returned by the query")
+		}
+		sample = vector[0].Value
+	default:
+		return 0, fmt.Errorf("the prometheus result type `%s` is not supported", result.Data.ResultType)
+	}
+	// the sample is a pair of the timestamp and the value in string
+	if len(sample) != 2 {
+		return 0, fmt.Errorf("invalid prometheus sample %v", sample)
+	}
+	value, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid prometheus sample value %v", sample[1])
+	}
+	return strconv.ParseFloat(value, 64)
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// fakeWorkloadController only reports that the pods of the batch are ready
+type fakeWorkloadController struct{}
+
+func (c *fakeWorkloadController) VerifySpec(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (c *fakeWorkloadController) Initialize(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (c *fakeWorkloadController) RolloutOneBatchPods(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (c *fakeWorkloadController) CheckOneBatchPods(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (c *fakeWorkloadController) FinalizeOneBatch(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (c *fakeWorkloadController) Finalize(ctx context.Context, succeed bool) bool {
+	return true
+}
+
+func newFakePrometheus(values map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v1/query" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query := req.URL.Query().Get("query")
+		value, ok := values[query]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprintf(w, `{"status":"error","errorType":"bad_data","error":"unknown query %s"}`, query)
+			return
+		}
+		if value == "" {
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1633000000.1,"%s"]}]}}`, value)
+	}))
+}
+
+func TestCanaryMetricAnalysis(t *testing.T) {
+	srv := newFakePrometheus(map[string]string{
+		`sum(rate(requests{app="test",namespace="default",code!~"5.."}[2m]))/sum(rate(requests{app="test",namespace="default"}[2m]))`: "0.995",
+		`histogram_quantile(0.99, latency{app="test"}[1m])`:                                                                           "800",
+		`empty`: "",
+	})
+	defer srv.Close()
+
+	intOrStr := func(s string) *intstr.IntOrString {
+		v := intstr.Parse(s)
+		return &v
+	}
+	successRate := v1alpha1.CanaryMetric{
+		Name:         "success-rate",
+		Interval:     "2m",
+		Query:        `sum(rate(requests{app="{{ .Name }}",namespace="{{ .Namespace }}",code!~"5.."}[{{ .Interval }}]))/sum(rate(requests{app="{{ .Name }}",namespace="{{ .Namespace }}"}[{{ .Interval }}]))`,
+		Provider:     &v1alpha1.MetricProvider{Address: srv.URL},
+		MetricsRange: &v1alpha1.MetricsExpectedRange{Min: intOrStr("0.99")},
+	}
+	latency := v1alpha1.CanaryMetric{
+		Name:         "latency",
+		TemplateRef:  &corev1.ObjectReference{Kind: "ConfigMap", Name: "latency-template"},
+		MetricsRange: &v1alpha1.MetricsExpectedRange{Max: intOrStr("500")},
+	}
+	cli := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "latency-template", Namespace: "default"},
+		Data: map[string]string{
+			"query":    `histogram_quantile(0.99, latency{app="{{ .Name }}"}[{{ .Interval }}])`,
+			"provider": "prometheus",
+			"address":  srv.URL,
+		},
+	}).Build()
+
+	tests := map[string]struct {
+		rolloutMetrics        []v1alpha1.CanaryMetric
+		batchMetrics          []v1alpha1.CanaryMetric
+		wantRollingState      v1alpha1.RollingState
+		wantBatchRollingState v1alpha1.BatchRollingState
+		wantPaused            bool
+		wantRollingBack       bool
+	}{
+		"no metrics": {
+			wantRollingState:      v1alpha1.RollingInBatchesState,
+			wantBatchRollingState: v1alpha1.BatchFinalizingState,
+		},
+		"metric in range": {
+			rolloutMetrics:        []v1alpha1.CanaryMetric{successRate},
+			wantRollingState:      v1alpha1.RollingInBatchesState,
+			wantBatchRollingState: v1alpha1.BatchFinalizingState,
+		},
+		"metric from template out of range pauses the rollout": {
+			rolloutMetrics:        []v1alpha1.CanaryMetric{successRate},
+			batchMetrics:          []v1alpha1.CanaryMetric{latency},
+			wantRollingState:      v1alpha1.RollingInBatchesState,
+			wantBatchRollingState: v1alpha1.BatchVerifyingState,
+			wantPaused:            true,
+		},
+		"metric out of range rolls back the rollout": {
+			batchMetrics: []v1alpha1.CanaryMetric{func() v1alpha1.CanaryMetric {
+				m := latency
+				m.FailurePolicy = v1alpha1.RollbackOnMetricFailure
+				return m
+			}()},
+			wantRollingState:      v1alpha1.RolloutFailingState,
+			wantBatchRollingState: v1alpha1.BatchInitializingState,
+			wantRollingBack:       true,
+		},
+		"metric without value is retried": {
+			rolloutMetrics: []v1alpha1.CanaryMetric{{
+				Name:     "empty",
+				Query:    "empty",
+				Provider: &v1alpha1.MetricProvider{Address: srv.URL},
+			}},
+			wantRollingState:      v1alpha1.RollingInBatchesState,
+			wantBatchRollingState: v1alpha1.BatchVerifyingState,
+		},
+		"query failure is retried": {
+			rolloutMetrics: []v1alpha1.CanaryMetric{{
+				Name:     "unknown",
+				Query:    "unknown",
+				Provider: &v1alpha1.MetricProvider{Address: srv.URL},
+			}},
+			wantRollingState:      v1alpha1.RollingInBatchesState,
+			wantBatchRollingState: v1alpha1.BatchVerifyingState,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			target := &unstructured.Unstructured{}
+			target.SetName("test")
+			target.SetNamespace("default")
+			r := &Controller{
+				client:   cli,
+				recorder: event.NewNopRecorder(),
+				parentController: &v1beta1.AppRollout{
+					ObjectMeta: metav1.ObjectMeta{Name: "rollout", Namespace: "default"},
+				},
+				rolloutSpec: &v1alpha1.RolloutPlan{
+					CanaryMetric:   tt.rolloutMetrics,
+					RolloutBatches: []v1alpha1.RolloutBatch{{CanaryMetric: tt.batchMetrics}},
+				},
+				rolloutStatus: &v1alpha1.RolloutStatus{
+					RollingState:      v1alpha1.RollingInBatchesState,
+					BatchRollingState: v1alpha1.BatchVerifyingState,
+				},
+				targetWorkload: target,
+			}
+			r.reconcileBatchInRolling(context.Background(), &fakeWorkloadController{})
+			if r.rolloutStatus.RollingState != tt.wantRollingState {
+				t.Errorf("rolling state miss match: want `%s`, got `%s`", tt.wantRollingState, r.rolloutStatus.RollingState)
+			}
+			if r.rolloutStatus.BatchRollingState != tt.wantBatchRollingState {
+				t.Errorf("batch rolling state miss match: want `%s`, got `%s`", tt.wantBatchRollingState,
+					r.rolloutStatus.BatchRollingState)
+			}
+			paused := r.rolloutStatus.GetCondition(v1alpha1.BatchPaused).Status == corev1.ConditionFalse
+			if paused != tt.wantPaused {
+				t.Errorf("paused miss match: want %v, got %v", tt.wantPaused, paused)
+			}
+			rollingBack := r.rolloutStatus.GetCondition(v1alpha1.RolloutRollingBack).Status == corev1.ConditionTrue
+			if rollingBack != tt.wantRollingBack {
+				t.Errorf("rolling back miss match: want %v, got %v", tt.wantRollingBack, rollingBack)
+			}
+		})
+	}
+}
+
+// fakeRollbackController reverts the pods after the given number of calls
+type fakeRollbackController struct {
+	fakeWorkloadController
+	calls int
+}
+
+func (c *fakeRollbackController) Rollback(ctx context.Context) (bool, error) {
+	c.calls--
+	return c.calls <= 0, nil
+}
+
+func TestRollbackWorkload(t *testing.T) {
+	target := &unstructured.Unstructured{}
+	target.SetKind("Deployment")
+	newController := func() *Controller {
+		status := &v1alpha1.RolloutStatus{RollingState: v1alpha1.RolloutFailingState}
+		status.SetConditions(v1alpha1.NewPositiveCondition(v1alpha1.RolloutRollingBack))
+		return &Controller{
+			recorder:         event.NewNopRecorder(),
+			parentController: &v1beta1.AppRollout{},
+			rolloutStatus:    status,
+			targetWorkload:   target,
+		}
+	}
+
+	r := newController()
+	rollbacker := &fakeRollbackController{calls: 2}
+	if r.rollbackWorkload(context.Background(), rollbacker) {
+		t.Fatalf("the workload should not be finalized before the pods are reverted")
+	}
+	if !r.rollbackWorkload(context.Background(), rollbacker) {
+		t.Fatalf("the workload should be finalized after the pods are reverted")
+	}
+	if r.rolloutStatus.GetCondition(v1alpha1.RolloutRollingBack).Status != corev1.ConditionFalse {
+		t.Errorf("the rolling back condition should be false once reverted")
+	}
+	// the rollout is not rolling back anymore
+	if !r.rollbackWorkload(context.Background(), rollbacker) || rollbacker.calls != 0 {
+		t.Errorf("the workload should not be rolled back again")
+	}
+
+	r = newController()
+	if !r.rollbackWorkload(context.Background(), &fakeWorkloadController{}) {
+		t.Fatalf("the workload that can't be rolled back should be finalized")
+	}
+	if cond := r.rolloutStatus.GetCondition(v1alpha1.RolloutRollingBack); cond.Status != corev1.ConditionFalse ||
+		cond.Message != "rolling back the workload `Deployment` is not supported" {
+		t.Errorf("unexpected rolling back condition %+v", cond)
+	}
+}
+
+func TestIsInMetricsRange(t *testing.T) {
+	intOrStr := func(s string) *intstr.IntOrString {
+		v := intstr.Parse(s)
+		return &v
+	}
+	tests := map[string]struct {
+		value        float64
+		metricsRange *v1alpha1.MetricsExpectedRange
+		want         bool
+		wantErr      bool
+	}{
+		"no range": {
+			value: 100,
+			want:  true,
+		},
+		"in range": {
+			value:        0.5,
+			metricsRange: &v1alpha1.MetricsExpectedRange{Min: intOrStr("0"), Max: intOrStr("1")},
+			want:         true,
+		},
+		"below min": {
+			value:        0.98,
+			metricsRange: &v1alpha1.MetricsExpectedRange{Min: intOrStr("0.99")},
+		},
+		"above max": {
+			value:        2,
+			metricsRange: &v1alpha1.MetricsExpectedRange{Max: intOrStr("1")},
+		},
+		"invalid bound": {
+			value:        2,
+			metricsRange: &v1alpha1.MetricsExpectedRange{Max: intOrStr("high")},
+			wantErr:      true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := isInMetricsRange(tt.value, tt.metricsRange)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -24,7 +24,9 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	kruisev1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisev1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
@@ -134,6 +136,9 @@ func (r *Controller) Reconcile(ctx context.Context) (res reconcile.Result, statu
 		r.reconcileBatchInRolling(ctx, workloadController)
 
 	case v1alpha1.RolloutFailingState, v1alpha1.RolloutAbandoningState, v1alpha1.RolloutDeletingState:
+		if !r.rollbackWorkload(ctx, workloadController) {
+			return
+		}
 		if succeed := workloadController.Finalize(ctx, false); succeed {
 			r.finalizeRollout(ctx)
 		}
@@ -180,13 +185,13 @@ func (r *Controller) reconcileBatchInRolling(ctx context.Context, workloadContro
 	case v1alpha1.BatchVerifyingState:
 		// verifying if the application is ready to roll
 		// need to check if they meet the availability requirements in the rollout spec.
-		// TODO: evaluate any metrics/analysis
 		// TODO: We may need to go back to rollout again if the size of the resource can change behind our back
 		verified, err := workloadController.CheckOneBatchPods(ctx)
 		if err != nil {
 			r.rolloutStatus.RolloutFailing(err.Error())
 		} else if verified {
-			r.rolloutStatus.StateTransition(v1alpha1.OneBatchAvailableEvent)
+			// the pods are ready, evaluate the canary metrics before moving on
+			r.verifyCanaryMetrics(ctx)
 		}
 
 	case v1alpha1.BatchFinalizingState:
@@ -208,17 +213,58 @@ func (r *Controller) reconcileBatchInRolling(ctx context.Context, workloadContro
 	}
 }
 
+// evaluate the canary metrics of the batch, we stay in the verifying state until all of them are in range
+func (r *Controller) verifyCanaryMetrics(ctx context.Context) {
+	violation, err := r.checkCanaryMetrics(ctx)
+	if err != nil {
+		klog.ErrorS(err, "failed to check the canary metrics", "current batch", r.rolloutStatus.CurrentBatch)
+		r.rolloutStatus.RolloutRetry(err.Error())
+		return
+	}
+	if violation != nil {
+		r.handleMetricViolation(violation)
+		return
+	}
+	r.rolloutStatus.StateTransition(v1alpha1.OneBatchAvailableEvent)
+}
+
+// rollbackWorkload reverts the upgraded pods if the rollout is rolling back, it returns if the workload can be finalized
+func (r *Controller) rollbackWorkload(ctx context.Context, workloadController workloads.WorkloadController) bool {
+	if r.rolloutStatus.GetCondition(v1alpha1.RolloutRollingBack).Status != corev1.ConditionTrue {
+		return true
+	}
+	rollbacker, ok := workloadController.(workloads.WorkloadRollbacker)
+	if !ok {
+		err := fmt.Errorf("rolling back the workload `%s` is not supported", r.targetWorkload.GetKind())
+		r.recorder.Event(r.parentController, event.Warning("Rollback not supported", err))
+		r.rolloutStatus.SetConditions(v1alpha1.NewNegativeCondition(v1alpha1.RolloutRollingBack, err.Error()))
+		return true
+	}
+	done, err := rollbacker.Rollback(ctx)
+	if err != nil {
+		klog.ErrorS(err, "failed to roll back the workload", "target workload", klog.KObj(r.targetWorkload))
+		r.rolloutStatus.RolloutRetry(err.Error())
+		return false
+	}
+	if !done {
+		return false
+	}
+	r.rolloutStatus.SetConditions(v1alpha1.NewNegativeCondition(v1alpha1.RolloutRollingBack,
+		"the upgraded pods are reverted to the source revision"))
+	return true
+}
+
 // all the common initialize work before we rollout
 // TODO: fail the rollout if the webhook call is explicitly rejected (through http status code)
 func (r *Controller) initializeRollout(ctx context.Context) error {
 	// call the pre-rollout webhooks
 	for _, rw := range r.rolloutSpec.RolloutWebhooks {
 		if rw.Type == v1alpha1.InitializeRolloutHook {
-			err := callWebhook(ctx, r.parentController, string(v1alpha1.InitializingState), rw)
+			err := r.invokeWebhook(ctx, string(v1alpha1.InitializingState), rw)
 			if err != nil {
 				klog.ErrorS(err, "failed to invoke a webhook",
 					"webhook name", rw.Name, "webhook end point", rw.URL)
-				r.rolloutStatus.RolloutRetry("failed to invoke a webhook")
+				r.rolloutStatus.RolloutRetry(fmt.Sprintf("failed to invoke the webhook %s: %s", rw.Name, err))
 				return err
 			}
 			klog.InfoS("successfully invoked a pre rollout webhook", "webhook name", rw.Name, "webhook end point",
@@ -235,11 +281,11 @@ func (r *Controller) initializeOneBatch(ctx context.Context) {
 	// call all the pre-batch rollout webhooks
 	for _, rh := range rolloutHooks {
 		if rh.Type == v1alpha1.PreBatchRolloutHook {
-			err := callWebhook(ctx, r.parentController, string(v1alpha1.BatchInitializingState), rh)
+			err := r.invokeWebhook(ctx, string(v1alpha1.BatchInitializingState), rh)
 			if err != nil {
 				klog.ErrorS(err, "failed to invoke a webhook",
 					"webhook name", rh.Name, "webhook end point", rh.URL)
-				r.rolloutStatus.RolloutRetry("failed to invoke a webhook")
+				r.rolloutStatus.RolloutRetry(fmt.Sprintf("failed to invoke the webhook %s: %s", rh.Name, err))
 				return
 			}
 			klog.InfoS("successfully invoked a pre batch webhook", "webhook name", rh.Name, "webhook end point",
@@ -275,11 +321,11 @@ func (r *Controller) finalizeOneBatch(ctx context.Context) {
 	// call all the post-batch rollout webhooks
 	for _, rh := range rolloutHooks {
 		if rh.Type == v1alpha1.PostBatchRolloutHook {
-			err := callWebhook(ctx, r.parentController, string(v1alpha1.BatchFinalizingState), rh)
+			err := r.invokeWebhook(ctx, string(v1alpha1.BatchFinalizingState), rh)
 			if err != nil {
 				klog.ErrorS(err, "failed to invoke a webhook",
 					"webhook name", rh.Name, "webhook end point", rh.URL)
-				r.rolloutStatus.RolloutRetry("failed to invoke a webhook")
+				r.rolloutStatus.RolloutRetry(fmt.Sprintf("failed to invoke the webhook %s: %s", rh.Name, err))
 				return
 			}
 			klog.InfoS("successfully invoked a post batch webhook", "webhook name", rh.Name, "webhook end point",
@@ -308,11 +354,11 @@ func (r *Controller) finalizeRollout(ctx context.Context) {
 	// call the post-rollout webhooks
 	for _, rw := range r.rolloutSpec.RolloutWebhooks {
 		if rw.Type == v1alpha1.FinalizeRolloutHook {
-			err := callWebhook(ctx, r.parentController, string(r.rolloutStatus.RollingState), rw)
+			err := r.invokeWebhook(ctx, string(r.rolloutStatus.RollingState), rw)
 			if err != nil {
 				klog.ErrorS(err, "failed to invoke a webhook",
 					"webhook name", rw.Name, "webhook end point", rw.URL)
-				r.rolloutStatus.RolloutRetry("failed to invoke a post rollout webhook")
+				r.rolloutStatus.RolloutRetry(fmt.Sprintf("failed to invoke the post rollout webhook %s: %s", rw.Name, err))
 				return
 			}
 			klog.InfoS("successfully invoked a post rollout webhook", "webhook name", rw.Name, "webhook end point",
@@ -351,6 +397,18 @@ func (r *Controller) GetWorkloadController() (workloads.WorkloadController, erro
 			return workloads.NewCloneSetScaleController(r.client, r.recorder, r.parentController,
 				r.rolloutSpec, r.rolloutStatus, target), nil
 		}
+
+		// check if the target workload is Advanced StatefulSet
+		if r.targetWorkload.GetKind() == reflect.TypeOf(kruisev1beta1.StatefulSet{}).Name() {
+			// the pods of Advanced StatefulSet are upgraded in place, scaling is not supported yet
+			if r.sourceWorkload == nil {
+				return nil, fmt.Errorf("scaling the Advanced StatefulSet `%s` is not supported", target.Name)
+			}
+			klog.InfoS("using advanced statefulset rollout controller for this rolloutplan", "target workload name",
+				target.Name, "namespace", target.Namespace)
+			return workloads.NewAdvancedStatefulSetRolloutController(r.client, r.recorder, r.parentController,
+				r.rolloutSpec, r.rolloutStatus, target), nil
+		}
 	}
 
 	if r.targetWorkload.GroupVersionKind().Group == apps.GroupName {
@@ -380,6 +438,15 @@ func (r *Controller) GetWorkloadController() (workloads.WorkloadController, erro
 			return workloads.NewStatefulSetScaleController(r.client, r.recorder, r.parentController,
 				r.rolloutSpec, r.rolloutStatus, target), nil
 		}
+
+		// check if the target workload is DaemonSet
+		if r.targetWorkload.GetKind() == reflect.TypeOf(apps.DaemonSet{}).Name() {
+			// DaemonSet can't be scaled, the pods are always upgraded in place
+			klog.InfoS("using daemonset rollout controller for this rolloutplan", "target workload name", target.Name,
+				"namespace", target.Namespace)
+			return workloads.NewDaemonSetRolloutController(r.client, r.recorder, r.parentController,
+				r.rolloutSpec, r.rolloutStatus, target), nil
+		}
 	}
 
 	return nil, fmt.Errorf("the workload kind `%s` is not supported", kind)
@@ -19,20 +19,51 @@ package rollout
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
 )
 
+// WebhookSignatureHeader is the header of the HMAC-SHA256 signature of the webhook request body
+const WebhookSignatureHeader = "X-Vela-Signature"
+
+const (
+	defaultWebhookTimeout = 10 * time.Second
+	// the webhook response recorded in the rollout status is truncated to this size
+	maxWebhookResponseSize = 1024
+)
+
+// webhookOptions are the options of the webhook request, the zero value uses the defaults
+type webhookOptions struct {
+	signingKey  []byte
+	bearerToken string
+	timeout     time.Duration
+	retries     *int32
+}
+
+// webhookCredential is the credential of a webhook resolved from the secrets
+type webhookCredential struct {
+	signingKey  []byte
+	bearerToken string
+}
+
 // issue an http call to the an end ponit
-func makeHTTPRequest(ctx context.Context, webhookEndPoint, method string, payload interface{}) ([]byte, int, error) {
+func makeHTTPRequest(ctx context.Context, webhookEndPoint, method string, payload interface{},
+	opts webhookOptions) ([]byte, int, error) {
 	payloadBin, err := json.Marshal(payload)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
@@ -43,22 +74,37 @@ func makeHTTPRequest(ctx context.Context, webhookEndPoint, method string, payloa
 		return nil, http.StatusInternalServerError, err
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), method, hook.String(), bytes.NewBuffer(payloadBin))
-	if err != nil {
-		return nil, http.StatusInternalServerError, err
+	timeout := opts.timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	backoff := retry.DefaultBackoff
+	if opts.retries != nil && *opts.retries >= 0 {
+		backoff.Steps = int(*opts.retries) + 1
 	}
-	req.Header.Set("Content-Type", "application/json")
 
 	// issue request with retry
 	var r *http.Response
 	var body []byte
-	err = retry.OnError(retry.DefaultBackoff,
+	err = retry.OnError(backoff,
 		func(error) bool {
 			// not sure what not to retry on
 			return true
 		}, func() error {
-			var requestErr error
-			r, requestErr = http.DefaultClient.Do(req.WithContext(ctx))
+			// the request is built for every attempt as the body is consumed by the previous one
+			req, requestErr := http.NewRequestWithContext(ctx, method, hook.String(), bytes.NewBuffer(payloadBin))
+			if requestErr != nil {
+				return requestErr
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if len(opts.signingKey) != 0 {
+				req.Header.Set(WebhookSignatureHeader, "sha256="+signPayload(opts.signingKey, payloadBin))
+			}
+			if len(opts.bearerToken) != 0 {
+				req.Header.Set("Authorization", "Bearer "+opts.bearerToken)
+			}
+			r, requestErr = httpClient.Do(req)
 			defer func() {
 				if r != nil {
 					_ = r.Body.Close()
@@ -87,9 +133,18 @@ func makeHTTPRequest(ctx context.Context, webhookEndPoint, method string, payloa
 	return body, r.StatusCode, nil
 }
 
+// signPayload returns the hex encoded HMAC-SHA256 of the payload
+func signPayload(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 // callWebhook does a HTTP POST to an external service and
 // returns an error if the response status code is non-2xx
-func callWebhook(ctx context.Context, resource klog.KMetadata, phase string, rw v1alpha1.RolloutWebhook) error {
+// the status code and the body of the response are always returned so that they can be recorded
+func callWebhook(ctx context.Context, resource klog.KMetadata, phase string, rw v1alpha1.RolloutWebhook,
+	cred webhookCredential) (int, []byte, error) {
 	payload := v1alpha1.RolloutWebhookPayload{
 		Name:      resource.GetName(),
 		Namespace: resource.GetNamespace(),
@@ -103,16 +158,24 @@ func callWebhook(ctx context.Context, resource klog.KMetadata, phase string, rw
 	if len(rw.Method) == 0 {
 		rw.Method = http.MethodPost
 	}
-	_, status, err := makeHTTPRequest(ctx, rw.URL, rw.Method, payload)
+	opts := webhookOptions{
+		signingKey:  cred.signingKey,
+		bearerToken: cred.bearerToken,
+		retries:     rw.Retries,
+	}
+	if rw.TimeoutSeconds != nil {
+		opts.timeout = time.Duration(*rw.TimeoutSeconds) * time.Second
+	}
+	body, status, err := makeHTTPRequest(ctx, rw.URL, rw.Method, payload, opts)
 	if err != nil {
-		return err
+		return status, body, err
 	}
 	if len(rw.ExpectedStatus) == 0 {
 		if status > http.StatusAccepted {
 			err := fmt.Errorf("we fail the webhook request based on status, http status = %d", status)
-			return err
+			return status, body, err
 		}
-		return nil
+		return status, body, nil
 	}
 	// check if the returned status is expected
 	accepted := false
@@ -125,7 +188,88 @@ func callWebhook(ctx context.Context, resource klog.KMetadata, phase string, rw
 	if !accepted {
 		err := fmt.Errorf("http request to the webhook not accepeted, http status = %d", status)
 		klog.ErrorS(err, "The status is not expected", "expected status", rw.ExpectedStatus)
+		return status, body, err
+	}
+	return status, body, nil
+}
+
+// invokeWebhook calls the webhook with the credentials in its secrets and records the response in the rollout status
+func (r *Controller) invokeWebhook(ctx context.Context, phase string, rw v1alpha1.RolloutWebhook) error {
+	webhookStatus := v1alpha1.RolloutWebhookStatus{
+		Name:         rw.Name,
+		Phase:        phase,
+		LastCallTime: metav1.Now(),
+	}
+	defer func() {
+		r.setWebhookStatus(webhookStatus)
+	}()
+
+	cred, err := r.resolveWebhookCredential(ctx, rw)
+	if err != nil {
+		webhookStatus.Message = err.Error()
+		return err
+	}
+	status, body, err := callWebhook(ctx, r.parentController, phase, rw, cred)
+	webhookStatus.StatusCode = status
+	webhookStatus.Response = truncateWebhookResponse(body)
+	if err != nil {
+		webhookStatus.Message = err.Error()
+		if len(webhookStatus.Response) != 0 {
+			return fmt.Errorf("%w, response: %s", err, webhookStatus.Response)
+		}
 		return err
 	}
 	return nil
 }
+
+// resolveWebhookCredential reads the signing key and the bearer token of the webhook from the secrets
+// in the namespace of the rollout
+func (r *Controller) resolveWebhookCredential(ctx context.Context, rw v1alpha1.RolloutWebhook) (webhookCredential, error) {
+	var cred webhookCredential
+	if rw.SigningSecretRef != nil {
+		key, err := r.getSecretValue(ctx, rw.SigningSecretRef)
+		if err != nil {
+			return cred, fmt.Errorf("failed to get the signing secret of the webhook %s: %w", rw.Name, err)
+		}
+		cred.signingKey = key
+	}
+	if rw.BearerTokenRef != nil {
+		token, err := r.getSecretValue(ctx, rw.BearerTokenRef)
+		if err != nil {
+			return cred, fmt.Errorf("failed to get the bearer token of the webhook %s: %w", rw.Name, err)
+		}
+		cred.bearerToken = string(token)
+	}
+	return cred, nil
+}
+
+func (r *Controller) getSecretValue(ctx context.Context, ref *corev1.SecretKeySelector) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: r.parentController.GetNamespace(), Name: ref.Name},
+		secret); err != nil {
+		return nil, err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok || len(value) == 0 {
+		return nil, fmt.Errorf("the key %s is not found in the secret %s", ref.Key, ref.Name)
+	}
+	return value, nil
+}
+
+// setWebhookStatus replaces the status of the webhook with the same name or appends it
+func (r *Controller) setWebhookStatus(webhookStatus v1alpha1.RolloutWebhookStatus) {
+	for i, ws := range r.rolloutStatus.WebhookStatus {
+		if ws.Name == webhookStatus.Name {
+			r.rolloutStatus.WebhookStatus[i] = webhookStatus
+			return
+		}
+	}
+	r.rolloutStatus.WebhookStatus = append(r.rolloutStatus.WebhookStatus, webhookStatus)
+}
+
+func truncateWebhookResponse(body []byte) string {
+	if len(body) > maxWebhookResponseSize {
+		return string(body[:maxWebhookResponseSize]) + "..."
+	}
+	return string(body)
+}
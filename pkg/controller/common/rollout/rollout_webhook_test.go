@@ -18,7 +18,9 @@ package rollout
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
 	"net"
 	"net/http"
@@ -26,10 +28,14 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	appsv1 "k8s.io/api/apps/v1"
-
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/pkg/oam"
 )
@@ -122,7 +128,7 @@ func TestMakeHTTPRequest(t *testing.T) {
 			if len(tt.url) == 0 {
 				tt.url = mockUrl
 			}
-			gotReply, gotCode, gotErr := makeHTTPRequest(ctx, "http://"+tt.url, tt.method, tt.payload)
+			gotReply, gotCode, gotErr := makeHTTPRequest(ctx, "http://"+tt.url, tt.method, tt.payload, webhookOptions{})
 			if gotCode != tt.want.statusCode {
 				t.Errorf("\n%s\nr.Reconcile(...): want code `%d`, got code:`%d` got err: %v \n", testName, tt.want.statusCode,
 					gotCode, gotErr)
@@ -215,7 +221,7 @@ func TestCallWebhook(t *testing.T) {
 			testServer := NewMock(http.MethodPost, url, tt.returnedStatusCode, body)
 			defer testServer.Close()
 
-			gotErr := callWebhook(ctx, tt.args.resource, tt.args.phase, tt.args.rw)
+			_, _, gotErr := callWebhook(ctx, tt.args.resource, tt.args.phase, tt.args.rw, webhookCredential{})
 			if (tt.wantErr == nil && gotErr != nil) || (tt.wantErr != nil && gotErr == nil) {
 				t.Errorf("\n%s\nr.Reconcile(...): want error `%s`, got error:`%s`\n", name, tt.wantErr, gotErr)
 			}
@@ -226,6 +232,119 @@ func TestCallWebhook(t *testing.T) {
 	}
 }
 
+func TestInvokeWebhook(t *testing.T) {
+	ctx := context.TODO()
+	var calls int
+	var gotSignature, gotAuthorization string
+	var gotPayload v1alpha1.RolloutWebhookPayload
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		gotSignature = req.Header.Get(WebhookSignatureHeader)
+		gotAuthorization = req.Header.Get("Authorization")
+		body, _ := io.ReadAll(req.Body)
+		if gotSignature != "sha256="+signPayload([]byte("signing-key"), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("invalid signature"))
+			return
+		}
+		_ = json.Unmarshal(body, &gotPayload)
+		if gotPayload.Phase == string(v1alpha1.BatchInitializingState) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"reason":"error budget exhausted"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("approved"))
+	}))
+	defer testServer.Close()
+
+	cli := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-secret", Namespace: "default"},
+		Data: map[string][]byte{
+			"signing-key": []byte("signing-key"),
+			"token":       []byte("my-token"),
+		},
+	}).Build()
+	r := &Controller{
+		client:   cli,
+		recorder: event.NewNopRecorder(),
+		parentController: &v1beta1.AppRollout{
+			ObjectMeta: metav1.ObjectMeta{Name: "rollout", Namespace: "default"},
+		},
+		rolloutStatus: &v1alpha1.RolloutStatus{},
+	}
+	rw := v1alpha1.RolloutWebhook{
+		Name: "gate",
+		URL:  testServer.URL,
+		SigningSecretRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "webhook-secret"},
+			Key:                  "signing-key",
+		},
+		BearerTokenRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "webhook-secret"},
+			Key:                  "token",
+		},
+		TimeoutSeconds: pointer.Int32Ptr(5),
+		Retries:        pointer.Int32Ptr(0),
+	}
+
+	if err := r.invokeWebhook(ctx, string(v1alpha1.InitializingState), rw); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if gotAuthorization != "Bearer my-token" {
+		t.Errorf("want bearer token `my-token`, got authorization header `%s`", gotAuthorization)
+	}
+	if gotPayload.Name != "rollout" || gotPayload.Phase != string(v1alpha1.InitializingState) {
+		t.Errorf("unexpected payload %+v", gotPayload)
+	}
+	if len(r.rolloutStatus.WebhookStatus) != 1 || r.rolloutStatus.WebhookStatus[0].Response != "approved" ||
+		r.rolloutStatus.WebhookStatus[0].StatusCode != http.StatusOK {
+		t.Errorf("unexpected webhook status %+v", r.rolloutStatus.WebhookStatus)
+	}
+
+	// the rejected response is recorded in the status of the same webhook
+	err := r.invokeWebhook(ctx, string(v1alpha1.BatchInitializingState), rw)
+	wantErr := fmt.Sprintf("we fail the webhook request based on status, http status = %d, response: %s",
+		http.StatusForbidden, `{"reason":"error budget exhausted"}`)
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("want error `%s`, got `%v`", wantErr, err)
+	}
+	if len(r.rolloutStatus.WebhookStatus) != 1 {
+		t.Fatalf("want 1 webhook status, got %+v", r.rolloutStatus.WebhookStatus)
+	}
+	ws := r.rolloutStatus.WebhookStatus[0]
+	if ws.StatusCode != http.StatusForbidden || ws.Response != `{"reason":"error budget exhausted"}` ||
+		ws.Phase != string(v1alpha1.BatchInitializingState) || len(ws.Message) == 0 {
+		t.Errorf("unexpected webhook status %+v", ws)
+	}
+
+	// the retries of the server error are limited
+	calls = 0
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	rw.URL = failing.URL
+	rw.Retries = pointer.Int32Ptr(1)
+	if err = r.invokeWebhook(ctx, string(v1alpha1.InitializingState), rw); err == nil {
+		t.Errorf("want error, got nil")
+	}
+	if calls != 2 {
+		t.Errorf("want 2 calls, got %d", calls)
+	}
+
+	// missing secret fails the webhook without calling it
+	calls = 0
+	rw.BearerTokenRef.Name = "not-exist"
+	if err = r.invokeWebhook(ctx, string(v1alpha1.InitializingState), rw); err == nil {
+		t.Errorf("want error, got nil")
+	}
+	if calls != 0 {
+		t.Errorf("want no call, got %d", calls)
+	}
+}
+
 func NewMock(method, mockUrl string, statusCode int, body string) *httptest.Server {
 	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		if req.Method == method {
@@ -0,0 +1,363 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	kruisev1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// AdvancedStatefulSetRolloutController is responsible for handle rollout Kruise Advanced StatefulSet type of workloads
+// The pods are upgraded in place batch by batch by lowering the partition of the rolling update strategy, the
+// Advanced StatefulSet is rendered paused for the rollout and resumed once it is under our control.
+type AdvancedStatefulSetRolloutController struct {
+	workloadController
+	targetNamespacedName types.NamespacedName
+	statefulSet          *kruisev1beta1.StatefulSet
+}
+
+// NewAdvancedStatefulSetRolloutController creates Advanced StatefulSet rollout controller
+func NewAdvancedStatefulSetRolloutController(client client.Client, recorder event.Recorder, parentController oam.Object,
+	rolloutSpec *v1alpha1.RolloutPlan, rolloutStatus *v1alpha1.RolloutStatus,
+	targetNamespacedName types.NamespacedName) *AdvancedStatefulSetRolloutController {
+	return &AdvancedStatefulSetRolloutController{
+		workloadController: workloadController{
+			client:           client,
+			recorder:         recorder,
+			parentController: parentController,
+			rolloutSpec:      rolloutSpec,
+			rolloutStatus:    rolloutStatus,
+		},
+		targetNamespacedName: targetNamespacedName,
+	}
+}
+
+// VerifySpec verifies that the rollout resource is consistent with the rollout spec
+func (c *AdvancedStatefulSetRolloutController) VerifySpec(ctx context.Context) (bool, error) {
+	var verifyErr error
+
+	defer func() {
+		if verifyErr != nil {
+			klog.Error(verifyErr)
+			c.recorder.Event(c.parentController, event.Warning("VerifyFailed", verifyErr))
+		}
+	}()
+
+	currentReplicas, verifyErr := c.size(ctx)
+	if verifyErr != nil {
+		c.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint: nilerr
+		return false, nil
+	}
+	// record the size and we will use this value to drive the rest of the batches
+	klog.InfoS("record the target size", "total replicas", currentReplicas)
+	c.rolloutStatus.RolloutTargetSize = currentReplicas
+	c.rolloutStatus.RolloutOriginalSize = currentReplicas
+
+	// make sure that the updateRevision is different from what we have already done
+	targetHash, verifyErr := utils.ComputeSpecHash(c.statefulSet.Spec.Template)
+	if verifyErr != nil {
+		// do not fail the rollout because we can't compute the hash value for some reason
+		c.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	if targetHash == c.rolloutStatus.LastAppliedPodTemplateIdentifier {
+		return false, fmt.Errorf("there is no difference between the source and target, hash = %s", targetHash)
+	}
+
+	if currentReplicas != c.statefulSet.Status.Replicas {
+		verifyErr = fmt.Errorf("the Advanced StatefulSet is still scaling, target = %d, statefulSet size = %d",
+			currentReplicas, c.statefulSet.Status.Replicas)
+		c.rolloutStatus.RolloutRetry(verifyErr.Error())
+		return false, verifyErr
+	}
+
+	// check if the rollout batch replicas added up to the Advanced StatefulSet replicas
+	if verifyErr = c.verifyRolloutBatchReplicaValue(currentReplicas); verifyErr != nil {
+		return false, verifyErr
+	}
+
+	// check if the Advanced StatefulSet has any controller
+	if controller := metav1.GetControllerOf(c.statefulSet); controller != nil {
+		return false, fmt.Errorf("the Advanced StatefulSet %s has a controller owner %s",
+			c.statefulSet.GetName(), controller.String())
+	}
+
+	// mark the rollout verified
+	c.recorder.Event(c.parentController, event.Normal("Rollout Verified",
+		"Rollout spec and the Advanced StatefulSet resource are verified"))
+	// record the new pod template on success
+	c.rolloutStatus.NewPodTemplateIdentifier = targetHash
+	return true, nil
+}
+
+// Initialize makes sure that the Advanced StatefulSet is under our control, no pod is upgraded until the first batch
+func (c *AdvancedStatefulSetRolloutController) Initialize(ctx context.Context) (bool, error) {
+	if err := c.fetchStatefulSet(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	statefulSetPatch := client.MergeFrom(c.statefulSet.DeepCopy())
+	if controller := metav1.GetControllerOf(c.statefulSet); controller == nil || !isRolloutController(controller) {
+		// add the parent controller to the owner of the Advanced StatefulSet
+		ref := metav1.NewControllerRef(c.parentController, c.parentController.GetObjectKind().GroupVersionKind())
+		c.statefulSet.SetOwnerReferences(append(c.statefulSet.GetOwnerReferences(), *ref))
+	}
+	// keep all the pods in the old version and resume the Advanced StatefulSet paused at rendering
+	rollingUpdate := c.rollingUpdate()
+	rollingUpdate.Partition = pointer.Int32Ptr(getAdvancedStatefulSetReplicas(c.statefulSet))
+	rollingUpdate.Paused = false
+
+	if err := c.client.Patch(ctx, c.statefulSet, statefulSetPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning("Failed to the start the Advanced StatefulSet update", err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	// mark the rollout initialized
+	c.recorder.Event(c.parentController, event.Normal("Rollout Initialized", "Rollout resource are initialized"))
+	return true, nil
+}
+
+// RolloutOneBatchPods calculates the number of pods we can upgrade once according to the rollout spec
+// and then set the partition accordingly
+func (c *AdvancedStatefulSetRolloutController) RolloutOneBatchPods(ctx context.Context) (bool, error) {
+	if err := c.fetchStatefulSet(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	currentReplicas := getAdvancedStatefulSetReplicas(c.statefulSet)
+	newPodTarget := c.calculateCurrentTarget(currentReplicas)
+	if err := c.setPartition(ctx, currentReplicas-newPodTarget); err != nil {
+		// nolint:nilerr
+		return false, nil
+	}
+
+	// record the finished upgrade action
+	klog.InfoS("upgraded one batch", "current batch", c.rolloutStatus.CurrentBatch,
+		"target size", newPodTarget)
+	c.recorder.Event(c.parentController, event.Normal("Batch Rollout",
+		fmt.Sprintf("Finished submiting all upgrade quests for batch %d", c.rolloutStatus.CurrentBatch)))
+	c.rolloutStatus.UpgradedReplicas = newPodTarget
+	return true, nil
+}
+
+// CheckOneBatchPods checks to see if the pods are all available according to the rollout plan
+func (c *AdvancedStatefulSetRolloutController) CheckOneBatchPods(ctx context.Context) (bool, error) {
+	if err := c.fetchStatefulSet(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	if len(c.rolloutSpec.RolloutBatches) <= int(c.rolloutStatus.CurrentBatch) {
+		err := errors.New("somehow, currentBatch number exceeded the rolloutBatches spec")
+		klog.ErrorS(err, "total batch", len(c.rolloutSpec.RolloutBatches), "current batch",
+			c.rolloutStatus.CurrentBatch)
+		return false, err
+	}
+
+	currentReplicas := getAdvancedStatefulSetReplicas(c.statefulSet)
+	newPodTarget := c.calculateCurrentTarget(currentReplicas)
+	readyPodCount := c.updatedReadyReplicas()
+
+	currentBatch := c.rolloutSpec.RolloutBatches[c.rolloutStatus.CurrentBatch]
+	maxUnavail := 0
+	if currentBatch.MaxUnavailable != nil {
+		maxUnavail, _ = intstr.GetValueFromIntOrPercent(currentBatch.MaxUnavailable, int(currentReplicas), true)
+	}
+	klog.InfoS("checking the rolling out progress", "current batch", c.rolloutStatus.CurrentBatch,
+		"new pod count target", newPodTarget, "new ready pod count", readyPodCount,
+		"max unavailable pod allowed", maxUnavail)
+	c.rolloutStatus.UpgradedReadyReplicas = int32(readyPodCount)
+
+	if maxUnavail+readyPodCount >= int(newPodTarget) {
+		// record the successful upgrade
+		klog.InfoS("all pods in current batch are ready", "current batch", c.rolloutStatus.CurrentBatch)
+		c.recorder.Event(c.parentController, event.Normal("Batch Available",
+			fmt.Sprintf("Batch %d is available", c.rolloutStatus.CurrentBatch)))
+		return true, nil
+	}
+
+	// continue to verify
+	klog.InfoS("the batch is not ready yet", "current batch", c.rolloutStatus.CurrentBatch)
+	c.rolloutStatus.RolloutRetry("the batch is not ready yet")
+	return false, nil
+}
+
+// FinalizeOneBatch makes sure that the rollout status are updated correctly
+func (c *AdvancedStatefulSetRolloutController) FinalizeOneBatch(ctx context.Context) (bool, error) {
+	status := c.rolloutStatus
+	spec := c.rolloutSpec
+
+	if spec.BatchPartition != nil && *spec.BatchPartition < status.CurrentBatch {
+		err := fmt.Errorf("the current batch value in the status is greater than the batch partition")
+		klog.ErrorS(err, "we have moved past the user defined partition", "user specified batch partition",
+			*spec.BatchPartition, "current batch we are working on", status.CurrentBatch)
+		return false, err
+	}
+
+	upgradedReplicas := int(status.UpgradedReplicas)
+	currentBatch := int(status.CurrentBatch)
+	// calculate the lower bound of the possible pod count just before the current batch
+	podCount := calculateNewBatchTarget(c.rolloutSpec, 0, int(status.RolloutTargetSize), currentBatch-1)
+	// the recorded number should be at least as much as the all the pods before the current batch
+	if podCount > upgradedReplicas {
+		err := fmt.Errorf("the upgraded replica in the status is less than all the pods in the previous batch")
+		klog.ErrorS(err, "rollout status inconsistent", "upgraded num status", upgradedReplicas,
+			"pods in all the previous batches", podCount)
+		return false, err
+	}
+
+	// calculate the upper bound with the current batch
+	podCount = calculateNewBatchTarget(c.rolloutSpec, 0, int(status.RolloutTargetSize), currentBatch)
+	// the recorded number should be not as much as the all the pods including the active batch
+	if podCount < upgradedReplicas {
+		err := fmt.Errorf("the upgraded replica in the status is greater than all the pods in the current batch")
+		klog.ErrorS(err, "rollout status inconsistent", "total target size", status.RolloutTargetSize,
+			"upgraded num status", upgradedReplicas, "pods in the batches including the current batch", podCount)
+		return false, err
+	}
+	return true, nil
+}
+
+// Finalize releases the Advanced StatefulSet, the partition is left as is so that a failed rollout won't
+// upgrade the rest of the pods
+func (c *AdvancedStatefulSetRolloutController) Finalize(ctx context.Context, succeed bool) bool {
+	if err := c.fetchStatefulSet(ctx); err != nil {
+		// don't fail the rollout just because of we can't get the resource
+		return false
+	}
+
+	statefulSetPatch := client.MergeFrom(c.statefulSet.DeepCopy())
+	var newOwnerList []metav1.OwnerReference
+	owners := c.statefulSet.GetOwnerReferences()
+	for i := range owners {
+		if isRolloutController(&owners[i]) {
+			continue
+		}
+		newOwnerList = append(newOwnerList, owners[i])
+	}
+	c.statefulSet.SetOwnerReferences(newOwnerList)
+
+	if err := c.client.Patch(ctx, c.statefulSet, statefulSetPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning("Failed to the release the Advanced StatefulSet", err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return false
+	}
+
+	// mark the resource finalized
+	c.rolloutStatus.LastAppliedPodTemplateIdentifier = c.rolloutStatus.NewPodTemplateIdentifier
+	c.recorder.Event(c.parentController, event.Normal("Rollout Finalized",
+		fmt.Sprintf("Rollout resource are finalized, succeed := %t", succeed)))
+	return true
+}
+
+// check if the replicas in all the rollout batches add up to the right number
+func (c *AdvancedStatefulSetRolloutController) verifyRolloutBatchReplicaValue(totalReplicas int32) error {
+	return verifyBatchesWithRollout(c.rolloutSpec, totalReplicas)
+}
+
+// the number of the upgraded pods after the current batch
+func (c *AdvancedStatefulSetRolloutController) calculateCurrentTarget(totalSize int32) int32 {
+	targetSize := int32(calculateNewBatchTarget(c.rolloutSpec, 0, int(totalSize), int(c.rolloutStatus.CurrentBatch)))
+	klog.InfoS("Calculated the number of upgraded pods in the Advanced StatefulSet after current batch",
+		"current batch", c.rolloutStatus.CurrentBatch, "target size", targetSize)
+	return targetSize
+}
+
+// updatedReadyReplicas returns the least number of the upgraded pods that are ready, the status only has the number
+// of the upgraded pods and the number of the ready pods, so the pods that are not ready are taken as upgraded ones
+func (c *AdvancedStatefulSetRolloutController) updatedReadyReplicas() int {
+	status := c.statefulSet.Status
+	if status.ObservedGeneration < c.statefulSet.Generation {
+		// the status is not updated with the latest partition yet
+		return 0
+	}
+	ready := int(status.UpdatedReplicas - (status.Replicas - status.ReadyReplicas))
+	if ready < 0 {
+		return 0
+	}
+	return ready
+}
+
+// rollingUpdate returns the rolling update strategy of the Advanced StatefulSet to modify, it's created if not set
+func (c *AdvancedStatefulSetRolloutController) rollingUpdate() *kruisev1beta1.RollingUpdateStatefulSetStrategy {
+	if c.statefulSet.Spec.UpdateStrategy.RollingUpdate == nil {
+		c.statefulSet.Spec.UpdateStrategy.RollingUpdate = &kruisev1beta1.RollingUpdateStatefulSetStrategy{}
+	}
+	return c.statefulSet.Spec.UpdateStrategy.RollingUpdate
+}
+
+func (c *AdvancedStatefulSetRolloutController) setPartition(ctx context.Context, partition int32) error {
+	statefulSetPatch := client.MergeFrom(c.statefulSet.DeepCopy())
+	c.rollingUpdate().Partition = pointer.Int32Ptr(partition)
+
+	if err := c.client.Patch(ctx, c.statefulSet, statefulSetPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning(event.Reason(fmt.Sprintf(
+			"Failed to update the partition of Advanced StatefulSet %s to the correct target %d", c.statefulSet.GetName(), partition)), err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return err
+	}
+
+	klog.InfoS("Submitted upgrade quest for Advanced StatefulSet", "StatefulSet",
+		c.statefulSet.GetName(), "target partition", partition, "batch", c.rolloutStatus.CurrentBatch)
+	return nil
+}
+
+func (c *AdvancedStatefulSetRolloutController) fetchStatefulSet(ctx context.Context) error {
+	workload := kruisev1beta1.StatefulSet{}
+	if err := c.client.Get(ctx, c.targetNamespacedName, &workload); err != nil {
+		if !apierrors.IsNotFound(err) {
+			c.recorder.Event(c.parentController, event.Warning("Failed to get the Advanced StatefulSet", err))
+		}
+		return err
+	}
+	c.statefulSet = &workload
+	return nil
+}
+
+func (c *AdvancedStatefulSetRolloutController) size(ctx context.Context) (int32, error) {
+	if c.statefulSet == nil {
+		if err := c.fetchStatefulSet(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return getAdvancedStatefulSetReplicas(c.statefulSet), nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	kruisev1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+func TestAdvancedStatefulSetRollout(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	r.NoError(kruisev1beta1.AddToScheme(scheme))
+	labels := map[string]string{"app": "db"}
+	sts := &kruisev1beta1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec: kruisev1beta1.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(4),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "db", Image: "db:v2"}}},
+			},
+			// the Advanced StatefulSet is rendered paused for the rollout
+			UpdateStrategy: kruisev1beta1.StatefulSetUpdateStrategy{
+				RollingUpdate: &kruisev1beta1.RollingUpdateStatefulSetStrategy{Paused: true},
+			},
+		},
+		Status: kruisev1beta1.StatefulSetStatus{Replicas: 4, ReadyReplicas: 4},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sts).Build()
+	key := types.NamespacedName{Namespace: "default", Name: "db"}
+
+	rollout := &v1beta1.AppRollout{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.AppRolloutKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "rollout", Namespace: "default", UID: "rollout-uid"},
+	}
+	rolloutSpec := &v1alpha1.RolloutPlan{
+		RolloutBatches: []v1alpha1.RolloutBatch{
+			{Replicas: intstr.FromInt(1)},
+			{Replicas: intstr.FromInt(3)},
+		},
+	}
+	rolloutStatus := &v1alpha1.RolloutStatus{}
+	c := NewAdvancedStatefulSetRolloutController(cli, event.NewNopRecorder(), rollout, rolloutSpec, rolloutStatus, key)
+
+	// setStatus acts as the Advanced StatefulSet controller to update the status
+	setStatus := func(updated, ready int32) {
+		got := &kruisev1beta1.StatefulSet{}
+		r.NoError(cli.Get(ctx, key, got))
+		got.Status.ObservedGeneration = got.Generation
+		got.Status.UpdatedReplicas = updated
+		got.Status.ReadyReplicas = ready
+		r.NoError(cli.Status().Update(ctx, got))
+	}
+
+	verified, err := c.VerifySpec(ctx)
+	r.NoError(err)
+	r.True(verified)
+	r.Equal(int32(4), rolloutStatus.RolloutTargetSize)
+
+	initialized, err := c.Initialize(ctx)
+	r.NoError(err)
+	r.True(initialized)
+	got := &kruisev1beta1.StatefulSet{}
+	r.NoError(cli.Get(ctx, key, got))
+	r.Equal("rollout", metav1.GetControllerOf(got).Name)
+	r.False(got.Spec.UpdateStrategy.RollingUpdate.Paused)
+	r.Equal(int32(4), *got.Spec.UpdateStrategy.RollingUpdate.Partition)
+
+	wantPartitions := []int32{3, 0}
+	wantUpgraded := []int32{1, 4}
+	for batch := range wantPartitions {
+		rolloutStatus.CurrentBatch = int32(batch)
+		done, err := c.RolloutOneBatchPods(ctx)
+		r.NoError(err)
+		r.True(done)
+		r.NoError(cli.Get(ctx, key, got))
+		r.Equal(wantPartitions[batch], *got.Spec.UpdateStrategy.RollingUpdate.Partition, "batch %d", batch)
+
+		// the upgraded pods are not ready yet
+		setStatus(wantUpgraded[batch], 4-wantUpgraded[batch])
+		ready, err := c.CheckOneBatchPods(ctx)
+		r.NoError(err)
+		r.False(ready, "batch %d", batch)
+
+		setStatus(wantUpgraded[batch], 4)
+		ready, err = c.CheckOneBatchPods(ctx)
+		r.NoError(err)
+		r.True(ready, "batch %d", batch)
+		finalized, err := c.FinalizeOneBatch(ctx)
+		r.NoError(err)
+		r.True(finalized, "batch %d", batch)
+	}
+
+	r.True(c.Finalize(ctx, true))
+	got = &kruisev1beta1.StatefulSet{}
+	r.NoError(cli.Get(ctx, key, got))
+	r.Nil(metav1.GetControllerOf(got))
+	r.Equal(rolloutStatus.NewPodTemplateIdentifier, rolloutStatus.LastAppliedPodTemplateIdentifier)
+
+	// nothing to rollout again
+	_, err = c.VerifySpec(ctx)
+	r.Error(err)
+}
@@ -262,6 +262,42 @@ func (c *CloneSetRolloutController) FinalizeOneBatch(ctx context.Context) (bool,
 	return true, nil
 }
 
+// Rollback sets the partition back to the size of the CloneSet, the CloneSet reverts the upgraded pods to
+// the current revision once the partition increases
+func (c *CloneSetRolloutController) Rollback(ctx context.Context) (bool, error) {
+	if err := c.fetchCloneSet(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+	cloneSetSize, err := c.size(ctx)
+	if err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+	partition := c.cloneSet.Spec.UpdateStrategy.Partition
+	if partition == nil || partition.Type != intstr.Int || partition.IntVal != cloneSetSize {
+		clonePatch := client.MergeFrom(c.cloneSet.DeepCopy())
+		c.cloneSet.Spec.UpdateStrategy.Partition = &intstr.IntOrString{Type: intstr.Int, IntVal: cloneSetSize}
+		if err := c.client.Patch(ctx, c.cloneSet, clonePatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+			c.recorder.Event(c.parentController, event.Warning("Failed to update the cloneset to roll back", err))
+			c.rolloutStatus.RolloutRetry(err.Error())
+			return false, nil
+		}
+		klog.InfoS("set the cloneset partition to roll back", "cloneSet", c.cloneSet.Name, "partition", cloneSetSize)
+		return false, nil
+	}
+	if c.cloneSet.Status.ObservedGeneration != c.cloneSet.Generation || c.cloneSet.Status.UpdatedReplicas != 0 {
+		c.rolloutStatus.RolloutRetry(fmt.Sprintf("the rollback is not finished yet with %d pods upgraded",
+			c.cloneSet.Status.UpdatedReplicas))
+		return false, nil
+	}
+	c.rolloutStatus.UpgradedReplicas = 0
+	c.rolloutStatus.UpgradedReadyReplicas = 0
+	c.recorder.Event(c.parentController, event.Normal("Rollout Rolled Back",
+		fmt.Sprintf("All the pods of the cloneset %s are reverted", c.cloneSet.Name)))
+	return true, nil
+}
+
 // Finalize makes sure the Cloneset is all upgraded
 func (c *CloneSetRolloutController) Finalize(ctx context.Context, succeed bool) bool {
 	if err := c.fetchCloneSet(ctx); err != nil {
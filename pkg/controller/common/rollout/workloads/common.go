@@ -19,6 +19,7 @@ package workloads
 import (
 	"fmt"
 
+	kruisev1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
@@ -150,3 +151,11 @@ func getStatefulSetReplicas(statefulSet *apps.StatefulSet) int32 {
 	}
 	return 1
 }
+
+func getAdvancedStatefulSetReplicas(statefulSet *kruisev1beta1.StatefulSet) int32 {
+	// replicas default is 1
+	if statefulSet.Spec.Replicas != nil {
+		return *statefulSet.Spec.Replicas
+	}
+	return 1
+}
@@ -60,6 +60,14 @@ type WorkloadController interface {
 	Finalize(ctx context.Context, succeed bool) bool
 }
 
+// WorkloadRollbacker is implemented by the workload controllers that can revert the upgraded pods
+// to the source revision when the rollout is rolled back
+type WorkloadRollbacker interface {
+	// Rollback reverts the upgraded pods to the source revision
+	// it returns if all the pods are reverted or should retry
+	Rollback(ctx context.Context) (bool, error)
+}
+
 type workloadController struct {
 	client           client.Client
 	recorder         event.Recorder
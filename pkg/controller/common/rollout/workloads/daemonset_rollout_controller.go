@@ -0,0 +1,450 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// daemonSetTemplateGenerationLabel is the label the DaemonSet controller puts on the pods, its value is the
+// template generation of the DaemonSet that the pod is created from
+const daemonSetTemplateGenerationLabel = "pod-template-generation"
+
+// DaemonSetRolloutController is responsible for handle rollout DaemonSet type of workloads
+// DaemonSet has no partition, so the DaemonSet is switched to the OnDelete update strategy during the rollout
+// and we delete the old pods batch by batch, the DaemonSet controller then recreates them with the new template.
+// The replicas of the rollout batches is the number of the nodes that the DaemonSet runs on, and the pod list of
+// the batch can contain the names of the pods or the nodes to upgrade.
+type DaemonSetRolloutController struct {
+	workloadController
+	targetNamespacedName types.NamespacedName
+	daemonSet            *appsv1.DaemonSet
+}
+
+// NewDaemonSetRolloutController creates DaemonSet rollout controller
+func NewDaemonSetRolloutController(client client.Client, recorder event.Recorder, parentController oam.Object,
+	rolloutSpec *v1alpha1.RolloutPlan, rolloutStatus *v1alpha1.RolloutStatus,
+	targetNamespacedName types.NamespacedName) *DaemonSetRolloutController {
+	return &DaemonSetRolloutController{
+		workloadController: workloadController{
+			client:           client,
+			recorder:         recorder,
+			parentController: parentController,
+			rolloutSpec:      rolloutSpec,
+			rolloutStatus:    rolloutStatus,
+		},
+		targetNamespacedName: targetNamespacedName,
+	}
+}
+
+// VerifySpec verifies that the rollout resource is consistent with the rollout spec
+func (c *DaemonSetRolloutController) VerifySpec(ctx context.Context) (bool, error) {
+	var verifyErr error
+
+	defer func() {
+		if verifyErr != nil {
+			klog.Error(verifyErr)
+			c.recorder.Event(c.parentController, event.Warning("VerifyFailed", verifyErr))
+		}
+	}()
+
+	currentSize, verifyErr := c.size(ctx)
+	if verifyErr != nil {
+		c.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint: nilerr
+		return false, nil
+	}
+	// record the size and we will use this value to drive the rest of the batches
+	klog.InfoS("record the target size", "total nodes", currentSize)
+	c.rolloutStatus.RolloutTargetSize = currentSize
+	c.rolloutStatus.RolloutOriginalSize = currentSize
+
+	// make sure that the updateRevision is different from what we have already done
+	targetHash, verifyErr := utils.ComputeSpecHash(c.daemonSet.Spec.Template)
+	if verifyErr != nil {
+		// do not fail the rollout because we can't compute the hash value for some reason
+		c.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	if targetHash == c.rolloutStatus.LastAppliedPodTemplateIdentifier {
+		return false, fmt.Errorf("there is no difference between the source and target, hash = %s", targetHash)
+	}
+
+	// check if the rollout batch replicas added up to the number of the nodes
+	if verifyErr = c.verifyRolloutBatchReplicaValue(currentSize); verifyErr != nil {
+		return false, verifyErr
+	}
+
+	// check if the DaemonSet has any controller
+	if controller := metav1.GetControllerOf(c.daemonSet); controller != nil {
+		return false, fmt.Errorf("the DaemonSet %s has a controller owner %s",
+			c.daemonSet.GetName(), controller.String())
+	}
+
+	// mark the rollout verified
+	c.recorder.Event(c.parentController, event.Normal("Rollout Verified",
+		"Rollout spec and the DaemonSet resource are verified"))
+	// record the new pod template DaemonSet on success
+	c.rolloutStatus.NewPodTemplateIdentifier = targetHash
+	return true, nil
+}
+
+// Initialize makes sure that the DaemonSet is under our control and the pods are only updated on delete
+func (c *DaemonSetRolloutController) Initialize(ctx context.Context) (bool, error) {
+	if err := c.fetchDaemonSet(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	daemonSetPatch := client.MergeFrom(c.daemonSet.DeepCopy())
+	if controller := metav1.GetControllerOf(c.daemonSet); controller == nil || !isRolloutController(controller) {
+		// add the parent controller to the owner of the DaemonSet
+		ref := metav1.NewControllerRef(c.parentController, c.parentController.GetObjectKind().GroupVersionKind())
+		c.daemonSet.SetOwnerReferences(append(c.daemonSet.GetOwnerReferences(), *ref))
+	}
+	// stop the DaemonSet controller from rolling all the pods by itself
+	c.daemonSet.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+
+	if err := c.client.Patch(ctx, c.daemonSet, daemonSetPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning("Failed to the start the DaemonSet update", err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	// mark the rollout initialized
+	c.recorder.Event(c.parentController, event.Normal("Rollout Initialized", "Rollout resource are initialized"))
+	return true, nil
+}
+
+// RolloutOneBatchPods deletes the old pods of the current batch so that they are recreated with the new template
+func (c *DaemonSetRolloutController) RolloutOneBatchPods(ctx context.Context) (bool, error) {
+	if err := c.fetchDaemonSet(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+	pods, err := c.listPods(ctx)
+	if err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	newPodTarget := c.calculateCurrentTarget(c.rolloutStatus.RolloutTargetSize)
+	upgraded, oldPods := c.groupPods(pods)
+	var toDelete []*corev1.Pod
+	if podList := c.rolloutSpec.RolloutBatches[c.rolloutStatus.CurrentBatch].PodList; len(podList) != 0 {
+		toDelete = selectPodsInList(oldPods, podList)
+	} else if count := int(newPodTarget) - upgraded; count > 0 {
+		if count > len(oldPods) {
+			count = len(oldPods)
+		}
+		toDelete = oldPods[:count]
+	}
+
+	for _, pod := range toDelete {
+		if err := c.client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			c.recorder.Event(c.parentController, event.Warning(event.Reason(fmt.Sprintf(
+				"Failed to delete the pod %s of the DaemonSet %s", pod.Name, c.daemonSet.GetName())), err))
+			c.rolloutStatus.RolloutRetry(err.Error())
+			// nolint:nilerr
+			return false, nil
+		}
+		klog.InfoS("deleted the old pod of the DaemonSet", "pod", pod.Name, "node", pod.Spec.NodeName,
+			"batch", c.rolloutStatus.CurrentBatch)
+	}
+
+	// record the finished upgrade action
+	klog.InfoS("upgraded one batch", "current batch", c.rolloutStatus.CurrentBatch,
+		"target size", newPodTarget, "deleted pods", len(toDelete))
+	c.recorder.Event(c.parentController, event.Normal("Batch Rollout",
+		fmt.Sprintf("Finished submiting all upgrade quests for batch %d", c.rolloutStatus.CurrentBatch)))
+	c.rolloutStatus.UpgradedReplicas = newPodTarget
+	return true, nil
+}
+
+// CheckOneBatchPods checks to see if the pods are all available according to the rollout plan
+func (c *DaemonSetRolloutController) CheckOneBatchPods(ctx context.Context) (bool, error) {
+	if err := c.fetchDaemonSet(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+	pods, err := c.listPods(ctx)
+	if err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	if len(c.rolloutSpec.RolloutBatches) <= int(c.rolloutStatus.CurrentBatch) {
+		err := errors.New("somehow, currentBatch number exceeded the rolloutBatches spec")
+		klog.ErrorS(err, "total batch", len(c.rolloutSpec.RolloutBatches), "current batch",
+			c.rolloutStatus.CurrentBatch)
+		return false, err
+	}
+
+	newPodTarget := c.calculateCurrentTarget(c.rolloutStatus.RolloutTargetSize)
+	readyPodCount := 0
+	for i := range pods {
+		if pod := &pods[i]; c.isUpgraded(pod) && pod.DeletionTimestamp == nil && isPodReady(pod) {
+			readyPodCount++
+		}
+	}
+
+	currentBatch := c.rolloutSpec.RolloutBatches[c.rolloutStatus.CurrentBatch]
+	maxUnavail := 0
+	if currentBatch.MaxUnavailable != nil {
+		maxUnavail, _ = intstr.GetValueFromIntOrPercent(currentBatch.MaxUnavailable,
+			int(c.rolloutStatus.RolloutTargetSize), true)
+	}
+	klog.InfoS("checking the rolling out progress", "current batch", c.rolloutStatus.CurrentBatch,
+		"new pod count target", newPodTarget, "new ready pod count", readyPodCount,
+		"max unavailable pod allowed", maxUnavail)
+	c.rolloutStatus.UpgradedReadyReplicas = int32(readyPodCount)
+
+	if maxUnavail+readyPodCount >= int(newPodTarget) {
+		// record the successful upgrade
+		klog.InfoS("all pods in current batch are ready", "current batch", c.rolloutStatus.CurrentBatch)
+		c.recorder.Event(c.parentController, event.Normal("Batch Available",
+			fmt.Sprintf("Batch %d is available", c.rolloutStatus.CurrentBatch)))
+		return true, nil
+	}
+
+	// continue to verify
+	klog.InfoS("the batch is not ready yet", "current batch", c.rolloutStatus.CurrentBatch)
+	c.rolloutStatus.RolloutRetry("the batch is not ready yet")
+	return false, nil
+}
+
+// FinalizeOneBatch makes sure that the rollout status are updated correctly
+func (c *DaemonSetRolloutController) FinalizeOneBatch(ctx context.Context) (bool, error) {
+	status := c.rolloutStatus
+	spec := c.rolloutSpec
+
+	if spec.BatchPartition != nil && *spec.BatchPartition < status.CurrentBatch {
+		err := fmt.Errorf("the current batch value in the status is greater than the batch partition")
+		klog.ErrorS(err, "we have moved past the user defined partition", "user specified batch partition",
+			*spec.BatchPartition, "current batch we are working on", status.CurrentBatch)
+		return false, err
+	}
+
+	upgradedReplicas := int(status.UpgradedReplicas)
+	currentBatch := int(status.CurrentBatch)
+	plan := c.effectiveRolloutPlan()
+	// calculate the lower bound of the possible pod count just before the current batch
+	podCount := calculateNewBatchTarget(plan, 0, int(status.RolloutTargetSize), currentBatch-1)
+	// the recorded number should be at least as much as the all the pods before the current batch
+	if podCount > upgradedReplicas {
+		err := fmt.Errorf("the upgraded replica in the status is less than all the pods in the previous batch")
+		klog.ErrorS(err, "rollout status inconsistent", "upgraded num status", upgradedReplicas,
+			"pods in all the previous batches", podCount)
+		return false, err
+	}
+
+	// calculate the upper bound with the current batch
+	podCount = calculateNewBatchTarget(plan, 0, int(status.RolloutTargetSize), currentBatch)
+	// the recorded number should be not as much as the all the pods including the active batch
+	if podCount < upgradedReplicas {
+		err := fmt.Errorf("the upgraded replica in the status is greater than all the pods in the current batch")
+		klog.ErrorS(err, "rollout status inconsistent", "total target size", status.RolloutTargetSize,
+			"upgraded num status", upgradedReplicas, "pods in the batches including the current batch", podCount)
+		return false, err
+	}
+	return true, nil
+}
+
+// Finalize restores the rolling update strategy of the DaemonSet and releases it
+func (c *DaemonSetRolloutController) Finalize(ctx context.Context, succeed bool) bool {
+	if err := c.fetchDaemonSet(ctx); err != nil {
+		// don't fail the rollout just because of we can't get the resource
+		return false
+	}
+
+	daemonSetPatch := client.MergeFrom(c.daemonSet.DeepCopy())
+	var newOwnerList []metav1.OwnerReference
+	owners := c.daemonSet.GetOwnerReferences()
+	for i := range owners {
+		if isRolloutController(&owners[i]) {
+			continue
+		}
+		newOwnerList = append(newOwnerList, owners[i])
+	}
+	c.daemonSet.SetOwnerReferences(newOwnerList)
+	// the rest of the pods are rolled by the DaemonSet controller if the rollout is stopped in the middle
+	c.daemonSet.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType}
+
+	if err := c.client.Patch(ctx, c.daemonSet, daemonSetPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning("Failed to the release the DaemonSet", err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return false
+	}
+
+	// mark the resource finalized
+	c.rolloutStatus.LastAppliedPodTemplateIdentifier = c.rolloutStatus.NewPodTemplateIdentifier
+	c.recorder.Event(c.parentController, event.Normal("Rollout Finalized",
+		fmt.Sprintf("Rollout resource are finalized, succeed := %t", succeed)))
+	return true
+}
+
+// check if the replicas in all the rollout batches add up to the right number
+func (c *DaemonSetRolloutController) verifyRolloutBatchReplicaValue(totalSize int32) error {
+	return verifyBatchesWithRollout(c.effectiveRolloutPlan(), totalSize)
+}
+
+// the number of the upgraded pods after the current batch
+func (c *DaemonSetRolloutController) calculateCurrentTarget(totalSize int32) int32 {
+	targetSize := int32(calculateNewBatchTarget(c.effectiveRolloutPlan(), 0, int(totalSize),
+		int(c.rolloutStatus.CurrentBatch)))
+	klog.InfoS("Calculated the number of upgraded pods in the DaemonSet after current batch",
+		"current batch", c.rolloutStatus.CurrentBatch, "target size", targetSize)
+	return targetSize
+}
+
+// effectiveRolloutPlan returns the rollout plan with the size of the batches given by the pod list
+func (c *DaemonSetRolloutController) effectiveRolloutPlan() *v1alpha1.RolloutPlan {
+	plan := c.rolloutSpec.DeepCopy()
+	for i, batch := range plan.RolloutBatches {
+		if len(batch.PodList) != 0 {
+			plan.RolloutBatches[i].Replicas = intstr.FromInt(len(batch.PodList))
+		}
+	}
+	return plan
+}
+
+// groupPods returns the number of the pods that are upgraded or being recreated and the old pods to upgrade
+func (c *DaemonSetRolloutController) groupPods(pods []corev1.Pod) (int, []*corev1.Pod) {
+	var oldPods []*corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		// the deleted old pods will be recreated with the new template
+		if !c.isUpgraded(pod) && pod.DeletionTimestamp == nil {
+			oldPods = append(oldPods, pod)
+		}
+	}
+	// the pods that are deleted but not recreated yet are counted as upgraded
+	upgraded := int(c.rolloutStatus.RolloutTargetSize) - len(oldPods)
+	if upgraded < 0 {
+		upgraded = 0
+	}
+	// upgrade the pods that are not ready first, then in the order of the nodes
+	sort.SliceStable(oldPods, func(i, j int) bool {
+		if ri, rj := isPodReady(oldPods[i]), isPodReady(oldPods[j]); ri != rj {
+			return !ri
+		}
+		return oldPods[i].Spec.NodeName < oldPods[j].Spec.NodeName
+	})
+	return upgraded, oldPods
+}
+
+// isUpgraded checks if the pod is created from the current template of the DaemonSet
+func (c *DaemonSetRolloutController) isUpgraded(pod *corev1.Pod) bool {
+	generation, ok := c.daemonSet.Annotations[appsv1.DeprecatedTemplateGeneration]
+	return ok && pod.Labels[daemonSetTemplateGenerationLabel] == generation
+}
+
+func (c *DaemonSetRolloutController) listPods(ctx context.Context) ([]corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(c.daemonSet.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	podList := &corev1.PodList{}
+	if err := c.client.List(ctx, podList, client.InNamespace(c.daemonSet.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	var pods []corev1.Pod
+	for i := range podList.Items {
+		if controller := metav1.GetControllerOf(&podList.Items[i]); controller != nil && controller.UID == c.daemonSet.UID {
+			pods = append(pods, podList.Items[i])
+		}
+	}
+	return pods, nil
+}
+
+func (c *DaemonSetRolloutController) fetchDaemonSet(ctx context.Context) error {
+	workload := appsv1.DaemonSet{}
+	if err := c.client.Get(ctx, c.targetNamespacedName, &workload); err != nil {
+		if !apierrors.IsNotFound(err) {
+			c.recorder.Event(c.parentController, event.Warning("Failed to get the DaemonSet", err))
+		}
+		return err
+	}
+	c.daemonSet = &workload
+	return nil
+}
+
+// size returns the number of the nodes that should run the DaemonSet pods
+func (c *DaemonSetRolloutController) size(ctx context.Context) (int32, error) {
+	if c.daemonSet == nil {
+		if err := c.fetchDaemonSet(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return c.daemonSet.Status.DesiredNumberScheduled, nil
+}
+
+// selectPodsInList returns the pods whose name or node is in the list
+func selectPodsInList(pods []*corev1.Pod, podList []string) []*corev1.Pod {
+	names := make(map[string]bool, len(podList))
+	for _, name := range podList {
+		names[name] = true
+	}
+	var selected []*corev1.Pod
+	for _, pod := range pods {
+		if names[pod.Name] || names[pod.Spec.NodeName] {
+			selected = append(selected, pod)
+		}
+	}
+	return selected
+}
+
+func isRolloutController(owner *metav1.OwnerReference) bool {
+	return owner.Kind == v1beta1.AppRolloutKind && owner.APIVersion == v1beta1.SchemeGroupVersion.String() ||
+		owner.Kind == v1alpha1.RolloutKind && owner.APIVersion == v1alpha1.SchemeGroupVersion.String()
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
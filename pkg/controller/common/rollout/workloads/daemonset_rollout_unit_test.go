@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+func TestDaemonSetRollout(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	labels := map[string]string{"app": "agent"}
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "agent",
+			Namespace:   "default",
+			UID:         "ds-uid",
+			Annotations: map[string]string{appsv1.DeprecatedTemplateGeneration: "2"},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "agent", Image: "agent:v2"}}},
+			},
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType},
+		},
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 4},
+	}
+	newPod := func(node, generation string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("agent-%s-%s", node, generation),
+				Namespace: "default",
+				Labels:    map[string]string{"app": "agent", daemonSetTemplateGenerationLabel: generation},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "DaemonSet",
+					Name:       "agent",
+					UID:        "ds-uid",
+					Controller: pointer.BoolPtr(true),
+				}},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type:   corev1.PodReady,
+				Status: corev1.ConditionTrue,
+			}}},
+		}
+	}
+	objs := []client.Object{ds}
+	for i := 0; i < 4; i++ {
+		objs = append(objs, newPod(fmt.Sprintf("node-%d", i), "1"))
+	}
+	cli := fake.NewClientBuilder().WithObjects(objs...).Build()
+
+	rollout := &v1beta1.AppRollout{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.AppRolloutKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "rollout", Namespace: "default", UID: "rollout-uid"},
+	}
+	rolloutSpec := &v1alpha1.RolloutPlan{
+		RolloutBatches: []v1alpha1.RolloutBatch{
+			{Replicas: intstr.FromInt(1)},
+			{PodList: []string{"node-3"}},
+			{Replicas: intstr.FromInt(2)},
+		},
+	}
+	rolloutStatus := &v1alpha1.RolloutStatus{}
+	c := NewDaemonSetRolloutController(cli, event.NewNopRecorder(), rollout, rolloutSpec, rolloutStatus,
+		types.NamespacedName{Namespace: "default", Name: "agent"})
+
+	// nodes returns the nodes of the pods with the template generation
+	nodes := func(generation string) []string {
+		podList := &corev1.PodList{}
+		r.NoError(cli.List(ctx, podList))
+		var nodes []string
+		for _, pod := range podList.Items {
+			if pod.Labels[daemonSetTemplateGenerationLabel] == generation {
+				nodes = append(nodes, pod.Spec.NodeName)
+			}
+		}
+		sort.Strings(nodes)
+		return nodes
+	}
+	// recreate acts as the DaemonSet controller to create the missing pods with the new template
+	recreate := func() {
+		existing := map[string]bool{}
+		for _, node := range append(nodes("1"), nodes("2")...) {
+			existing[node] = true
+		}
+		for i := 0; i < 4; i++ {
+			if node := fmt.Sprintf("node-%d", i); !existing[node] {
+				r.NoError(cli.Create(ctx, newPod(node, "2")))
+			}
+		}
+	}
+
+	verified, err := c.VerifySpec(ctx)
+	r.NoError(err)
+	r.True(verified)
+	r.Equal(int32(4), rolloutStatus.RolloutTargetSize)
+
+	initialized, err := c.Initialize(ctx)
+	r.NoError(err)
+	r.True(initialized)
+	got := &appsv1.DaemonSet{}
+	r.NoError(cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "agent"}, got))
+	r.Equal(appsv1.OnDeleteDaemonSetStrategyType, got.Spec.UpdateStrategy.Type)
+	r.Equal("rollout", metav1.GetControllerOf(got).Name)
+
+	wantNewNodes := [][]string{
+		{"node-0"},
+		{"node-0", "node-3"},
+		{"node-0", "node-1", "node-2", "node-3"},
+	}
+	for batch, want := range wantNewNodes {
+		rolloutStatus.CurrentBatch = int32(batch)
+		done, err := c.RolloutOneBatchPods(ctx)
+		r.NoError(err)
+		r.True(done)
+		// the batch is not ready until the pods are recreated
+		ready, err := c.CheckOneBatchPods(ctx)
+		r.NoError(err)
+		r.False(ready, "batch %d", batch)
+
+		recreate()
+		r.Equal(want, nodes("2"), "batch %d", batch)
+		ready, err = c.CheckOneBatchPods(ctx)
+		r.NoError(err)
+		r.True(ready, "batch %d", batch)
+		finalized, err := c.FinalizeOneBatch(ctx)
+		r.NoError(err)
+		r.True(finalized, "batch %d", batch)
+	}
+	r.Empty(nodes("1"))
+
+	r.True(c.Finalize(ctx, true))
+	r.NoError(cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "agent"}, got))
+	r.Equal(appsv1.RollingUpdateDaemonSetStrategyType, got.Spec.UpdateStrategy.Type)
+	r.Equal(rolloutStatus.NewPodTemplateIdentifier, rolloutStatus.LastAppliedPodTemplateIdentifier)
+}
+
+func TestVerifyRolloutBatchReplicaValue4DaemonSet(t *testing.T) {
+	c := &DaemonSetRolloutController{
+		workloadController: workloadController{
+			rolloutSpec: &v1alpha1.RolloutPlan{
+				RolloutBatches: []v1alpha1.RolloutBatch{
+					{PodList: []string{"node-0", "node-1"}},
+					{Replicas: intstr.FromInt(1)},
+				},
+			},
+		},
+	}
+	require.NoError(t, c.verifyRolloutBatchReplicaValue(3))
+	require.EqualError(t, c.verifyRolloutBatchReplicaValue(4),
+		"the rollout plan batch size mismatch, total batch size = 3, totalReplicas size = 4")
+}
@@ -291,6 +291,36 @@ func (c *DeploymentRolloutController) Finalize(ctx context.Context, succeed bool
 	return true
 }
 
+// Rollback scales the source Deployment back to the rollout size and then scales the target Deployment down
+func (c *DeploymentRolloutController) Rollback(ctx context.Context) (bool, error) {
+	if err := c.fetchDeployments(ctx); err != nil {
+		// don't fail the rollback just because of we can't get the resource
+		// nolint:nilerr
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+	totalSize := c.rolloutStatus.RolloutTargetSize
+	if getDeploymentReplicas(&c.sourceDeploy) < totalSize {
+		klog.InfoS("set source deployment replicas to roll back", "deploy", c.sourceDeploy.Name, "sourceSize", totalSize)
+		return false, c.scaleDeployment(ctx, &c.sourceDeploy, totalSize)
+	}
+	// make sure that the source deployment is ready before removing the target pods
+	if c.sourceDeploy.Status.ReadyReplicas < totalSize {
+		c.rolloutStatus.RolloutRetry(fmt.Sprintf("the rollback is not ready yet with %d source pods ready",
+			c.sourceDeploy.Status.ReadyReplicas))
+		return false, nil
+	}
+	if getDeploymentReplicas(&c.targetDeploy) != 0 {
+		klog.InfoS("set target deployment replicas to roll back", "deploy", c.targetDeploy.Name, "targetSize", 0)
+		return false, c.scaleDeployment(ctx, &c.targetDeploy, 0)
+	}
+	c.rolloutStatus.UpgradedReplicas = 0
+	c.rolloutStatus.UpgradedReadyReplicas = 0
+	c.recorder.Event(c.parentController, event.Normal("Rollout Rolled Back",
+		fmt.Sprintf("The source deployment %s is scaled back to %d", c.sourceDeploy.Name, totalSize)))
+	return true, nil
+}
+
 /* ----------------------------------
 The functions below are helper functions
 ------------------------------------- */
@@ -17,8 +17,19 @@
 package workloads
 
 import (
+	"context"
 	"testing"
 
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/require"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
 )
 
@@ -98,3 +109,55 @@ func TestCalculateCurrentSource(t *testing.T) {
 		})
 	}
 }
+
+func TestDeploymentRollback(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	r.NoError(apps.AddToScheme(scheme))
+	newDeploy := func(name string, replicas int32) *apps.Deployment {
+		return &apps.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       apps.DeploymentSpec{Replicas: pointer.Int32Ptr(replicas)},
+			Status:     apps.DeploymentStatus{Replicas: replicas, ReadyReplicas: replicas},
+		}
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newDeploy("v1", 6), newDeploy("v2", 4)).Build()
+	source := types.NamespacedName{Namespace: "default", Name: "v1"}
+	target := types.NamespacedName{Namespace: "default", Name: "v2"}
+	rolloutStatus := &v1alpha1.RolloutStatus{RolloutTargetSize: 10, UpgradedReplicas: 4}
+	c := NewDeploymentRolloutController(cli, event.NewNopRecorder(), &v1beta1.AppRollout{}, &v1alpha1.RolloutPlan{},
+		rolloutStatus, source, target)
+	getReplicas := func(key types.NamespacedName) int32 {
+		deploy := &apps.Deployment{}
+		r.NoError(cli.Get(ctx, key, deploy))
+		return *deploy.Spec.Replicas
+	}
+
+	// the source is scaled back first
+	done, err := c.Rollback(ctx)
+	r.NoError(err)
+	r.False(done)
+	r.Equal(int32(10), getReplicas(source))
+	r.Equal(int32(4), getReplicas(target))
+
+	// the target is kept until the source pods are ready
+	done, err = c.Rollback(ctx)
+	r.NoError(err)
+	r.False(done)
+	r.Equal(int32(4), getReplicas(target))
+
+	deploy := &apps.Deployment{}
+	r.NoError(cli.Get(ctx, source, deploy))
+	deploy.Status.ReadyReplicas = 10
+	r.NoError(cli.Status().Update(ctx, deploy))
+	done, err = c.Rollback(ctx)
+	r.NoError(err)
+	r.False(done)
+	r.Equal(int32(0), getReplicas(target))
+
+	done, err = c.Rollback(ctx)
+	r.NoError(err)
+	r.True(done)
+	r.Equal(int32(0), rolloutStatus.UpgradedReplicas)
+}
@@ -52,6 +52,14 @@ type Args struct {
 	// The default value is 10.
 	AppRevisionLimit int
 
+	// WorkflowRunLimit is the maximum number of workflow runs that will be maintained for each application.
+	// The default value is 10.
+	WorkflowRunLimit int
+
+	// DriftDetectionInterval is the interval to compare the applied resources of the applications with their
+	// applied configurations. The drift detection is disabled if it's 0.
+	DriftDetectionInterval time.Duration
+
 	// DefRevisionLimit is the maximum number of component/trait definition revisions that will be maintained.
 	// The default value is 20.
 	DefRevisionLimit int
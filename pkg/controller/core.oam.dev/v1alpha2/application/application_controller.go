@@ -18,6 +18,7 @@ package application
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -38,12 +39,14 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	velatypes "github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	common2 "github.com/oam-dev/kubevela/pkg/controller/common"
 	core "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/assemble"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/dispatch"
 	"github.com/oam-dev/kubevela/pkg/cue/packages"
 	monitorContext "github.com/oam-dev/kubevela/pkg/monitor/context"
 	"github.com/oam-dev/kubevela/pkg/multicluster"
@@ -51,6 +54,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/pkg/utils/apply"
+	errors2 "github.com/oam-dev/kubevela/pkg/utils/errors"
 	"github.com/oam-dev/kubevela/pkg/workflow"
 	"github.com/oam-dev/kubevela/version"
 )
@@ -62,6 +66,8 @@ const (
 const (
 	// baseWorkflowBackoffWaitTime is the time to wait before reconcile workflow again
 	baseWorkflowBackoffWaitTime = 3000 * time.Millisecond
+	// orderedDeletionRequeueInterval is the time to wait before checking the resources deleted in order again
+	orderedDeletionRequeueInterval = 5 * time.Second
 
 	legacyResourceTrackerFinalizer = "resourceTracker.finalizer.core.oam.dev"
 	// resourceTrackerFinalizer is to delete the resource tracker of the latest app revision.
@@ -80,11 +86,14 @@ type Reconciler struct {
 	Recorder             event.Recorder
 	applicator           apply.Applicator
 	appRevisionLimit     int
+	workflowRunLimit     int
 	concurrentReconciles int
+	driftInterval        time.Duration
 }
 
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core.oam.dev,resources=workflowruns,verbs=get;list;watch;create;delete
 
 // Reconcile process app event
 // nolint:gocyclo
@@ -118,12 +127,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		app:    app,
 		parser: appParser,
 	}
-	endReconcile, err := r.handleFinalizers(logCtx, app)
+	endReconcile, result, err := r.handleFinalizers(logCtx, app)
 	if err != nil {
 		return r.endWithNegativeCondition(logCtx, app, condition.ReconcileError(err), common.ApplicationStarting)
 	}
 	if endReconcile {
-		return ctrl.Result{}, nil
+		return result, nil
 	}
 
 	appFile, err := appParser.GenerateAppFile(logCtx, app)
@@ -203,10 +212,17 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		switch workflowState {
 		case common.WorkflowStateSuspended:
 			logCtx.Info("Workflow return state=Suspend")
-			return ctrl.Result{}, r.patchStatusWithRetryOnConflict(logCtx, app, common.ApplicationWorkflowSuspending)
+			result := ctrl.Result{}
+			if resumeTime := workflow.NextResumeTime(app.Status.Workflow); resumeTime != nil {
+				result.RequeueAfter = time.Until(resumeTime.Time)
+				if result.RequeueAfter < baseWorkflowBackoffWaitTime {
+					result.RequeueAfter = baseWorkflowBackoffWaitTime
+				}
+			}
+			return result, r.patchStatusWithRetryOnConflict(logCtx, app, common.ApplicationWorkflowSuspending)
 		case common.WorkflowStateTerminated:
 			logCtx.Info("Workflow return state=Terminated")
-			if err := r.doWorkflowFinish(app, wf); err != nil {
+			if err := r.doWorkflowFinish(ctx, app, wf, workflowState); err != nil {
 				return r.endWithNegativeConditionWithRetry(ctx, app, condition.ErrorCondition("DoWorkflowFinish", err), common.ApplicationRunningWorkflow)
 			}
 			return ctrl.Result{}, r.patchStatusWithRetryOnConflict(logCtx, app, common.ApplicationWorkflowTerminated)
@@ -224,6 +240,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 						return e
 					})
 				}
+				if errors.As(err, &errors2.GCInProgressError{}) {
+					logCtx.Info("Waiting for outdated resources to be deleted in order")
+					return ctrl.Result{RequeueAfter: orderedDeletionRequeueInterval}, r.patchStatusWithRetryOnConflict(logCtx, app, common.ApplicationRunningWorkflow)
+				}
 				if err != nil {
 					logCtx.Error(err, "Failed to gc after workflow")
 					r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedGC, err))
@@ -231,7 +251,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				}
 				app.Status.ResourceTracker = ref
 			}
-			if err := r.doWorkflowFinish(app, wf); err != nil {
+			if err := r.doWorkflowFinish(logCtx, app, wf, workflowState); err != nil {
 				return r.endWithNegativeConditionWithRetry(logCtx, app, condition.ErrorCondition("DoWorkflowFinish", err), common.ApplicationRunningWorkflow)
 			}
 			app.Status.SetConditions(condition.ReadyCondition("WorkflowFinished"))
@@ -305,18 +325,25 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		Reason:             condition.ReasonReconcileSuccess,
 	})
 	r.Recorder.Event(app, event.Normal(velatypes.ReasonDeployed, velatypes.MessageDeployed))
+	if r.driftInterval > 0 {
+		if err := r.detectDrift(ctx, app, handler.preserveFieldsOptions); err != nil {
+			logCtx.Error(err, "Failed to detect drift")
+			r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedDriftDetection, err))
+		}
+		return ctrl.Result{RequeueAfter: r.driftInterval}, r.patchStatus(logCtx, app, phase)
+	}
 	return ctrl.Result{}, r.patchStatus(logCtx, app, phase)
 }
 
 // NOTE Because resource tracker is cluster-scoped resources, we cannot garbage collect them
 // by setting application(namespace-scoped) as their owners.
 // We must delete all resource trackers related to an application through finalizer logic.
-func (r *Reconciler) handleFinalizers(ctx monitorContext.Context, app *v1beta1.Application) (bool, error) {
+func (r *Reconciler) handleFinalizers(ctx monitorContext.Context, app *v1beta1.Application) (bool, ctrl.Result, error) {
 	if app.ObjectMeta.DeletionTimestamp.IsZero() {
 		if !meta.FinalizerExists(app, resourceTrackerFinalizer) {
 			meta.AddFinalizer(app, resourceTrackerFinalizer)
 			ctx.Info("Register new finalizer for application", "finalizer", resourceTrackerFinalizer)
-			return true, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
+			return true, ctrl.Result{}, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
 		}
 	} else {
 		if meta.FinalizerExists(app, legacyResourceTrackerFinalizer) {
@@ -326,10 +353,10 @@ func (r *Reconciler) handleFinalizers(ctx monitorContext.Context, app *v1beta1.A
 			rt.SetName(fmt.Sprintf("%s-%s", app.Namespace, app.Name))
 			if err := r.Client.Delete(ctx, rt); err != nil && !kerrors.IsNotFound(err) {
 				ctx.Error(err, "Failed to delete legacy resource tracker", "name", rt.Name)
-				return true, errors.WithMessage(err, "cannot remove finalizer")
+				return true, ctrl.Result{}, errors.WithMessage(err, "cannot remove finalizer")
 			}
 			meta.RemoveFinalizer(app, legacyResourceTrackerFinalizer)
-			return true, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
+			return true, ctrl.Result{}, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
 		}
 		if meta.FinalizerExists(app, resourceTrackerFinalizer) || meta.FinalizerExists(app, legacyOnlyRevisionFinalizer) {
 			listOpts := []client.ListOption{
@@ -340,25 +367,51 @@ func (r *Reconciler) handleFinalizers(ctx monitorContext.Context, app *v1beta1.A
 			rtList := &v1beta1.ResourceTrackerList{}
 			if err := r.Client.List(ctx, rtList, listOpts...); err != nil {
 				ctx.Error(err, "Failed to list resource tracker of app", "name", app.Name)
-				return true, errors.WithMessage(err, "cannot remove finalizer")
+				return true, ctrl.Result{}, errors.WithMessage(err, "cannot remove finalizer")
+			}
+			// handle the resources according to the rules of garbage-collect policy before deleting resource trackers
+			gcOptions, err := gcOptionsOfApp(app)
+			if err != nil {
+				ctx.Error(err, "Failed to parse garbage-collect policy of app", "name", app.Name)
+				return true, ctrl.Result{}, errors.WithMessage(err, "cannot remove finalizer")
+			}
+			var rts []*v1beta1.ResourceTracker
+			for i := range rtList.Items {
+				rts = append(rts, &rtList.Items[i])
+			}
+			finalized, err := dispatch.FinalizeResources(ctx, r.Client, rts, gcOptions)
+			if err == nil {
+				var subFinalized bool
+				subFinalized, err = multicluster.FinalizeResourcesInSubClusters(ctx, r.Client, app, func(c context.Context, subRTs []*v1beta1.ResourceTracker) (bool, error) {
+					return dispatch.FinalizeResources(c, r.Client, subRTs, gcOptions)
+				})
+				finalized = finalized && subFinalized
+			}
+			if err != nil {
+				ctx.Error(err, "Failed to finalize resources of app", "name", app.Name)
+				return true, ctrl.Result{}, errors.WithMessage(err, "cannot remove finalizer")
+			}
+			if !finalized {
+				ctx.Info("Waiting for resources of app to be deleted in order", "name", app.Name)
+				return true, ctrl.Result{RequeueAfter: orderedDeletionRequeueInterval}, nil
 			}
 			for _, rt := range rtList.Items {
 				if err := r.Client.Delete(ctx, rt.DeepCopy()); err != nil && !kerrors.IsNotFound(err) {
 					ctx.Error(err, "Failed to delete resource tracker", "name", rt.Name)
-					return true, errors.WithMessage(err, "cannot remove finalizer")
+					return true, ctrl.Result{}, errors.WithMessage(err, "cannot remove finalizer")
 				}
 			}
 			if err := multicluster.GarbageCollectionForAllResourceTrackersInSubCluster(ctx, r.Client, app); err != nil {
-				return true, err
+				return true, ctrl.Result{}, err
 			}
 			meta.RemoveFinalizer(app, resourceTrackerFinalizer)
 			// legacyOnlyRevisionFinalizer will be deprecated in the future
 			// this is for backward compatibility
 			meta.RemoveFinalizer(app, legacyOnlyRevisionFinalizer)
-			return true, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
+			return true, ctrl.Result{}, errors.Wrap(r.Client.Update(ctx, app), errUpdateApplicationFinalizer)
 		}
 	}
-	return false, nil
+	return false, ctrl.Result{}, nil
 }
 
 func (r *Reconciler) _endWithNegativeCondition(ctx context.Context, app *v1beta1.Application, condition condition.Condition, phase common.ApplicationPhase, retry bool) (ctrl.Result, error) {
@@ -406,14 +459,31 @@ func (r *Reconciler) patchStatusWithRetryOnConflict(ctx context.Context, app *v1
 	})
 }
 
-func (r *Reconciler) doWorkflowFinish(app *v1beta1.Application, wf workflow.Workflow) error {
+func (r *Reconciler) doWorkflowFinish(ctx context.Context, app *v1beta1.Application, wf workflow.Workflow, state common.WorkflowState) error {
 	if err := wf.Trace(); err != nil {
 		return errors.WithMessage(err, "record workflow state")
 	}
+	if err := workflow.RecordRun(ctx, r.Client, app, state, r.workflowRunLimit); err != nil {
+		return errors.WithMessage(err, "record workflow run")
+	}
 	app.Status.Workflow.Finished = true
 	return nil
 }
 
+// gcOptionsOfApp returns the gc options in the garbage-collect policy of the application
+func gcOptionsOfApp(app *v1beta1.Application) (dispatch.GCOptions, error) {
+	gcOpts := dispatch.GCOptions{}
+	for _, policy := range app.Spec.Policies {
+		if policy.Type != v1alpha1.GarbageCollectPolicyType || policy.Properties == nil {
+			continue
+		}
+		if err := json.Unmarshal(policy.Properties.Raw, &gcOpts); err != nil {
+			return gcOpts, errors.Wrapf(err, "invalid garbage-collect policy %s", policy.Name)
+		}
+	}
+	return gcOpts, nil
+}
+
 // appWillRollout judge whether the application will be released by rollout.
 // If it's true, application controller will only create or update application revision but not emit any other K8s
 // resources into the cluster. Rollout controller will do real release works.
@@ -466,7 +536,9 @@ func Setup(mgr ctrl.Manager, args core.Args) error {
 		pd:                   args.PackageDiscover,
 		applicator:           apply.NewAPIApplicator(mgr.GetClient()),
 		appRevisionLimit:     args.AppRevisionLimit,
+		workflowRunLimit:     args.WorkflowRunLimit,
 		concurrentReconciles: args.ConcurrentReconciles,
+		driftInterval:        args.DriftDetectionInterval,
 	}
 	return reconciler.SetupWithManager(mgr)
 }
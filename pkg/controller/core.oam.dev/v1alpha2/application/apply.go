@@ -56,7 +56,8 @@ type AppHandler struct {
 	deletedResources []common.ClusterObjectReference
 	parser           *appfile.Parser
 
-	gcOptions dispatch.GCOptions
+	gcOptions             dispatch.GCOptions
+	preserveFieldsOptions dispatch.PreserveFieldsOptions
 }
 
 // Dispatch apply manifests into k8s.
@@ -215,7 +216,8 @@ func (h *AppHandler) initDispatcher() {
 	if h.dispatcher == nil {
 		// only do GC when ALL resources are dispatched successfully
 		// so skip GC while dispatching addon resources
-		h.dispatcher = dispatch.NewAppManifestsDispatcher(h.r.Client, h.currentAppRev).StartAndSkipGC(h.latestTracker).WithGCOptions(h.gcOptions)
+		h.dispatcher = dispatch.NewAppManifestsDispatcher(h.r.Client, h.currentAppRev).StartAndSkipGC(h.latestTracker).WithGCOptions(h.gcOptions).
+			WithPreserveFieldsOptions(h.preserveFieldsOptions)
 	}
 }
 
@@ -376,10 +378,15 @@ func (h *AppHandler) handleRollout(ctx context.Context) (reconcile.Result, error
 // HandleBuiltInPolicies handle built in policies
 func (h *AppHandler) HandleBuiltInPolicies(policies []*appfile.Workload) error {
 	for _, policy := range policies {
-		if policy.Type == "garbage-collect" {
+		switch policy.Type {
+		case "garbage-collect":
 			if err := h.SetGCOptions(policy.Params); err != nil {
 				return err
 			}
+		case "preserve-fields":
+			if err := h.SetPreserveFieldsOptions(policy.Params); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -399,3 +406,18 @@ func (h *AppHandler) SetGCOptions(options map[string]interface{}) error {
 	h.gcOptions = gcOpts
 	return nil
 }
+
+// SetPreserveFieldsOptions set the rules of the fields preserved from the live objects for AppHandler
+func (h *AppHandler) SetPreserveFieldsOptions(options map[string]interface{}) error {
+	bt, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	preserveFieldsOpts := dispatch.PreserveFieldsOptions{}
+	if err = json.Unmarshal(bt, &preserveFieldsOpts); err != nil {
+		return err
+	}
+	h.preserveFieldsOptions = preserveFieldsOpts
+	return nil
+}
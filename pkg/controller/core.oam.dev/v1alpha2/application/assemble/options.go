@@ -131,6 +131,7 @@ func PrepareWorkloadForRollout(rolloutComp string) WorkloadOption {
 			cloneSetDisablePath            = "spec.updateStrategy.paused"
 			advancedStatefulSetDisablePath = "spec.updateStrategy.rollingUpdate.paused"
 			deploymentDisablePath          = "spec.paused"
+			daemonSetUpdateStrategyPath    = "spec.updateStrategy"
 		)
 		pv := fieldpath.Pave(assembledWorkload.UnstructuredContent())
 		// TODO: we can get the workloadDefinition name from workload.GetLabels()["oam.WorkloadTypeLabel"]
@@ -169,6 +170,15 @@ func PrepareWorkloadForRollout(rolloutComp string) WorkloadOption {
 			case reflect.TypeOf(appsv1.StatefulSet{}).Name():
 				// TODO: Pause StatefulSet here.
 				return nil
+			case reflect.TypeOf(appsv1.DaemonSet{}).Name():
+				// DaemonSet can't be paused, the pods are only recreated by the rollout controller on delete
+				if err := pv.SetValue(daemonSetUpdateStrategyPath,
+					map[string]interface{}{"type": string(appsv1.OnDeleteDaemonSetStrategyType)}); err != nil {
+					return err
+				}
+				klog.InfoS("we render a daemonset assembledWorkload with OnDelete update strategy",
+					"kind", assembledWorkload.GetKind(), "instance name", assembledWorkload.GetName())
+				return nil
 			}
 		}
 
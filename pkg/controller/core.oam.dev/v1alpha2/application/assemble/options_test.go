@@ -124,6 +124,34 @@ var _ = Describe("Test WorkloadOption", func() {
 			Expect(assembledDeploy.Spec.Paused).Should(BeTrue())
 		})
 
+		It("test rollout DaemonSet", func() {
+			By("Use DaemonSet as workload")
+			ds := &unstructured.Unstructured{}
+			ds.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(reflect.TypeOf(appsv1.DaemonSet{}).Name()))
+			ds.SetLabels(map[string]string{oam.LabelAppComponent: compName})
+			Expect(unstructured.SetNestedField(ds.Object, map[string]interface{}{
+				"type":          "RollingUpdate",
+				"rollingUpdate": map[string]interface{}{"maxUnavailable": int64(1)},
+			}, "spec", "updateStrategy")).Should(BeNil())
+			comp := types.ComponentManifest{
+				Name:             compName,
+				StandardWorkload: ds,
+			}
+			By("Add PrepareWorkloadForRollout WorkloadOption")
+			ao := NewAppManifests(appRev, appParser).WithWorkloadOption(PrepareWorkloadForRollout(compName))
+			ao.componentManifests = []*types.ComponentManifest{&comp}
+			workloads, _, _, err := ao.GroupAssembledManifests()
+			Expect(err).Should(BeNil())
+			Expect(len(workloads)).Should(Equal(1))
+
+			By("Verify workload is only updated on delete")
+			wl := workloads[compName]
+			assembledDS := &appsv1.DaemonSet{}
+			runtime.DefaultUnstructuredConverter.FromUnstructured(wl.Object, assembledDS)
+			Expect(assembledDS.Spec.UpdateStrategy.Type).Should(Equal(appsv1.OnDeleteDaemonSetStrategyType))
+			Expect(assembledDS.Spec.UpdateStrategy.RollingUpdate).Should(BeNil())
+		})
+
 	})
 
 	Describe("test DiscoveryHelmBasedWorkload", func() {
@@ -18,6 +18,7 @@ package dispatch
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 
 	"github.com/pkg/errors"
@@ -59,6 +60,8 @@ type AppManifestsDispatcher struct {
 	previousRT *v1beta1.ResourceTracker
 	skipGC     bool
 
+	preserveFieldsOptions PreserveFieldsOptions
+
 	appRevName    string
 	namespace     string
 	currentRTName string
@@ -95,6 +98,12 @@ func (a *AppManifestsDispatcher) WithGCOptions(gcOptions GCOptions) *AppManifest
 	return a
 }
 
+// WithPreserveFieldsOptions set the rules of the fields preserved from the live objects for AppManifestsDispatcher
+func (a *AppManifestsDispatcher) WithPreserveFieldsOptions(options PreserveFieldsOptions) *AppManifestsDispatcher {
+	a.preserveFieldsOptions = options
+	return a
+}
+
 // Dispatch apply manifests into k8s and return a resource tracker recording applied manifests' references.
 // If GC is enabled, it will do GC after applying.
 // If 'UpgradeAndSkipGC' is enabled, it will:
@@ -274,7 +283,12 @@ func (a *AppManifestsDispatcher) applyAndRecordManifests(ctx context.Context, ma
 
 		// each resource applied by dispatcher MUST be controlled by resource tracker
 		setOrOverrideOAMControllerOwner(rsc, ownerRef)
-		if err := a.applicator.Apply(ctx, rsc, applyOpts...); err != nil {
+		rscApplyOpts, err := a.applyOptionsOf(rsc, applyOpts)
+		if err != nil {
+			return errors.Wrapf(err, "cannot apply manifest, name: %q apiVersion: %q kind: %q",
+				rsc.GetName(), rsc.GetAPIVersion(), rsc.GetKind())
+		}
+		if err := a.applicator.Apply(ctx, rsc, rscApplyOpts...); err != nil {
 			klog.ErrorS(err, "Failed to apply a resource", "object",
 				klog.KObj(rsc), "apiVersion", rsc.GetAPIVersion(), "kind", rsc.GetKind())
 			return errors.Wrapf(err, "cannot apply manifest, name: %q apiVersion: %q kind: %q",
@@ -286,6 +300,34 @@ func (a *AppManifestsDispatcher) applyAndRecordManifests(ctx context.Context, ma
 	return a.updateResourceTrackerStatus(ctx, manifests)
 }
 
+// applyOptionsOf returns the apply options of the resource according to its apply mode annotation and the
+// preserve-fields rules, the resources in server-side mode are applied with the field manager of the application
+func (a *AppManifestsDispatcher) applyOptionsOf(rsc *unstructured.Unstructured, applyOpts []apply.ApplyOption) ([]apply.ApplyOption, error) {
+	opts := append([]apply.ApplyOption{}, applyOpts...)
+	annotations := rsc.GetAnnotations()
+	switch mode := annotations[oam.AnnotationApplyMode]; mode {
+	case "", oam.ApplyModeClientSide:
+	case oam.ApplyModeServerSide:
+		forceConflicts := annotations[oam.AnnotationApplyForceConflicts] == "true"
+		opts = append(opts, apply.ServerSideApply(FieldManagerOf(a.appRev), forceConflicts))
+	default:
+		return nil, errors.Errorf("unknown apply mode %q", mode)
+	}
+	if paths := a.preserveFieldsOptions.PreservedPaths(rsc); len(paths) != 0 {
+		opts = append(opts, apply.PreserveFields(paths))
+	}
+	return opts, nil
+}
+
+// FieldManagerOf returns the field manager used by the server-side apply of the application
+func FieldManagerOf(appRev *v1beta1.ApplicationRevision) string {
+	appName := appRev.Spec.Application.Name
+	if appName == "" {
+		appName = appRev.GetLabels()[oam.LabelAppName]
+	}
+	return fmt.Sprintf("kubevela-app/%s/%s", appRev.Namespace, appName)
+}
+
 // ImmutableResourcesUpdate only updates the ownerReference
 // TODO(wonderflow): we should allow special fields to be updated. e.g. the resources.requests for bound claims for PV should be able to update
 func (a *AppManifestsDispatcher) ImmutableResourcesUpdate(ctx context.Context, res *unstructured.Unstructured, ownerRef metav1.OwnerReference, applyOpts []apply.ApplyOption) (bool, error) {
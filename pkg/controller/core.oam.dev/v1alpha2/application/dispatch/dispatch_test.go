@@ -24,7 +24,9 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
 )
 
 func TestSetOAMOwner(t *testing.T) {
@@ -138,3 +140,32 @@ func TestCheckComponentDeleted(t *testing.T) {
 		}
 	}
 }
+
+func TestApplyOptionsOf(t *testing.T) {
+	appRev := &v1beta1.ApplicationRevision{}
+	appRev.SetName("app-v1")
+	appRev.SetNamespace("default")
+	appRev.Spec.Application.SetName("app")
+	a := &AppManifestsDispatcher{appRev: appRev}
+	assert.Equal(t, "kubevela-app/default/app", FieldManagerOf(appRev))
+
+	baseOpts := []apply.ApplyOption{apply.NotUpdateRenderHashEqual()}
+	testCase := map[string]struct {
+		annotations map[string]string
+		wantOpts    int
+		wantErr     bool
+	}{
+		"default mode":     {annotations: nil, wantOpts: 1},
+		"client-side mode": {annotations: map[string]string{oam.AnnotationApplyMode: oam.ApplyModeClientSide}, wantOpts: 1},
+		"server-side mode": {annotations: map[string]string{oam.AnnotationApplyMode: oam.ApplyModeServerSide}, wantOpts: 2},
+		"unknown mode":     {annotations: map[string]string{oam.AnnotationApplyMode: "replace"}, wantErr: true},
+	}
+	for caseName, tc := range testCase {
+		rsc := &unstructured.Unstructured{}
+		rsc.SetAnnotations(tc.annotations)
+		opts, err := a.applyOptionsOf(rsc, baseOpts)
+		assert.Equal(t, tc.wantErr, err != nil, caseName)
+		assert.Equal(t, tc.wantOpts, len(opts), caseName)
+	}
+	assert.Equal(t, 1, len(baseOpts))
+}
@@ -18,9 +18,11 @@ package dispatch
 
 import (
 	"context"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -32,11 +34,56 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/oam"
+	errors2 "github.com/oam-dev/kubevela/pkg/utils/errors"
 )
 
 // GCOptions contains options for gc
 type GCOptions struct {
 	KeepLegacyResource bool `json:"keepLegacyResource,omitempty"`
+	// Rules decide how the selected resources are garbage collected, the first matched rule is used
+	Rules []GCRule `json:"rules,omitempty"`
+}
+
+// GCStrategy is the strategy of garbage collecting a resource
+type GCStrategy string
+
+const (
+	// GCStrategyDefault deletes the resource when it's no longer used by the application or the application is deleted
+	GCStrategyDefault GCStrategy = ""
+	// GCStrategyKeepOnDelete deletes the resource when it's no longer used by the application, but keeps it
+	// when the application is deleted
+	GCStrategyKeepOnDelete GCStrategy = "keep-on-delete"
+	// GCStrategyOrphan never deletes the resource, it's orphaned instead
+	GCStrategyOrphan GCStrategy = "orphan"
+)
+
+// GCRule decides how the resources matched by the selector are garbage collected
+type GCRule struct {
+	Selector ResourceSelector `json:"selector,omitempty"`
+	Strategy GCStrategy       `json:"strategy,omitempty"`
+	// Order is the order of deleting the resources, the resources with smaller order are deleted first,
+	// e.g. set a bigger order for the namespaces to delete them after the workloads. The default order is 0.
+	Order int `json:"order,omitempty"`
+}
+
+// ruleOf returns the first rule matching the resource or nil
+func (o GCOptions) ruleOf(rsc *unstructured.Unstructured) *GCRule {
+	for i, rule := range o.Rules {
+		if rule.Selector.Match(rsc) {
+			return &o.Rules[i]
+		}
+	}
+	return nil
+}
+
+// isOrdered checks if any rule requires the ordered deletion
+func (o GCOptions) isOrdered() bool {
+	for _, rule := range o.Rules {
+		if rule.Order != 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // GarbageCollector do GC according two resource trackers
@@ -66,7 +113,9 @@ type GCHandler struct {
 	appRev v1beta1.ApplicationRevision
 }
 
-// GarbageCollect delete the old resources that are no longer in the new resource tracker
+// GarbageCollect delete the old resources that are no longer in the new resource tracker.
+// If the deletion is ordered, the resources are deleted group by group in the ascending order as FinalizeResources does,
+// a GCInProgressError is returned and the old resource tracker is kept until the last group is being deleted.
 func (h *GCHandler) GarbageCollect(ctx context.Context, oldRT, newRT *v1beta1.ResourceTracker, legacyRTs []*v1beta1.ResourceTracker) error {
 	h.oldRT = oldRT
 	h.newRT = newRT
@@ -90,6 +139,8 @@ func (h *GCHandler) GarbageCollect(ctx context.Context, oldRT, newRT *v1beta1.Re
 		// if legacy resourceTracker not track any resources, delete it.
 		return h.cleanUpResourceTracker(ctx, legacyRTs)
 	}
+	var toBeDeletedList []*unstructured.Unstructured
+	var orders []int
 	for _, oldRsc := range h.oldRT.Status.TrackedResources {
 		reused := false
 		for _, newRsc := range h.newRT.Status.TrackedResources {
@@ -115,13 +166,41 @@ func (h *GCHandler) GarbageCollect(ctx context.Context, oldRT, newRT *v1beta1.Re
 				// the resource have skipGC annotation, will not delete the resource
 				continue
 			}
-
-			if err := h.c.Delete(ctx, toBeDeleted); err != nil && !kerrors.IsNotFound(err) {
-				klog.ErrorS(err, "Failed to delete a resource", "name", oldRsc.Name, "apiVersion", oldRsc.APIVersion, "kind", oldRsc.Kind)
-				return errors.Wrapf(err, "cannot delete resource %q", oldRsc)
+			order, isOrphaned, err := h.handleResourceGCRule(ctx, toBeDeleted, oldRT)
+			if err != nil {
+				return errors.Wrap(err, "cannot handle resource gc rule")
+			}
+			if isOrphaned {
+				continue
+			}
+			toBeDeletedList = append(toBeDeletedList, toBeDeleted)
+			orders = append(orders, order)
+		}
+	}
+	// delete the resources with smaller order first
+	sort.Stable(&gcOrderSorter{resources: toBeDeletedList, orders: orders})
+	inProgress := false
+	var opts []client.DeleteOption
+	if h.gcOptions.isOrdered() {
+		// only delete the group with the smallest order, the rest are deleted after it's gone
+		for i := range orders {
+			if orders[i] != orders[0] {
+				toBeDeletedList, inProgress = toBeDeletedList[:i], true
+				break
 			}
-			klog.InfoS("Successfully GC a resource", "name", oldRsc.Name, "apiVersion", oldRsc.APIVersion, "kind", oldRsc.Kind)
 		}
+		opts = append(opts, client.PropagationPolicy(metav1.DeletePropagationForeground))
+	}
+	for _, toBeDeleted := range toBeDeletedList {
+		if err := h.c.Delete(ctx, toBeDeleted, opts...); err != nil && !kerrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete a resource", "name", toBeDeleted.GetName(), "apiVersion", toBeDeleted.GetAPIVersion(), "kind", toBeDeleted.GetKind())
+			return errors.Wrapf(err, "cannot delete resource %q", klog.KObj(toBeDeleted))
+		}
+		klog.InfoS("Successfully GC a resource", "name", toBeDeleted.GetName(), "apiVersion", toBeDeleted.GetAPIVersion(), "kind", toBeDeleted.GetKind())
+	}
+	if inProgress {
+		klog.InfoS("Waiting for resources to be deleted in order", "name", h.oldRT.Name, "order", orders[0])
+		return errors2.GCInProgressError{Name: h.oldRT.Name}
 	}
 	// delete the old resource tracker
 	if err := h.c.Delete(ctx, h.oldRT); err != nil && !kerrors.IsNotFound(err) {
@@ -188,6 +267,150 @@ func (h *GCHandler) handleResourceSkipGC(ctx context.Context, u *unstructured.Un
 	return true, nil
 }
 
+// handleResourceGCRule finds the gc rule of the resource, the resource is orphaned if the rule requires
+// it returns the deletion order of the resource and whether it's orphaned
+func (h *GCHandler) handleResourceGCRule(ctx context.Context, u *unstructured.Unstructured, oldRt *v1beta1.ResourceTracker) (int, bool, error) {
+	if len(h.gcOptions.Rules) == 0 {
+		return 0, false, nil
+	}
+	res := u.DeepCopy()
+	if err := h.c.Get(ctx, types.NamespacedName{Namespace: res.GetNamespace(), Name: res.GetName()}, res); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return 0, false, err
+		}
+		// resource have gone, deleting it again is a no-op
+		return 0, false, nil
+	}
+	rule := h.gcOptions.ruleOf(res)
+	if rule == nil {
+		return 0, false, nil
+	}
+	if rule.Strategy == GCStrategyOrphan {
+		return rule.Order, true, orphanResource(ctx, h.c, res, oldRt)
+	}
+	return rule.Order, false, nil
+}
+
+// orphanResource removes the owner references of the resource trackers from the resource
+func orphanResource(ctx context.Context, c client.Client, res *unstructured.Unstructured, rts ...*v1beta1.ResourceTracker) error {
+	var owners []metav1.OwnerReference
+	for _, ownerReference := range res.GetOwnerReferences() {
+		owned := false
+		for _, rt := range rts {
+			if ownerReference.UID == rt.GetUID() {
+				owned = true
+				break
+			}
+		}
+		if !owned {
+			owners = append(owners, ownerReference)
+		}
+	}
+	res.SetOwnerReferences(owners)
+	if err := c.Update(ctx, res); err != nil {
+		klog.ErrorS(err, "Failed to orphan a resource", "object", klog.KObj(res), "apiVersion", res.GetAPIVersion(), "kind", res.GetKind())
+		return err
+	}
+	klog.InfoS("Successfully orphan a resource", "object", klog.KObj(res), "apiVersion", res.GetAPIVersion(), "kind", res.GetKind())
+	return nil
+}
+
+// FinalizeResources handles the resources tracked by the resource trackers of a deleting application according to
+// the gc rules, it returns true if the resource trackers can be deleted.
+// The resources of strategy keep-on-delete or orphan are orphaned. If the deletion is ordered, the resources are
+// deleted group by group in the ascending order, the next group is not deleted until all the resources of the
+// previous group are gone, so it returns false if there are resources still being deleted.
+// Otherwise, the resources are deleted by the garbage collector of kubernetes after the resource trackers are deleted.
+func FinalizeResources(ctx context.Context, c client.Client, rts []*v1beta1.ResourceTracker, gcOptions GCOptions) (bool, error) {
+	if len(gcOptions.Rules) == 0 {
+		return true, nil
+	}
+	ordered := gcOptions.isOrdered()
+	groups := map[int][]*unstructured.Unstructured{}
+	visited := map[v1.ObjectReference]bool{}
+	for _, rt := range rts {
+		for _, ref := range rt.Status.TrackedResources {
+			key := v1.ObjectReference{APIVersion: ref.APIVersion, Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name}
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+			res := &unstructured.Unstructured{}
+			res.SetGroupVersionKind(ref.GroupVersionKind())
+			if err := c.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, res); err != nil {
+				if kerrors.IsNotFound(err) {
+					continue
+				}
+				return false, errors.Wrapf(err, "cannot get resource %q", klog.KObj(res))
+			}
+			if !isOwnedByAny(res, rts) {
+				continue
+			}
+			rule := gcOptions.ruleOf(res)
+			if rule != nil && (rule.Strategy == GCStrategyKeepOnDelete || rule.Strategy == GCStrategyOrphan) {
+				if err := orphanResource(ctx, c, res, rts...); err != nil {
+					return false, errors.Wrapf(err, "cannot orphan resource %q", klog.KObj(res))
+				}
+				continue
+			}
+			if ordered {
+				order := 0
+				if rule != nil {
+					order = rule.Order
+				}
+				groups[order] = append(groups[order], res)
+			}
+		}
+	}
+	if len(groups) == 0 {
+		return true, nil
+	}
+	// only delete the group with the smallest order, the rest are deleted after it's gone
+	var orders []int
+	for order := range groups {
+		orders = append(orders, order)
+	}
+	sort.Ints(orders)
+	for _, res := range groups[orders[0]] {
+		if res.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if err := c.Delete(ctx, res, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !kerrors.IsNotFound(err) {
+			return false, errors.Wrapf(err, "cannot delete resource %q", klog.KObj(res))
+		}
+		klog.InfoS("Successfully delete a resource in order", "object", klog.KObj(res), "order", orders[0])
+	}
+	return false, nil
+}
+
+func isOwnedByAny(obj metav1.Object, rts []*v1beta1.ResourceTracker) bool {
+	for _, rt := range rts {
+		if IsOwningObject(obj, rt) {
+			return true
+		}
+	}
+	return false
+}
+
+// gcOrderSorter sorts the resources by their deletion orders
+type gcOrderSorter struct {
+	resources []*unstructured.Unstructured
+	orders    []int
+}
+
+func (s *gcOrderSorter) Len() int {
+	return len(s.resources)
+}
+
+func (s *gcOrderSorter) Less(i, j int) bool {
+	return s.orders[i] < s.orders[j]
+}
+
+func (s *gcOrderSorter) Swap(i, j int) {
+	s.resources[i], s.resources[j] = s.resources[j], s.resources[i]
+	s.orders[i], s.orders[j] = s.orders[j], s.orders[i]
+}
+
 // SetGCOptions set gc options for GCHandler
 func (h *GCHandler) SetGCOptions(gcOptions GCOptions) {
 	h.gcOptions = gcOptions
@@ -17,12 +17,24 @@
 package dispatch
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	errors2 "github.com/oam-dev/kubevela/pkg/utils/errors"
 )
 
 func TestIsTrackedResources(t *testing.T) {
@@ -136,3 +148,187 @@ func TestIsTrackedResources(t *testing.T) {
 		assert.Equal(t, testcase.expect, isTrackedResources(testcase.oldRT, testcase.newRT))
 	}
 }
+
+func newTrackedConfigMap(name, component string, rt *v1beta1.ResourceTracker) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{oam.LabelAppComponent: component},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1beta1.SchemeGroupVersion.String(),
+				Kind:       v1beta1.ResourceTrackerKind,
+				Name:       rt.Name,
+				UID:        rt.UID,
+				Controller: pointer.BoolPtr(true),
+			}},
+		},
+	}
+}
+
+func trackResources(rt *v1beta1.ResourceTracker, names ...string) {
+	for _, name := range names {
+		rt.Status.TrackedResources = append(rt.Status.TrackedResources, corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       name,
+			Namespace:  "default",
+		})
+	}
+}
+
+func TestGarbageCollectWithRules(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	oldRT := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "app-v1-default", UID: "old-rt"}}
+	newRT := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "app-v2-default", UID: "new-rt"}}
+	trackResources(oldRT, "orphan", "delete", "keep-on-delete")
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		oldRT,
+		newTrackedConfigMap("orphan", "orphan", oldRT),
+		newTrackedConfigMap("delete", "delete", oldRT),
+		newTrackedConfigMap("keep-on-delete", "keep-on-delete", oldRT),
+	).Build()
+
+	h := NewGCHandler(cli, "default", v1beta1.ApplicationRevision{})
+	h.SetGCOptions(GCOptions{Rules: []GCRule{{
+		Selector: ResourceSelector{ComponentNames: []string{"orphan"}},
+		Strategy: GCStrategyOrphan,
+	}, {
+		Selector: ResourceSelector{ComponentNames: []string{"keep-on-delete"}},
+		Strategy: GCStrategyKeepOnDelete,
+	}}})
+	r.NoError(h.GarbageCollect(ctx, oldRT, newRT, nil))
+
+	cm := &corev1.ConfigMap{}
+	r.NoError(cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "orphan"}, cm))
+	r.Empty(cm.OwnerReferences)
+	// keep-on-delete only works when the application is deleted
+	r.True(kerrors.IsNotFound(cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "keep-on-delete"}, cm)))
+	r.True(kerrors.IsNotFound(cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "delete"}, cm)))
+	r.True(kerrors.IsNotFound(cli.Get(ctx, client.ObjectKey{Name: oldRT.Name}, &v1beta1.ResourceTracker{})))
+}
+
+func TestGarbageCollectInOrder(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	oldRT := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "app-v1-default", UID: "old-rt"}}
+	newRT := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "app-v2-default", UID: "new-rt"}}
+	trackResources(oldRT, "first", "second", "last", "reused")
+	trackResources(newRT, "reused")
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		oldRT,
+		newTrackedConfigMap("first", "first", oldRT),
+		newTrackedConfigMap("second", "second", oldRT),
+		newTrackedConfigMap("last", "last", oldRT),
+		newTrackedConfigMap("reused", "reused", newRT),
+	).Build()
+	exists := func(name string) bool {
+		err := cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &corev1.ConfigMap{})
+		if kerrors.IsNotFound(err) {
+			return false
+		}
+		r.NoError(err)
+		return true
+	}
+
+	h := NewGCHandler(cli, "default", v1beta1.ApplicationRevision{})
+	h.SetGCOptions(GCOptions{Rules: []GCRule{{
+		Selector: ResourceSelector{ComponentNames: []string{"last"}},
+		Order:    2,
+	}, {
+		Selector: ResourceSelector{ComponentNames: []string{"second"}},
+		Order:    1,
+	}}})
+	// the next group is not deleted until the previous group is gone, the old resource tracker is kept meanwhile
+	wantExisting := [][]string{
+		{"second", "last", "reused"},
+		{"last", "reused"},
+	}
+	for i, want := range wantExisting {
+		err := h.GarbageCollect(ctx, oldRT, newRT, nil)
+		r.True(errors.As(err, &errors2.GCInProgressError{}), "round %d", i)
+		var got []string
+		for _, name := range []string{"first", "second", "last", "reused"} {
+			if exists(name) {
+				got = append(got, name)
+			}
+		}
+		r.Equal(want, got, "round %d", i)
+		r.NoError(cli.Get(ctx, client.ObjectKey{Name: oldRT.Name}, &v1beta1.ResourceTracker{}))
+	}
+	r.NoError(h.GarbageCollect(ctx, oldRT, newRT, nil))
+	r.False(exists("last"))
+	r.True(exists("reused"))
+	r.True(kerrors.IsNotFound(cli.Get(ctx, client.ObjectKey{Name: oldRT.Name}, &v1beta1.ResourceTracker{})))
+}
+
+func TestFinalizeResources(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	rt := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "app-v1-default", UID: "rt"}}
+	otherRT := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "other-v1-default", UID: "other-rt"}}
+	trackResources(rt, "keep", "orphan", "first", "second", "last", "not-owned")
+	cli := fake.NewClientBuilder().WithObjects(
+		newTrackedConfigMap("keep", "keep", rt),
+		newTrackedConfigMap("orphan", "orphan", rt),
+		newTrackedConfigMap("first", "first", rt),
+		newTrackedConfigMap("second", "second", rt),
+		newTrackedConfigMap("last", "last", rt),
+		newTrackedConfigMap("not-owned", "last", otherRT),
+	).Build()
+	exists := func(name string) bool {
+		err := cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &corev1.ConfigMap{})
+		if kerrors.IsNotFound(err) {
+			return false
+		}
+		r.NoError(err)
+		return true
+	}
+
+	// without rules, the resources are deleted along with the resource trackers
+	finalized, err := FinalizeResources(ctx, cli, []*v1beta1.ResourceTracker{rt}, GCOptions{})
+	r.NoError(err)
+	r.True(finalized)
+
+	gcOptions := GCOptions{Rules: []GCRule{{
+		Selector: ResourceSelector{ComponentNames: []string{"keep"}},
+		Strategy: GCStrategyKeepOnDelete,
+	}, {
+		Selector: ResourceSelector{ComponentNames: []string{"orphan"}},
+		Strategy: GCStrategyOrphan,
+		Order:    -1,
+	}, {
+		Selector: ResourceSelector{ComponentNames: []string{"last"}},
+		Order:    2,
+	}, {
+		Selector: ResourceSelector{ComponentNames: []string{"second"}},
+		Order:    1,
+	}}}
+	wantExisting := [][]string{
+		{"keep", "orphan", "second", "last", "not-owned"},
+		{"keep", "orphan", "last", "not-owned"},
+		{"keep", "orphan", "not-owned"},
+	}
+	for i, want := range wantExisting {
+		finalized, err = FinalizeResources(ctx, cli, []*v1beta1.ResourceTracker{rt}, gcOptions)
+		r.NoError(err)
+		r.False(finalized, "round %d", i)
+		var got []string
+		for _, name := range []string{"keep", "orphan", "first", "second", "last", "not-owned"} {
+			if exists(name) {
+				got = append(got, name)
+			}
+		}
+		r.Equal(want, got, "round %d", i)
+	}
+	finalized, err = FinalizeResources(ctx, cli, []*v1beta1.ResourceTracker{rt}, gcOptions)
+	r.NoError(err)
+	r.True(finalized)
+
+	cm := &corev1.ConfigMap{}
+	for _, name := range []string{"keep", "orphan"} {
+		r.NoError(cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, cm))
+		assert.Empty(t, cm.OwnerReferences, name)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dispatch
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// PreserveFieldsOptions contains the rules of the fields that are preserved from the live objects when applying
+type PreserveFieldsOptions struct {
+	Rules []PreserveFieldsRule `json:"rules,omitempty"`
+}
+
+// PreserveFieldsRule preserves the fields in the paths of the resources matched by the selector
+type PreserveFieldsRule struct {
+	Selector ResourceSelector `json:"selector,omitempty"`
+	// Paths are the field paths, e.g. `spec.replicas` or `metadata.annotations[sidecar.istio.io/status]`
+	Paths []string `json:"paths"`
+}
+
+// ResourceSelector selects the resources dispatched by the application, an empty selector matches all resources.
+// A resource is selected only if all the non-empty conditions are matched.
+type ResourceSelector struct {
+	ComponentNames []string `json:"componentNames,omitempty"`
+	TraitTypes     []string `json:"traitTypes,omitempty"`
+	ResourceTypes  []string `json:"resourceTypes,omitempty"`
+	ResourceNames  []string `json:"resourceNames,omitempty"`
+}
+
+// Match checks if the resource is selected
+func (s ResourceSelector) Match(rsc *unstructured.Unstructured) bool {
+	labels := rsc.GetLabels()
+	return matchAny(s.ComponentNames, labels[oam.LabelAppComponent]) &&
+		matchAny(s.TraitTypes, labels[oam.TraitTypeLabel]) &&
+		matchAny(s.ResourceTypes, rsc.GetKind()) &&
+		matchAny(s.ResourceNames, rsc.GetName())
+}
+
+// PreservedPaths returns the paths of all the rules that select the resource
+func (o PreserveFieldsOptions) PreservedPaths(rsc *unstructured.Unstructured) []string {
+	var paths []string
+	for _, rule := range o.Rules {
+		if rule.Selector.Match(rsc) {
+			paths = append(paths, rule.Paths...)
+		}
+	}
+	return paths
+}
+
+func matchAny(candidates []string, value string) bool {
+	if len(candidates) == 0 {
+		return true
+	}
+	for _, c := range candidates {
+		if c == value {
+			return true
+		}
+	}
+	return false
+}
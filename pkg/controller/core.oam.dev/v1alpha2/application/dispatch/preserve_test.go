@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dispatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestPreservedPaths(t *testing.T) {
+	deploy := &unstructured.Unstructured{}
+	deploy.SetKind("Deployment")
+	deploy.SetName("web")
+	deploy.SetLabels(map[string]string{oam.LabelAppComponent: "web"})
+
+	ingress := &unstructured.Unstructured{}
+	ingress.SetKind("Ingress")
+	ingress.SetName("web")
+	ingress.SetLabels(map[string]string{oam.LabelAppComponent: "web", oam.TraitTypeLabel: "gateway"})
+
+	options := PreserveFieldsOptions{Rules: []PreserveFieldsRule{{
+		Selector: ResourceSelector{ComponentNames: []string{"web"}, ResourceTypes: []string{"Deployment"}},
+		Paths:    []string{"spec.replicas"},
+	}, {
+		Selector: ResourceSelector{TraitTypes: []string{"gateway"}},
+		Paths:    []string{"spec.ingressClassName"},
+	}, {
+		Paths: []string{"metadata.annotations[sidecar.istio.io/status]"},
+	}, {
+		Selector: ResourceSelector{ResourceNames: []string{"api"}},
+		Paths:    []string{"spec.template"},
+	}}}
+	assert.Equal(t, []string{"spec.replicas", "metadata.annotations[sidecar.istio.io/status]"}, options.PreservedPaths(deploy))
+	assert.Equal(t, []string{"spec.ingressClassName", "metadata.annotations[sidecar.istio.io/status]"}, options.PreservedPaths(ingress))
+	assert.Empty(t, PreserveFieldsOptions{}.PreservedPaths(deploy))
+
+	a := &AppManifestsDispatcher{}
+	a.WithPreserveFieldsOptions(options)
+	opts, err := a.applyOptionsOf(deploy, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(opts))
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	velatypes "github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/dispatch"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
+)
+
+// detectDrift compares the resources tracked by the current resource trackers of the application with the
+// configurations recorded when they were applied, the drifted resources are recorded in the status of the application
+// with the Drifted condition. The drifted resources are re-applied if the application has the auto-correct-drift
+// annotation. Fields preserved by the preserve-fields options are neither compared nor re-applied.
+// Resources without the recorded configurations, such as the ones applied in server-side mode, cannot be compared,
+// they are reported in the message of the Drifted condition and a DriftUntracked event.
+func (r *Reconciler) detectDrift(ctx context.Context, app *v1beta1.Application, preserveFieldsOptions dispatch.PreserveFieldsOptions) error {
+	autoCorrect := app.GetAnnotations()[oam.AnnotationAutoCorrectDrift] == "true"
+	refs, err := r.getTrackedResources(ctx, app)
+	if err != nil {
+		return err
+	}
+	var drifted []common.DriftedResource
+	var untracked []string
+	var errs []string
+	for _, ref := range refs {
+		res, tracked, err := r.checkResourceDrift(ctx, ref, preserveFieldsOptions, autoCorrect)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if !tracked {
+			untracked = append(untracked, resourceName(ref))
+			continue
+		}
+		if res == nil {
+			continue
+		}
+		drifted = append(drifted, *res)
+		if autoCorrect && !res.Missing {
+			r.Recorder.Event(app, event.Normal(velatypes.ReasonDriftCorrected,
+				fmt.Sprintf("Re-applied the drifted %s %s", ref.Kind, ref.Name)))
+		}
+	}
+	app.Status.DriftedResources = drifted
+	var untrackedMsg string
+	if len(untracked) != 0 {
+		untrackedMsg = fmt.Sprintf("%d resources are not tracked for drift: %s", len(untracked), strings.Join(untracked, ", "))
+		r.Recorder.Event(app, event.Warning(velatypes.ReasonDriftUntracked, errors.New(untrackedMsg)))
+	}
+	if len(drifted) == 0 {
+		app.Status.SetConditions(condition.NoDrift(untrackedMsg))
+	} else {
+		msg := fmt.Sprintf("%d resources have drifted from the applied configurations", len(drifted))
+		r.Recorder.Event(app, event.Warning(velatypes.ReasonDrifted, errors.New(msg)))
+		if untrackedMsg != "" {
+			msg += "; " + untrackedMsg
+		}
+		app.Status.SetConditions(condition.Drifted(msg))
+	}
+	if len(errs) != 0 {
+		return errors.Errorf("cannot detect drift of resources: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// getTrackedResources returns the resources recorded in the resource trackers of the current application revision,
+// the resource trackers are read from the local cluster and the sub clusters the application is applied to
+func (r *Reconciler) getTrackedResources(ctx context.Context, app *v1beta1.Application) ([]common.ClusterObjectReference, error) {
+	var rtName string
+	switch {
+	case app.Status.ResourceTracker != nil:
+		rtName = app.Status.ResourceTracker.Name
+	case app.Status.LatestRevision != nil:
+		rtName = dispatch.ConstructResourceTrackerName(app.Status.LatestRevision.Name, app.Namespace)
+	default:
+		return nil, nil
+	}
+	clusters := []string{multicluster.ClusterLocalName}
+	for _, cluster := range multicluster.GetAppliedClusters(app) {
+		if cluster != "" && cluster != multicluster.ClusterLocalName {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Strings(clusters[1:])
+	var refs []common.ClusterObjectReference
+	for _, cluster := range clusters {
+		rt := &v1beta1.ResourceTracker{}
+		if err := r.Get(multicluster.ContextWithClusterName(ctx, cluster), client.ObjectKey{Name: rtName}, rt); err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "cannot get resource tracker %s in cluster %s", rtName, cluster)
+		}
+		for _, tracked := range rt.Status.TrackedResources {
+			ref := common.ClusterObjectReference{ObjectReference: tracked}
+			if cluster != multicluster.ClusterLocalName {
+				ref.Cluster = cluster
+			}
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// resourceName returns the name of the resource shown in the drift messages
+func resourceName(ref common.ClusterObjectReference) string {
+	name := fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+	if ref.Cluster != "" {
+		name += " in cluster " + ref.Cluster
+	}
+	return name
+}
+
+// checkResourceDrift returns the drifted fields of the applied resource, nil is returned if the resource is not
+// drifted. The returned tracked is false if the resource has no last applied configuration to compare with.
+func (r *Reconciler) checkResourceDrift(ctx context.Context, ref common.ClusterObjectReference, preserveFieldsOptions dispatch.PreserveFieldsOptions, autoCorrect bool) (*common.DriftedResource, bool, error) {
+	ctx = multicluster.ContextWithClusterName(ctx, ref.Cluster)
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(ref.GroupVersionKind())
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, live); err != nil {
+		if kerrors.IsNotFound(err) {
+			return &common.DriftedResource{ClusterObjectReference: ref, Missing: true}, true, nil
+		}
+		return nil, false, errors.Wrapf(err, "cannot get %s %s", ref.Kind, ref.Name)
+	}
+	preservedPaths := preserveFieldsOptions.PreservedPaths(live)
+	fields, tracked, err := apply.DetectDrift(live, preservedPaths)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "cannot compare %s %s", ref.Kind, ref.Name)
+	}
+	if !tracked {
+		return nil, false, nil
+	}
+	if len(fields) == 0 {
+		return nil, true, nil
+	}
+	if autoCorrect {
+		if err := apply.CorrectDrift(ctx, r.Client, live, preservedPaths); err != nil {
+			return nil, false, errors.Wrapf(err, "cannot re-apply %s %s", ref.Kind, ref.Name)
+		}
+	}
+	return &common.DriftedResource{ClusterObjectReference: ref, Fields: fields}, true, nil
+}
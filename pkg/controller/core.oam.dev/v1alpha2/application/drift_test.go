@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/dispatch"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestDetectDrift(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	applied := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+	}
+	config, err := json.Marshal(applied)
+	r.NoError(err)
+	live := applied.DeepCopy()
+	live.SetAnnotations(map[string]string{oam.AnnotationLastAppliedConfig: string(config)})
+	live.Spec.Replicas = pointer.Int32Ptr(5)
+	// the configmap is not applied by the client-side apply so it's not tracked
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"}}
+	rt := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "app-v1-default"}}
+	s := runtime.NewScheme()
+	r.NoError(scheme.AddToScheme(s))
+	r.NoError(v1beta1.SchemeBuilder.AddToScheme(s))
+	cli := fake.NewClientBuilder().WithScheme(s).WithObjects(live, cm, rt).Build()
+
+	refOf := func(apiVersion, kind, name string) common.ClusterObjectReference {
+		return common.ClusterObjectReference{ObjectReference: corev1.ObjectReference{
+			APIVersion: apiVersion, Kind: kind, Namespace: "default", Name: name}}
+	}
+	track := func(refs ...common.ClusterObjectReference) {
+		r.NoError(cli.Get(ctx, types.NamespacedName{Name: rt.Name}, rt))
+		rt.Status.TrackedResources = nil
+		for _, ref := range refs {
+			rt.Status.TrackedResources = append(rt.Status.TrackedResources, ref.ObjectReference)
+		}
+		r.NoError(cli.Update(ctx, rt))
+	}
+	// the applied resources are outdated and should not be checked
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Status: common.AppStatus{
+			LatestRevision:   &common.Revision{Name: "app-v1"},
+			AppliedResources: []common.ClusterObjectReference{refOf("v1", "Secret", "outdated")},
+		},
+	}
+	reconciler := &Reconciler{Client: cli, Recorder: event.NewNopRecorder()}
+
+	track(refOf("apps/v1", "Deployment", "web"), refOf("v1", "ConfigMap", "config"), refOf("v1", "Secret", "deleted"))
+	r.NoError(reconciler.detectDrift(ctx, app, dispatch.PreserveFieldsOptions{}))
+	r.Equal([]common.DriftedResource{
+		{ClusterObjectReference: refOf("apps/v1", "Deployment", "web"), Fields: []string{"spec.replicas"}},
+		{ClusterObjectReference: refOf("v1", "Secret", "deleted"), Missing: true},
+	}, app.Status.DriftedResources)
+	cond := app.Status.GetCondition(condition.TypeDrifted)
+	r.Equal(corev1.ConditionTrue, cond.Status)
+	r.Contains(cond.Message, "1 resources are not tracked for drift: ConfigMap default/config")
+	got := &appsv1.Deployment{}
+	r.NoError(cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web"}, got))
+	r.Equal(int32(5), *got.Spec.Replicas)
+
+	// the preserved fields are neither reported nor re-applied
+	app.SetAnnotations(map[string]string{oam.AnnotationAutoCorrectDrift: "true"})
+	track(refOf("apps/v1", "Deployment", "web"), refOf("v1", "ConfigMap", "config"))
+	preserved := dispatch.PreserveFieldsOptions{Rules: []dispatch.PreserveFieldsRule{{
+		Selector: dispatch.ResourceSelector{ResourceTypes: []string{"Deployment"}},
+		Paths:    []string{"spec.replicas"},
+	}}}
+	r.NoError(reconciler.detectDrift(ctx, app, preserved))
+	r.Empty(app.Status.DriftedResources)
+	r.NoError(cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web"}, got))
+	r.Equal(int32(5), *got.Spec.Replicas)
+
+	// the drifted resources are re-applied with the auto-correct-drift annotation
+	r.NoError(reconciler.detectDrift(ctx, app, dispatch.PreserveFieldsOptions{}))
+	r.Len(app.Status.DriftedResources, 1)
+	r.NoError(cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web"}, got))
+	r.Equal(int32(2), *got.Spec.Replicas)
+
+	r.NoError(reconciler.detectDrift(ctx, app, dispatch.PreserveFieldsOptions{}))
+	r.Empty(app.Status.DriftedResources)
+	cond = app.Status.GetCondition(condition.TypeDrifted)
+	r.Equal(corev1.ConditionFalse, cond.Status)
+	r.Equal("1 resources are not tracked for drift: ConfigMap default/config", cond.Message)
+
+	track(refOf("apps/v1", "Deployment", "web"))
+	r.NoError(reconciler.detectDrift(ctx, app, dispatch.PreserveFieldsOptions{}))
+	r.Empty(app.Status.GetCondition(condition.TypeDrifted).Message)
+}
@@ -60,33 +60,43 @@ func (h *AppHandler) GenerateApplicationSteps(ctx context.Context,
 	})
 	var tasks []wfTypes.TaskRunner
 	for _, step := range af.WorkflowSteps {
-		options := &wfTypes.GeneratorOptions{
-			ID: generateStepID(step.Name, app.Status.Workflow),
-		}
-		generatorName := step.Type
-		if generatorName == "apply-component" {
-			generatorName = "builtin-apply-component"
-			options.StepConvertor = func(lstep v1beta1.WorkflowStep) (v1beta1.WorkflowStep, error) {
-				copierStep := lstep.DeepCopy()
-				if err := convertStepProperties(copierStep, app); err != nil {
-					return lstep, errors.WithMessage(err, "convert [apply-component]")
-				}
-				return *copierStep, nil
-			}
-		}
-
-		genTask, err := taskDiscover.GetTaskGenerator(ctx, generatorName)
+		task, err := generateTaskRunner(ctx, app, step, taskDiscover, generateStepID(step.Name, app.Status.Workflow))
 		if err != nil {
 			return nil, err
 		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
 
-		task, err := genTask(step, options)
+func generateTaskRunner(ctx context.Context, app *v1beta1.Application, step v1beta1.WorkflowStep, taskDiscover wfTypes.TaskDiscover, id string) (wfTypes.TaskRunner, error) {
+	options := &wfTypes.GeneratorOptions{
+		ID: id,
+	}
+	generatorName := step.Type
+	if generatorName == "apply-component" {
+		generatorName = "builtin-apply-component"
+		options.StepConvertor = func(lstep v1beta1.WorkflowStep) (v1beta1.WorkflowStep, error) {
+			copierStep := lstep.DeepCopy()
+			if err := convertStepProperties(copierStep, app); err != nil {
+				return lstep, errors.WithMessage(err, "convert [apply-component]")
+			}
+			return *copierStep, nil
+		}
+	}
+	for _, subStep := range step.SubSteps {
+		subTask, err := generateTaskRunner(ctx, app, subStep.WorkflowStep(), taskDiscover, generateSubStepID(step.Name, subStep.Name, app.Status.Workflow))
 		if err != nil {
 			return nil, err
 		}
-		tasks = append(tasks, task)
+		options.SubTaskRunners = append(options.SubTaskRunners, subTask)
 	}
-	return tasks, nil
+
+	genTask, err := taskDiscover.GetTaskGenerator(ctx, generatorName)
+	if err != nil {
+		return nil, err
+	}
+	return genTask(step, options)
 }
 
 func convertStepProperties(step *v1beta1.WorkflowStep, app *v1beta1.Application) error {
@@ -264,3 +274,23 @@ func generateStepID(stepName string, wfStatus *common.WorkflowStatus) string {
 	}
 	return id
 }
+
+func generateSubStepID(groupName string, stepName string, wfStatus *common.WorkflowStatus) string {
+	var id string
+	if wfStatus != nil {
+		for _, status := range wfStatus.Steps {
+			if status.Name != groupName || status.SubSteps == nil {
+				continue
+			}
+			for _, subStatus := range status.SubSteps.Steps {
+				if subStatus.Name == stepName {
+					id = subStatus.ID
+				}
+			}
+		}
+	}
+	if id == "" {
+		id = utils.RandomString(10)
+	}
+	return id
+}
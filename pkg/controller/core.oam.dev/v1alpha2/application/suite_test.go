@@ -144,6 +144,7 @@ var _ = BeforeSuite(func(done Done) {
 		pd:               pd,
 		Recorder:         event.NewAPIRecorder(recorder),
 		appRevisionLimit: appRevisionLimit,
+		workflowRunLimit: 10,
 		applicator:       apply.NewAPIApplicator(k8sClient),
 	}
 	// setup the controller manager since we need the component handler to run in the background
@@ -48,14 +48,16 @@ func RolloutWorkloadName(rolloutComp string) assemble.WorkloadOption {
 		}
 
 		// we hard code the behavior depends on the workload group/kind for now. The only in-place upgradable resources
-		// we support is cloneset/statefulset for now. We can easily add more later.
+		// we support is cloneset/advanced statefulset/statefulset/daemonset for now. We can easily add more later.
 		supportInplaceUpgrade := false
 		if w.GroupVersionKind().Group == v1alpha1.GroupVersion.Group {
-			if w.GetKind() == reflect.TypeOf(v1alpha1.CloneSet{}).Name() {
+			if w.GetKind() == reflect.TypeOf(v1alpha1.CloneSet{}).Name() ||
+				w.GetKind() == reflect.TypeOf(v1alpha1.StatefulSet{}).Name() {
 				supportInplaceUpgrade = true
 			}
 		} else if w.GroupVersionKind().Group == appsv1.GroupName {
-			if w.GetKind() == reflect.TypeOf(appsv1.StatefulSet{}).Name() {
+			if w.GetKind() == reflect.TypeOf(appsv1.StatefulSet{}).Name() ||
+				w.GetKind() == reflect.TypeOf(appsv1.DaemonSet{}).Name() {
 				supportInplaceUpgrade = true
 			}
 		}
@@ -85,6 +87,10 @@ func HandleReplicas(ctx context.Context, rolloutComp string, c client.Client) as
 		if compName != rolloutComp {
 			return nil
 		}
+		// DaemonSet has no replicas, its size is decided by the nodes
+		if u.GroupVersionKind().Group == appsv1.GroupName && u.GetKind() == reflect.TypeOf(appsv1.DaemonSet{}).Name() {
+			return nil
+		}
 
 		pv := fieldpath.Pave(u.UnstructuredContent())
 
@@ -124,6 +124,29 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		r.record.Event(&policydefinition, event.Warning("failed to garbage collect DefinitionRevision of type PolicyDefinition", err))
 	}
 
+	def := utils.NewCapabilityPolicyDef(&policydefinition)
+	def.Name = req.NamespacedName.Name
+	// Store the parameter of policyDefinition to configMap
+	cmName, err := def.StoreOpenAPISchema(ctx, r.Client, r.pd, req.Namespace, req.Name, defRev.Name)
+	if err != nil {
+		klog.InfoS("Could not store capability in ConfigMap", "err", err)
+		r.record.Event(&(policydefinition), event.Warning("Could not store capability in ConfigMap", err))
+		return ctrl.Result{}, util.PatchCondition(ctx, r, &policydefinition,
+			condition.ReconcileError(fmt.Errorf(util.ErrStoreCapabilityInConfigMap, policydefinition.Name, err)))
+	}
+
+	if policydefinition.Status.ConfigMapRef != cmName {
+		policydefinition.Status.ConfigMapRef = cmName
+		if err := r.UpdateStatus(ctx, &policydefinition); err != nil {
+			klog.ErrorS(err, "Could not update PolicyDefinition Status", "policyDefinition", klog.KRef(req.Namespace, req.Name))
+			r.record.Event(&policydefinition, event.Warning("Could not update PolicyDefinition Status", err))
+			return ctrl.Result{}, util.PatchCondition(ctx, r, &policydefinition,
+				condition.ReconcileError(fmt.Errorf(util.ErrUpdatePolicyDefinition, policydefinition.Name, err)))
+		}
+		klog.InfoS("Successfully updated the status.configMapRef of the PolicyDefinition", "policyDefinition",
+			klog.KRef(req.Namespace, req.Name), "status.configMapRef", cmName)
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -240,6 +240,38 @@ func TestNewCapabilityComponentDef(t *testing.T) {
 	assert.Equal(t, def.Terraform, terraform)
 }
 
+func TestCapabilityPolicyDefinitionGetOpenAPISchema(t *testing.T) {
+	policyDefinition := &v1beta1.PolicyDefinition{
+		Spec: v1beta1.PolicyDefinitionSpec{
+			Schematic: &common.Schematic{
+				CUE: &common.CUE{
+					Template: `
+output: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	data: replicas: "\(parameter.replicas)"
+}
+parameter: {
+	// +usage=Specify the number of replicas
+	replicas: *1 | int
+	clusters: [...string]
+}`,
+				},
+			},
+		},
+	}
+	def := NewCapabilityPolicyDef(policyDefinition)
+	data, err := def.GetOpenAPISchema(nil, "replicas")
+	assert.NilError(t, err)
+	schema := &openapi3.Schema{}
+	assert.NilError(t, schema.UnmarshalJSON(data))
+	assert.Equal(t, len(schema.Properties), 2)
+	assert.Equal(t, schema.Properties["replicas"].Value.Type, "integer")
+	assert.Equal(t, schema.Properties["replicas"].Value.Description, "Specify the number of replicas")
+	assert.Equal(t, schema.Properties["clusters"].Value.Type, "array")
+	assert.DeepEqual(t, schema.Required, []string{"replicas", "clusters"})
+}
+
 func TestGetOpenAPISchemaFromTerraformComponentDefinition(t *testing.T) {
 	type want struct {
 		subStr string
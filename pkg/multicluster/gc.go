@@ -30,7 +30,8 @@ import (
 	errors2 "github.com/oam-dev/kubevela/pkg/utils/errors"
 )
 
-func getAppliedClusters(app *v1beta1.Application) []string {
+// GetAppliedClusters returns the clusters that the resources of the application are applied to
+func GetAppliedClusters(app *v1beta1.Application) []string {
 	status, err := envbinding.GetEnvBindingPolicyStatus(app, "")
 	appliedClusters := map[string]bool{}
 	if err != nil {
@@ -53,10 +54,16 @@ func getAppliedClusters(app *v1beta1.Application) []string {
 }
 
 // GarbageCollectionForOutdatedResourcesInSubClusters run garbage collection in sub clusters and remove outdated ResourceTrackers with their associated resources
+// A GCInProgressError is returned if the ordered garbage collection of any sub cluster is still in progress and no other errors occur.
 func GarbageCollectionForOutdatedResourcesInSubClusters(ctx context.Context, app *v1beta1.Application, gcHandler func(context.Context) error) error {
 	var errs errors2.ErrorList
-	for _, clusterName := range getAppliedClusters(app) {
+	var inProgress error
+	for _, clusterName := range GetAppliedClusters(app) {
 		if err := gcHandler(ContextWithClusterName(ctx, clusterName)); err != nil {
+			if errors.As(err, &errors2.GCInProgressError{}) {
+				inProgress = err
+				continue
+			}
 			if !errors.As(err, &errors2.ResourceTrackerNotExistError{}) {
 				errs.Append(errors.Wrapf(err, "failed to run gc in subCluster %s", clusterName))
 			}
@@ -65,13 +72,44 @@ func GarbageCollectionForOutdatedResourcesInSubClusters(ctx context.Context, app
 	if errs.HasError() {
 		return errs
 	}
-	return nil
+	return inProgress
+}
+
+// FinalizeResourcesInSubClusters finalizes the resources tracked by the ResourceTrackers of the application in sub clusters
+// before the ResourceTrackers are deleted, so that the gc rules such as keep-on-delete and orphan are applied there as well.
+// The finalize func is called with the context of each sub cluster, it returns false if any sub cluster is not finalized yet.
+func FinalizeResourcesInSubClusters(ctx context.Context, c client.Client, app *v1beta1.Application,
+	finalize func(context.Context, []*v1beta1.ResourceTracker) (bool, error)) (bool, error) {
+	finalized := true
+	for _, cluster := range GetAppliedClusters(app) {
+		subCtx := ContextWithClusterName(ctx, cluster)
+		rtList := &v1beta1.ResourceTrackerList{}
+		if err := c.List(subCtx, rtList, client.MatchingLabels{
+			oam.LabelAppName:      app.Name,
+			oam.LabelAppNamespace: app.Namespace,
+		}); err != nil {
+			return false, errors.Wrapf(err, "failed to list resource trackers in subCluster %s", cluster)
+		}
+		if len(rtList.Items) == 0 {
+			continue
+		}
+		var rts []*v1beta1.ResourceTracker
+		for i := range rtList.Items {
+			rts = append(rts, &rtList.Items[i])
+		}
+		done, err := finalize(subCtx, rts)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to finalize resources in subCluster %s", cluster)
+		}
+		finalized = finalized && done
+	}
+	return finalized, nil
 }
 
 // GarbageCollectionForAllResourceTrackersInSubCluster run garbage collection in sub clusters and remove all ResourceTrackers for the EnvBinding
 func GarbageCollectionForAllResourceTrackersInSubCluster(ctx context.Context, c client.Client, app *v1beta1.Application) error {
 	// delete subCluster resourceTracker
-	for _, cluster := range getAppliedClusters(app) {
+	for _, cluster := range GetAppliedClusters(app) {
 		subCtx := ContextWithClusterName(ctx, cluster)
 		listOpts := []client.ListOption{
 			client.MatchingLabels{
@@ -17,16 +17,20 @@ limitations under the License.
 package multicluster
 
 import (
+	"context"
 	"encoding/json"
 	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
 )
 
 func TestGetAppliedCluster(t *testing.T) {
@@ -39,7 +43,7 @@ func TestGetAppliedCluster(t *testing.T) {
 		Type:   v1alpha1.EnvBindingPolicyType,
 		Status: &runtime.RawExtension{Raw: []byte(`bad value`)},
 	}}
-	clusters := getAppliedClusters(app)
+	clusters := GetAppliedClusters(app)
 	r.Equal(1, len(clusters))
 	r.Equal("cluster-0", clusters[0])
 	envBindingStatus := &v1alpha1.EnvBindingStatus{ClusterConnections: []v1alpha1.ClusterConnection{{
@@ -53,9 +57,40 @@ func TestGetAppliedCluster(t *testing.T) {
 		Type:   v1alpha1.EnvBindingPolicyType,
 		Status: &runtime.RawExtension{Raw: bs},
 	}}
-	clusters = getAppliedClusters(app)
+	clusters = GetAppliedClusters(app)
 	r.Equal(2, len(clusters))
 	sort.Strings(clusters)
 	r.Equal("cluster-1", clusters[0])
 	r.Equal("cluster-2", clusters[1])
 }
+
+func TestFinalizeResourcesInSubClusters(t *testing.T) {
+	r := require.New(t)
+	s := runtime.NewScheme()
+	r.NoError(v1beta1.AddToScheme(s))
+	app := &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+	bs, err := json.Marshal(&v1alpha1.EnvBindingStatus{ClusterConnections: []v1alpha1.ClusterConnection{{
+		ClusterName: "cluster-1",
+	}}})
+	r.NoError(err)
+	app.Status.PolicyStatus = []common.PolicyStatus{{
+		Type:   v1alpha1.EnvBindingPolicyType,
+		Status: &runtime.RawExtension{Raw: bs},
+	}}
+	cli := fake.NewClientBuilder().WithScheme(s).WithObjects(&v1beta1.ResourceTracker{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-v1-default", Labels: map[string]string{
+			oam.LabelAppName:      "app",
+			oam.LabelAppNamespace: "default",
+		}},
+	}).Build()
+
+	var clusters []string
+	finalized, err := FinalizeResourcesInSubClusters(context.Background(), cli, app, func(ctx context.Context, rts []*v1beta1.ResourceTracker) (bool, error) {
+		clusters = append(clusters, ctx.Value(ClusterContextKey).(string))
+		r.Equal(1, len(rts))
+		return false, nil
+	})
+	r.NoError(err)
+	r.False(finalized)
+	r.Equal([]string{"cluster-1"}, clusters)
+}
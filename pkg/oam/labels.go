@@ -64,6 +64,9 @@ const (
 
 	// LabelAddonName indicates the name of the corresponding Addon
 	LabelAddonName = "addons.oam.dev/name"
+
+	// LabelAddonVersion indicates the version of the corresponding Addon
+	LabelAddonVersion = "addons.oam.dev/version"
 )
 
 const (
@@ -126,6 +129,9 @@ const (
 	// AnnotationAddonsName records the name of initializer stored in configMap
 	AnnotationAddonsName = "addons.oam.dev/name"
 
+	// AnnotationAddonDependencies records the names of the addons that the addon depends on, split by comma
+	AnnotationAddonDependencies = "addons.oam.dev/dependencies"
+
 	// AnnotationLastAppliedConfiguration is kubectl annotations for 3-way merge
 	AnnotationLastAppliedConfiguration = "kubectl.kubernetes.io/last-applied-configuration"
 
@@ -149,4 +155,23 @@ const (
 
 	// AnnotationWorkloadName indicates the managed workload's name by trait
 	AnnotationWorkloadName = "trait.oam.dev/workload-name"
+
+	// AnnotationApplyMode is used to tell application how to apply the workload/trait, the value can be
+	// `client-side` (default) or `server-side`
+	AnnotationApplyMode = "app.oam.dev/apply-mode"
+
+	// AnnotationApplyForceConflicts is used to tell application to take over the fields managed by others
+	// when the workload/trait is applied in server-side mode
+	AnnotationApplyForceConflicts = "app.oam.dev/apply-force-conflicts"
+
+	// AnnotationAutoCorrectDrift is used to tell application to re-apply the resources drifted from their
+	// applied configurations when the drift detection is enabled
+	AnnotationAutoCorrectDrift = "app.oam.dev/auto-correct-drift"
+)
+
+const (
+	// ApplyModeClientSide applies the resource by a three-way merge patch computed in client side
+	ApplyModeClientSide = "client-side"
+	// ApplyModeServerSide applies the resource by the server-side apply
+	ApplyModeServerSide = "server-side"
 )
@@ -333,13 +333,27 @@ func GetDefinition(ctx context.Context, cli client.Reader, definition client.Obj
 // GetCapabilityDefinition can get different versions of ComponentDefinition/TraitDefinition
 func GetCapabilityDefinition(ctx context.Context, cli client.Reader, definition client.Object,
 	definitionName string) error {
-	isLatestRevision, defRev, err := fetchDefinitionRev(ctx, cli, definitionName)
+	// if the component's type doesn't contain '@' means user want to use the latest Definition.
+	if !IsDefinitionRevisionRef(definitionName) {
+		return GetDefinition(ctx, cli, definition, definitionName)
+	}
+	var defType common.DefinitionType
+	switch definition.(type) {
+	case *v1beta1.ComponentDefinition:
+		defType = common.ComponentType
+	case *v1beta1.TraitDefinition:
+		defType = common.TraitType
+	case *v1beta1.PolicyDefinition:
+		defType = common.PolicyType
+	case *v1beta1.WorkflowStepDefinition:
+		defType = common.WorkflowStepType
+	default:
+		return errors.Errorf("definition revision is not supported by %T", definition)
+	}
+	defRev, err := GetDefinitionRevisionByRef(ctx, cli, definitionName, defType)
 	if err != nil {
 		return err
 	}
-	if isLatestRevision {
-		return GetDefinition(ctx, cli, definition, definitionName)
-	}
 	switch def := definition.(type) {
 	case *v1beta1.ComponentDefinition:
 		*def = defRev.Spec.ComponentDefinition
@@ -354,21 +368,28 @@ func GetCapabilityDefinition(ctx context.Context, cli client.Reader, definition
 	return nil
 }
 
-func fetchDefinitionRev(ctx context.Context, cli client.Reader, definitionName string) (bool, *v1beta1.DefinitionRevision, error) {
-	// if the component's type doesn't contain '@' means user want to use the latest Definition.
-	if !strings.Contains(definitionName, "@") {
-		return true, nil, nil
-	}
+// IsDefinitionRevisionRef checks if the definition name refers to a specific revision, e.g., worker@v2
+func IsDefinitionRevisionRef(definitionName string) bool {
+	return strings.Contains(definitionName, "@")
+}
 
+// GetDefinitionRevisionByRef gets the DefinitionRevision referred by the definition name in the form of `type@revision`,
+// e.g., worker@v2 refers to the DefinitionRevision worker-v2. An error is returned if the revision is not a revision of
+// the given definition type.
+func GetDefinitionRevisionByRef(ctx context.Context, cli client.Reader, definitionName string, defType common.DefinitionType) (*v1beta1.DefinitionRevision, error) {
 	defRevName, err := ConvertDefinitionRevName(definitionName)
 	if err != nil {
-		return false, nil, err
+		return nil, err
 	}
 	defRev := new(v1beta1.DefinitionRevision)
 	if err := GetDefinition(ctx, cli, defRev, defRevName); err != nil {
-		return false, nil, err
+		return nil, err
+	}
+	if defRev.Spec.DefinitionType != defType {
+		return nil, errors.Errorf("%s refers to a revision of %s rather than %s", definitionName,
+			defRev.Spec.DefinitionType, defType)
 	}
-	return false, defRev, nil
+	return defRev, nil
 }
 
 // ConvertDefinitionRevName can help convert definition type defined in Application to DefinitionRevision Name
@@ -2001,3 +2001,38 @@ func TestConvertDefinitionRevName(t *testing.T) {
 		}
 	}
 }
+
+func TestGetCapabilityDefinitionByRevisionRef(t *testing.T) {
+	workerRev := v1beta1.DefinitionRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-v2", Namespace: "vela-system"},
+		Spec: v1beta1.DefinitionRevisionSpec{
+			Revision:       2,
+			DefinitionType: common.ComponentType,
+			ComponentDefinition: v1beta1.ComponentDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+				Spec:       v1beta1.ComponentDefinitionSpec{Schematic: &common.Schematic{CUE: &common.CUE{Template: "output: {}"}}},
+			},
+		},
+	}
+	cli := test.MockClient{
+		MockGet: func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+			if rev, ok := obj.(*v1beta1.DefinitionRevision); ok && key.Name == workerRev.Name && key.Namespace == workerRev.Namespace {
+				workerRev.DeepCopyInto(rev)
+				return nil
+			}
+			return apierrors.NewNotFound(schema.GroupResource{Group: v1beta1.Group, Resource: "definitionrevisions"}, key.Name)
+		},
+	}
+	ctx := util.SetNamespaceInCtx(context.Background(), "default")
+
+	compDef := new(v1beta1.ComponentDefinition)
+	assert.NoError(t, util.GetCapabilityDefinition(ctx, &cli, compDef, "worker@v2"))
+	assert.Equal(t, "worker", compDef.Name)
+	assert.Equal(t, "output: {}", compDef.Spec.Schematic.CUE.Template)
+
+	err := util.GetCapabilityDefinition(ctx, &cli, new(v1beta1.ComponentDefinition), "worker@v3")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	err = util.GetCapabilityDefinition(ctx, &cli, new(v1beta1.TraitDefinition), "worker@v2")
+	assert.EqualError(t, err, "worker@v2 refers to a revision of Component rather than Trait")
+}
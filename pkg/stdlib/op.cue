@@ -135,6 +135,8 @@ import (
 
 #SendEmail: email.#Send
 
+#Notify: notification.#Notify
+
 #Load: oam.#LoadComponets
 
 #LoadInOrder: oam.#LoadComponetsInOrder
@@ -20,11 +20,24 @@ import (
 	"embed"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
 )
 
+// BuiltinProvider is the provider of the operations without `#provider`
+const BuiltinProvider = "builtin"
+
+// Op is an operation declared in the stdlib packages by `#do` and `#provider`
+type Op struct {
+	Provider string
+	Do       string
+}
+
 var (
 	//go:embed pkgs op.cue ql.cue
 	fs embed.FS
@@ -86,3 +99,58 @@ func AddImportsFor(inst *build.Instance, tagTempl string) error {
 	}
 	return nil
 }
+
+// GetOps returns all the operations declared in the stdlib packages
+func GetOps() (map[Op]bool, error) {
+	files, err := fs.ReadDir("pkgs")
+	if err != nil {
+		return nil, err
+	}
+	names := []string{"op.cue", "ql.cue"}
+	for _, file := range files {
+		names = append(names, "pkgs/"+file.Name())
+	}
+	ops := map[Op]bool{}
+	for _, name := range names {
+		body, err := fs.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(name, body)
+		if err != nil {
+			return nil, err
+		}
+		ast.Walk(f, func(node ast.Node) bool {
+			if st, ok := node.(*ast.StructLit); ok {
+				if do := stringField(st, "#do"); do != "" {
+					provider := stringField(st, "#provider")
+					if provider == "" {
+						provider = BuiltinProvider
+					}
+					ops[Op{Provider: provider, Do: do}] = true
+				}
+			}
+			return true
+		}, nil)
+	}
+	return ops, nil
+}
+
+// stringField returns the value of the field in the struct if it's a string literal
+func stringField(st *ast.StructLit, label string) string {
+	for _, elt := range st.Elts {
+		field, ok := elt.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, _ := ast.LabelName(field.Label); name != label {
+			continue
+		}
+		if lit, ok := field.Value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if str, err := strconv.Unquote(lit.Value); err == nil {
+				return str
+			}
+		}
+	}
+	return ""
+}
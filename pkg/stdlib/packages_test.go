@@ -47,3 +47,18 @@ out: op.context`)
 	assert.NilError(t, err)
 	assert.Equal(t, str, "xxx")
 }
+
+func TestGetOps(t *testing.T) {
+	ops, err := GetOps()
+	assert.NilError(t, err)
+	for _, op := range []Op{
+		{Provider: "kube", Do: "apply"},
+		{Provider: "oam", Do: "component-apply"},
+		{Provider: BuiltinProvider, Do: "steps"},
+		{Provider: BuiltinProvider, Do: "wait"},
+		{Provider: BuiltinProvider, Do: "load"},
+	} {
+		assert.Assert(t, ops[op], "op %v not found", op)
+	}
+	assert.Assert(t, !ops[Op{Provider: "kube", Do: "unknown"}])
+}
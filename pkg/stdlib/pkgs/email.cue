@@ -5,7 +5,7 @@
 	from: {
 		address:  string
 		alias?:   string
-		password: string
+		password: secret.#Value
 		host:     string
 		port:     int
 	}
@@ -3,10 +3,10 @@
 	#provider: "http"
 
 	method: *"GET" | "POST" | "PUT" | "DELETE"
-	url:    string
+	url:    secret.#Value
 	request?: {
 		body: string
-		header: [string]:  string
+		header: [string]:  secret.#Value
 		trailer: [string]: string
 	}
 	response: {
@@ -0,0 +1,12 @@
+#Notify: {
+	#do:       "notify"
+	#provider: "notification"
+
+	// +usage=The type of the webhook, can be slack, dingding, lark or webhook
+	type: *"webhook" | "slack" | "dingding" | "lark"
+	// +usage=Specify the url of the webhook directly or in a secret of the application namespace
+	url: secret.#Value
+	// +usage=The message sent to the webhook, the string fields can be go templates rendered with the workflow context
+	message: {...}
+	...
+}
@@ -0,0 +1,12 @@
+// +usage=A credential given in plain text, or referred from a secret in the application namespace which is resolved when the step runs
+#Value: string | {
+	value?:     string
+	secretRef?: #Ref
+}
+
+#Ref: {
+	name: string
+	key:  string
+	// +usage=The cluster where the secret locates, default to the control plane
+	cluster?: string
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/oam-dev/kubevela/pkg/oam"
 
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/pkg/errors"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -43,12 +44,18 @@ const (
 // computing a three-way diff merge in client side based on its current state, modified stated,
 // and last-applied-state which is tracked through an specific annotation.
 // If the resource doesn't exist before, Apply will create it.
+// The server-side apply is used instead if the ServerSideApply option is given.
 type Applicator interface {
 	Apply(context.Context, client.Object, ...ApplyOption) error
 }
 
 type applyAction struct {
 	skipUpdate bool
+
+	// serverSideApply and its options are set by ServerSideApply
+	serverSideApply bool
+	fieldManager    string
+	forceConflicts  bool
 }
 
 // ApplyOption is called before applying state to the object.
@@ -129,6 +136,11 @@ func (a *APIApplicator) Apply(ctx context.Context, desired client.Object, ao ...
 		return nil
 	}
 
+	if applyAct.serverSideApply {
+		loggingApply("server-side applying object", desired)
+		return serverSideApply(ctx, a.c, applyAct, desired)
+	}
+
 	loggingApply("patching object", desired)
 	patch, err := a.patcher.patch(existing, desired)
 	if err != nil {
@@ -167,6 +179,10 @@ func createOrGetExisting(ctx context.Context, act *applyAction, c client.Client,
 		if err := executeApplyOptions(act, nil, desired, ao); err != nil {
 			return nil, err
 		}
+		if act.serverSideApply {
+			loggingApply("server-side applying object", desired)
+			return nil, serverSideApply(ctx, c, act, desired)
+		}
 		if err := addLastAppliedConfigAnnotation(desired); err != nil {
 			return nil, err
 		}
@@ -191,6 +207,23 @@ func createOrGetExisting(ctx context.Context, act *applyAction, c client.Client,
 	return existing, nil
 }
 
+// serverSideApply applies the desired object with the field manager of the action, the fields managed by
+// others can only be changed when conflicts are forced
+func serverSideApply(ctx context.Context, c client.Client, act *applyAction, desired client.Object) error {
+	// managedFields must be empty in an apply request
+	desired.SetManagedFields(nil)
+	opts := []client.PatchOption{client.FieldOwner(act.fieldManager)}
+	if act.forceConflicts {
+		opts = append(opts, client.ForceOwnership)
+	}
+	err := c.Patch(ctx, desired, client.Apply, opts...)
+	if kerrors.IsConflict(err) {
+		return errors.Wrapf(err, "cannot apply object in server-side mode as fields are managed by others, "+
+			"set annotation %s to take over them", oam.AnnotationApplyForceConflicts)
+	}
+	return errors.Wrap(err, "cannot apply object in server-side mode")
+}
+
 func executeApplyOptions(act *applyAction, existing, desired client.Object, aos []ApplyOption) error {
 	// if existing is nil, it means the object is going to be created.
 	// ApplyOption function should handle this situation carefully by itself.
@@ -279,3 +312,51 @@ func MakeCustomApplyOption(f func(existing, desired client.Object) error) ApplyO
 		return f(existing, desired)
 	}
 }
+
+// ServerSideApply applies the object by the server-side apply with the given field manager instead of
+// the three-way merge patch. If forceConflicts is true, the fields managed by others are taken over.
+func ServerSideApply(fieldManager string, forceConflicts bool) ApplyOption {
+	return func(act *applyAction, _, _ client.Object) error {
+		if fieldManager == "" {
+			return errors.New("field manager is required by server-side apply")
+		}
+		act.serverSideApply = true
+		act.fieldManager = fieldManager
+		act.forceConflicts = forceConflicts
+		return nil
+	}
+}
+
+// PreserveFields keeps the values of the fields in the given paths from the existing object, so that the fields
+// managed by others (e.g. the replicas scaled by HPA) won't be reset by applying. A path that doesn't exist in the
+// existing object is applied with the desired value.
+// The paths are in the format of fieldpath, e.g. `spec.replicas` or `metadata.annotations[sidecar.istio.io/status]`.
+func PreserveFields(paths []string) ApplyOption {
+	return func(_ *applyAction, existing, desired client.Object) error {
+		if existing == nil || desired == nil || len(paths) == 0 {
+			return nil
+		}
+		desiredObj, ok := desired.(*unstructured.Unstructured)
+		if !ok {
+			return nil
+		}
+		existingPaved, err := fieldpath.PaveObject(existing)
+		if err != nil {
+			return errors.Wrap(err, "cannot pave existing object")
+		}
+		desiredPaved := fieldpath.Pave(desiredObj.Object)
+		for _, path := range paths {
+			value, err := existingPaved.GetValue(path)
+			if fieldpath.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return errors.Wrapf(err, "cannot get field %q of existing object", path)
+			}
+			if err := desiredPaved.SetValue(path, value); err != nil {
+				return errors.Wrapf(err, "cannot preserve field %q", path)
+			}
+		}
+		return nil
+	}
+}
@@ -427,3 +427,146 @@ func TestMustBeControllableByAny(t *testing.T) {
 		})
 	}
 }
+
+func TestServerSideApply(t *testing.T) {
+	notFound := kerrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "desired")
+	conflict := kerrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "desired",
+		errors.New("conflict with \"kube-controller-manager\": .spec.replicas"))
+	cases := map[string]struct {
+		reason        string
+		getErr        error
+		patchErr      error
+		ao            []ApplyOption
+		wantForce     bool
+		wantPatchType types.PatchType
+		want          error
+	}{
+		"CreateByServerSideApply": {
+			reason:        "The object should be created by server-side apply if it does not exist",
+			getErr:        notFound,
+			ao:            []ApplyOption{ServerSideApply("test-manager", false)},
+			wantPatchType: types.ApplyPatchType,
+		},
+		"UpdateByServerSideApply": {
+			reason:        "The existing object should be updated by server-side apply",
+			ao:            []ApplyOption{ServerSideApply("test-manager", true)},
+			wantForce:     true,
+			wantPatchType: types.ApplyPatchType,
+		},
+		"SkipUpdateByServerSideApply": {
+			reason: "The existing object should not be applied if it's not changed",
+			ao:     []ApplyOption{ServerSideApply("test-manager", false), NotUpdateRenderHashEqual()},
+		},
+		"ConflictError": {
+			reason:        "A conflict error should be returned with the hint of force conflicts annotation",
+			patchErr:      conflict,
+			ao:            []ApplyOption{ServerSideApply("test-manager", false)},
+			wantPatchType: types.ApplyPatchType,
+			want: errors.Wrapf(conflict, "cannot apply object in server-side mode as fields are managed by others, "+
+				"set annotation %s to take over them", oam.AnnotationApplyForceConflicts),
+		},
+		"NoFieldManager": {
+			reason: "An error should be returned if the field manager is not set",
+			ao:     []ApplyOption{ServerSideApply("", false)},
+			want:   errors.Wrap(errors.New("field manager is required by server-side apply"), "cannot apply ApplyOption"),
+		},
+	}
+	for caseName, tc := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			desired := &unstructured.Unstructured{}
+			desired.SetAPIVersion("apps/v1")
+			desired.SetKind("Deployment")
+			desired.SetName("desired")
+			desired.SetNamespace("default")
+			desired.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "test-manager"}})
+			existing := desired.DeepCopy()
+			_, err := generateRenderHash(existing)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var gotPatchType types.PatchType
+			var gotOpts client.PatchOptions
+			c := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					if tc.getErr != nil {
+						return tc.getErr
+					}
+					existing.DeepCopyInto(obj.(*unstructured.Unstructured))
+					return nil
+				},
+				MockCreate: test.NewMockCreateFn(errors.New("object should not be created")),
+				MockPatch: func(_ context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					gotPatchType = patch.Type()
+					gotOpts.ApplyOptions(opts)
+					if len(obj.GetManagedFields()) != 0 {
+						return errors.New("managed fields should be empty")
+					}
+					if _, ok := obj.GetAnnotations()[oam.AnnotationLastAppliedConfig]; ok {
+						return errors.New("last applied configuration should not be recorded")
+					}
+					return tc.patchErr
+				},
+			}
+			err = NewAPIApplicator(c).Apply(ctx, desired, tc.ao...)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want error, +got error\n%s\n", tc.reason, diff)
+			}
+			if gotPatchType != tc.wantPatchType {
+				t.Errorf("\n%s\nApply(...): want patch type %q, got %q\n", tc.reason, tc.wantPatchType, gotPatchType)
+			}
+			if tc.wantPatchType == types.ApplyPatchType {
+				if gotOpts.FieldManager != "test-manager" {
+					t.Errorf("\n%s\nApply(...): want field manager test-manager, got %q\n", tc.reason, gotOpts.FieldManager)
+				}
+				if gotForce := gotOpts.Force != nil && *gotOpts.Force; gotForce != tc.wantForce {
+					t.Errorf("\n%s\nApply(...): want force %v, got %v\n", tc.reason, tc.wantForce, gotForce)
+				}
+			}
+		})
+	}
+}
+
+func TestPreserveFields(t *testing.T) {
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":        "test",
+			"annotations": map[string]interface{}{"sidecar.istio.io/status": "injected"},
+		},
+		"spec": map[string]interface{}{"replicas": int64(5)},
+	}}
+	newDesired := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "test"},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+				"paused":   false,
+			},
+		}}
+	}
+	paths := []string{"spec.replicas", "metadata.annotations[sidecar.istio.io/status]", "spec.paused"}
+
+	desired := newDesired()
+	if err := PreserveFields(paths)(&applyAction{}, existing, desired); err != nil {
+		t.Fatal(err)
+	}
+	want := newDesired()
+	want.Object["spec"].(map[string]interface{})["replicas"] = int64(5)
+	want.SetAnnotations(map[string]string{"sidecar.istio.io/status": "injected"})
+	if diff := cmp.Diff(want, desired); diff != "" {
+		t.Errorf("PreserveFields(...): -want, +got\n%s\n", diff)
+	}
+
+	// the desired values are applied on creation
+	desired = newDesired()
+	if err := PreserveFields(paths)(&applyAction{}, nil, desired); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(newDesired(), desired); diff != "" {
+		t.Errorf("PreserveFields(...): -want, +got\n%s\n", diff)
+	}
+
+	if err := PreserveFields([]string{"spec..replicas"})(&applyAction{}, existing, newDesired()); err == nil {
+		t.Errorf("PreserveFields(...): want error for invalid path, got nil")
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DetectDrift compares the live object with the last applied configuration recorded in its annotation, it returns
+// the paths of the applied fields whose live values differ. Fields set by others, such as the defaults and the status,
+// are not regarded as drifted, neither are the fields under the preserved paths, see PreserveFields.
+// The object without the annotation, e.g. applied in server-side mode, is not tracked.
+func DetectDrift(live *unstructured.Unstructured, preservedPaths []string) (fields []string, tracked bool, err error) {
+	original, err := getOriginalConfiguration(live)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(original) == 0 {
+		return nil, false, nil
+	}
+	desired := map[string]interface{}{}
+	if err := json.Unmarshal(original, &desired); err != nil {
+		return nil, true, errors.Wrap(err, "cannot unmarshal the last applied configuration")
+	}
+	// normalize the live object to the same json types as the desired one
+	bs, err := json.Marshal(live.Object)
+	if err != nil {
+		return nil, true, err
+	}
+	current := map[string]interface{}{}
+	if err := json.Unmarshal(bs, &current); err != nil {
+		return nil, true, err
+	}
+
+	for key, value := range desired {
+		switch key {
+		case "status":
+			continue
+		case "metadata":
+			desiredMeta, _ := value.(map[string]interface{})
+			currentMeta, _ := current["metadata"].(map[string]interface{})
+			for _, metaKey := range []string{"labels", "annotations"} {
+				if v, ok := desiredMeta[metaKey]; ok {
+					fields = compareFields(fields, "metadata."+metaKey, v, currentMeta[metaKey])
+				}
+			}
+		default:
+			fields = compareFields(fields, key, value, current[key])
+		}
+	}
+	var drifted []string
+	for _, field := range fields {
+		if !isUnderPaths(field, preservedPaths) {
+			drifted = append(drifted, field)
+		}
+	}
+	sort.Strings(drifted)
+	return drifted, true, nil
+}
+
+// isUnderPaths checks if the field path equals to or is nested in any of the paths
+func isUnderPaths(field string, paths []string) bool {
+	for _, p := range paths {
+		if field == p || strings.HasPrefix(field, p+".") || strings.HasPrefix(field, p+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// compareFields appends the paths under the given path where the desired value is not contained in the current one
+func compareFields(fields []string, path string, desired, current interface{}) []string {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			return append(fields, path)
+		}
+		for key, value := range d {
+			fields = compareFields(fields, fieldPathOf(path, key), value, c[key])
+		}
+		return fields
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok || len(c) != len(d) {
+			return append(fields, path)
+		}
+		for i := range d {
+			fields = compareFields(fields, fmt.Sprintf("%s[%d]", path, i), d[i], c[i])
+		}
+		return fields
+	default:
+		if !reflect.DeepEqual(desired, current) {
+			return append(fields, path)
+		}
+		return fields
+	}
+}
+
+func fieldPathOf(parent, key string) string {
+	if strings.ContainsAny(key, "./[]") {
+		return fmt.Sprintf("%s[%s]", parent, key)
+	}
+	return parent + "." + key
+}
+
+// CorrectDrift patches the live object back to the last applied configuration recorded in its annotation,
+// the fields under the preserved paths keep their live values
+func CorrectDrift(ctx context.Context, c client.Client, live *unstructured.Unstructured, preservedPaths []string) error {
+	original, err := getOriginalConfiguration(live)
+	if err != nil {
+		return err
+	}
+	if len(original) == 0 {
+		return errors.Errorf("no last applied configuration found in %s %s", live.GetKind(), live.GetName())
+	}
+	desired := &unstructured.Unstructured{}
+	if err := json.Unmarshal(original, &desired.Object); err != nil {
+		return errors.Wrap(err, "cannot unmarshal the last applied configuration")
+	}
+	if err := PreserveFields(preservedPaths)(nil, live, desired); err != nil {
+		return err
+	}
+	patch, err := threeWayMergePatch(live, desired)
+	if err != nil {
+		return errors.Wrap(err, "cannot calculate patch by computing a three way diff")
+	}
+	loggingApply("correcting drifted object", desired)
+	return errors.Wrapf(c.Patch(ctx, desired, patch), "cannot patch object")
+}
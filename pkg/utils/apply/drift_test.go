@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newDriftTestDeployment() *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "web",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app.oam.dev/name": "app"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "nginx:1.20"},
+					},
+				},
+			},
+		},
+	}}
+	if err := addLastAppliedConfigAnnotation(u); err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func TestDetectDrift(t *testing.T) {
+	cases := map[string]struct {
+		reason         string
+		mutate         func(live *unstructured.Unstructured)
+		preservedPaths []string
+		wantFields     []string
+		wantTracked    bool
+	}{
+		"NoDrift": {
+			reason: "Fields added by others should not be regarded as drifted",
+			mutate: func(live *unstructured.Unstructured) {
+				_ = unstructured.SetNestedField(live.Object, "Always", "spec", "template", "spec", "restartPolicy")
+				_ = unstructured.SetNestedField(live.Object, int64(2), "status", "replicas")
+				live.SetResourceVersion("100")
+			},
+			wantTracked: true,
+		},
+		"Drifted": {
+			reason: "Changed, removed and reordered fields should be reported",
+			mutate: func(live *unstructured.Unstructured) {
+				_ = unstructured.SetNestedField(live.Object, int64(3), "spec", "replicas")
+				_ = unstructured.SetNestedSlice(live.Object, []interface{}{
+					map[string]interface{}{"name": "web", "image": "nginx:latest"},
+				}, "spec", "template", "spec", "containers")
+				labels := live.GetLabels()
+				delete(labels, "app.oam.dev/name")
+				live.SetLabels(labels)
+			},
+			wantFields: []string{
+				"metadata.labels[app.oam.dev/name]",
+				"spec.replicas",
+				"spec.template.spec.containers[0].image",
+			},
+			wantTracked: true,
+		},
+		"Preserved": {
+			reason: "Fields under the preserved paths should not be regarded as drifted",
+			mutate: func(live *unstructured.Unstructured) {
+				_ = unstructured.SetNestedField(live.Object, int64(3), "spec", "replicas")
+				_ = unstructured.SetNestedSlice(live.Object, []interface{}{
+					map[string]interface{}{"name": "web", "image": "nginx:latest"},
+				}, "spec", "template", "spec", "containers")
+			},
+			preservedPaths: []string{"spec.replicas", "spec.template.spec.containers"},
+			wantTracked:    true,
+		},
+		"NotTracked": {
+			reason: "Object without the last applied configuration should not be tracked",
+			mutate: func(live *unstructured.Unstructured) {
+				live.SetAnnotations(nil)
+			},
+		},
+	}
+	for caseName, tc := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			live := newDriftTestDeployment()
+			tc.mutate(live)
+			fields, tracked, err := DetectDrift(live, tc.preservedPaths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tracked != tc.wantTracked {
+				t.Errorf("\n%s\nDetectDrift(...): want tracked %v, got %v\n", tc.reason, tc.wantTracked, tracked)
+			}
+			if diff := cmp.Diff(tc.wantFields, fields); diff != "" {
+				t.Errorf("\n%s\nDetectDrift(...): -want fields, +got fields\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCorrectDrift(t *testing.T) {
+	live := newDriftTestDeployment()
+	_ = unstructured.SetNestedField(live.Object, int64(3), "spec", "replicas")
+
+	var gotPatch map[string]interface{}
+	c := &test.MockClient{
+		MockPatch: func(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+			if patch.Type() != types.StrategicMergePatchType {
+				return errors.Errorf("unexpected patch type %s", patch.Type())
+			}
+			data, err := patch.Data(obj)
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal(data, &gotPatch)
+		},
+	}
+	if err := CorrectDrift(context.Background(), c, live, nil); err != nil {
+		t.Fatal(err)
+	}
+	replicas, _, _ := unstructured.NestedFieldNoCopy(gotPatch, "spec", "replicas")
+	if diff := cmp.Diff(float64(2), replicas); diff != "" {
+		t.Errorf("CorrectDrift(...): -want replicas, +got replicas\n%s\n", diff)
+	}
+
+	gotPatch = nil
+	if err := CorrectDrift(context.Background(), c, live, []string{"spec.replicas"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(gotPatch, "spec", "replicas"); found {
+		t.Errorf("CorrectDrift(...): want the preserved replicas not reverted, got patch %v", gotPatch)
+	}
+
+	live.SetAnnotations(nil)
+	if err := CorrectDrift(context.Background(), c, live, nil); err == nil {
+		t.Errorf("CorrectDrift(...): want error for the object without last applied configuration")
+	}
+}
@@ -29,3 +29,14 @@ type ResourceTrackerNotExistError struct {
 func (err ResourceTrackerNotExistError) Error() string {
 	return fmt.Sprintf("given resource tracker %q doesn't exist", err.Name)
 }
+
+// GCInProgressError identifies the ordered garbage collection of a resourcetracker which is waiting for the resources
+// of smaller orders to be deleted
+type GCInProgressError struct {
+	Name string
+}
+
+// Error implement error interface
+func (err GCInProgressError) Error() string {
+	return fmt.Sprintf("resources tracked by resource tracker %q are being deleted in order", err.Name)
+}
@@ -94,3 +94,12 @@ func (c ViewContext) MakeParameter(parameter interface{}) (*value.Value, error)
 func (c ViewContext) StoreRef() *corev1.ObjectReference {
 	return nil
 }
+
+// GetStepDebugInfo get the debug info of the step.
+func (c ViewContext) GetStepDebugInfo(name string) *wfContext.StepDebugInfo {
+	return nil
+}
+
+// SetStepDebugInfo set the debug info of the step.
+func (c ViewContext) SetStepDebugInfo(name string, info *wfContext.StepDebugInfo) {
+}
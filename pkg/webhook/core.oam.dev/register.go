@@ -26,7 +26,9 @@ import (
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/applicationrollout"
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/component"
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/componentdefinition"
+	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/policydefinition"
 	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/traitdefinition"
+	"github.com/oam-dev/kubevela/pkg/webhook/core.oam.dev/v1alpha2/workflowstepdefinition"
 )
 
 // Register will be called in main and register all validation handlers
@@ -37,6 +39,8 @@ func Register(mgr manager.Manager, args controller.Args) {
 		componentdefinition.RegisterMutatingHandler(mgr, args)
 		componentdefinition.RegisterValidatingHandler(mgr, args)
 		traitdefinition.RegisterValidatingHandler(mgr, args)
+		policydefinition.RegisterValidatingHandler(mgr, args)
+		workflowstepdefinition.RegisterValidatingHandler(mgr, args)
 		applicationrollout.RegisterMutatingHandler(mgr)
 		applicationrollout.RegisterValidatingHandler(mgr)
 		applicationconfiguration.RegisterMutatingHandler(mgr)
@@ -48,11 +52,15 @@ func Register(mgr manager.Manager, args controller.Args) {
 		componentdefinition.RegisterMutatingHandler(mgr, args)
 		componentdefinition.RegisterValidatingHandler(mgr, args)
 		traitdefinition.RegisterValidatingHandler(mgr, args)
+		policydefinition.RegisterValidatingHandler(mgr, args)
+		workflowstepdefinition.RegisterValidatingHandler(mgr, args)
 	case "v0.3":
 		application.RegisterValidatingHandler(mgr, args)
 		componentdefinition.RegisterMutatingHandler(mgr, args)
 		componentdefinition.RegisterValidatingHandler(mgr, args)
 		traitdefinition.RegisterValidatingHandler(mgr, args)
+		policydefinition.RegisterValidatingHandler(mgr, args)
+		workflowstepdefinition.RegisterValidatingHandler(mgr, args)
 		applicationrollout.RegisterMutatingHandler(mgr)
 		applicationrollout.RegisterValidatingHandler(mgr)
 	case "v0.2":
@@ -18,23 +18,56 @@ package application
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/pkg/webhook/common/rollout"
+	"github.com/oam-dev/kubevela/pkg/workflow/tasks/template"
+)
+
+// builtinStepTypes are the workflow step types handled by the workflow engine without WorkflowStepDefinition
+var builtinStepTypes = map[string]bool{
+	"suspend":    true,
+	"step-group": true,
+}
+
+const (
+	// applyComponentStepType is the step type which applies a component of the application, the step is run by
+	// the builtin-apply-component template with the inputs, outputs and dependsOn of the component merged
+	applyComponentStepType     = "apply-component"
+	applyComponentStepTemplate = "builtin-apply-component"
 )
 
 // ValidateCreate validates the Application on creation
 func (h *ValidatingHandler) ValidateCreate(ctx context.Context, app *v1beta1.Application) field.ErrorList {
-	var componentErrs field.ErrorList
+	if componentErrs := h.validateDefinitionRevisions(ctx, app); len(componentErrs) != 0 {
+		// the definitions can't be loaded, no need to validate further
+		return componentErrs
+	}
+	componentErrs := h.validateWorkflow(ctx, app)
+	if policyErrs := h.validatePolicies(ctx, app); len(policyErrs) != 0 {
+		// the policies can't be loaded, no need to generate the app file
+		return append(componentErrs, policyErrs...)
+	}
 	// try to generate an app file
 	appParser := appfile.NewApplicationParser(h.Client, h.dm, h.pd)
 
@@ -50,6 +83,7 @@ func (h *ValidatingHandler) ValidateCreate(ctx context.Context, app *v1beta1.App
 	if err := appParser.ValidateCUESchematicAppfile(af); err != nil {
 		componentErrs = append(componentErrs, field.Invalid(field.NewPath("schematic"), app, err.Error()))
 	}
+	componentErrs = append(componentErrs, h.validateParameterSchemas(ctx, app)...)
 	if v := app.GetAnnotations()[oam.AnnotationAppRollout]; len(v) != 0 && v != "true" {
 		componentErrs = append(componentErrs, field.Invalid(field.NewPath("annotation:app.oam.dev/rollout-template"), app, "the annotation value of rollout-template must be true"))
 	}
@@ -64,10 +98,452 @@ func (h *ValidatingHandler) ValidateCreate(ctx context.Context, app *v1beta1.App
 func (h *ValidatingHandler) ValidateUpdate(ctx context.Context, newApp, oldApp *v1beta1.Application) field.ErrorList {
 	// check if the newApp is valid
 	componentErrs := h.ValidateCreate(ctx, newApp)
-	// TODO: add more validating
 	return componentErrs
 }
 
+// validateDefinitionRevisions validates the definitions referred in the form of `type@revision`, e.g., worker@v2,
+// the DefinitionRevision must exist and be a revision of the definition type
+func (h *ValidatingHandler) validateDefinitionRevisions(ctx context.Context, app *v1beta1.Application) field.ErrorList {
+	var errs field.ErrorList
+	validate := func(path *field.Path, definitionName string, defType common.DefinitionType) {
+		if !util.IsDefinitionRevisionRef(definitionName) {
+			return
+		}
+		if _, err := util.GetDefinitionRevisionByRef(ctx, h.Client, definitionName, defType); err != nil {
+			if apierrors.IsNotFound(err) {
+				errs = append(errs, field.NotFound(path, definitionName))
+				return
+			}
+			errs = append(errs, field.Invalid(path, definitionName, err.Error()))
+		}
+	}
+	for i, comp := range app.Spec.Components {
+		compPath := field.NewPath("spec", "components").Index(i)
+		validate(compPath.Child("type"), comp.Type, common.ComponentType)
+		for j, trait := range comp.Traits {
+			validate(compPath.Child("traits").Index(j).Child("type"), trait.Type, common.TraitType)
+		}
+	}
+	for i, policy := range app.Spec.Policies {
+		validate(field.NewPath("spec", "policies").Index(i).Child("type"), policy.Type, common.PolicyType)
+	}
+	if app.Spec.Workflow != nil {
+		for i, step := range app.Spec.Workflow.Steps {
+			stepPath := field.NewPath("spec", "workflow", "steps").Index(i)
+			validate(stepPath.Child("type"), step.Type, common.WorkflowStepType)
+			for j, subStep := range step.SubSteps {
+				validate(stepPath.Child("subSteps").Index(j).Child("type"), subStep.Type, common.WorkflowStepType)
+			}
+		}
+	}
+	return errs
+}
+
+// validatePolicies validates the types of the policies exist, built-in policies are handled without PolicyDefinition
+func (h *ValidatingHandler) validatePolicies(ctx context.Context, app *v1beta1.Application) field.ErrorList {
+	var errs field.ErrorList
+	for i, policy := range app.Spec.Policies {
+		if policy.Type == v1alpha1.GarbageCollectPolicyType || policy.Type == v1alpha1.PreserveFieldsPolicyType {
+			continue
+		}
+		path := field.NewPath("spec", "policies").Index(i).Child("type")
+		if err := util.GetCapabilityDefinition(ctx, h.Client, new(v1beta1.PolicyDefinition), policy.Type); err != nil {
+			if apierrors.IsNotFound(err) {
+				errs = append(errs, field.NotFound(path, policy.Type))
+				continue
+			}
+			errs = append(errs, field.Invalid(path, policy.Type, err.Error()))
+		}
+	}
+	return errs
+}
+
+// workflowNode is a step or sub step of the workflow
+type workflowNode struct {
+	path *field.Path
+	name string
+	typ  string
+	// target is the name marked as ready once the step succeeded, it's the `name` in the properties of the step
+	target    string
+	dependsOn []string
+	inputs    common.StepInputs
+	outputs   common.StepOutputs
+}
+
+// workflowEdge points to the step depended by a workflow node, path is where the dependency is declared
+type workflowEdge struct {
+	to    int
+	value string
+	path  *field.Path
+}
+
+// validateWorkflow validates the types of the workflow steps exist, the `dependsOn` of the steps refer to
+// existing steps without cycles and the `inputs` of the steps refer to the declared outputs
+func (h *ValidatingHandler) validateWorkflow(ctx context.Context, app *v1beta1.Application) field.ErrorList {
+	if app.Spec.Workflow == nil {
+		return nil
+	}
+	var nodes []workflowNode
+	var errs field.ErrorList
+	addNode := func(node workflowNode, err *field.Error) {
+		nodes = append(nodes, node)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i, step := range app.Spec.Workflow.Steps {
+		stepPath := field.NewPath("spec", "workflow", "steps").Index(i)
+		addNode(newWorkflowNode(app, stepPath, step.Name, step.Type, step.Properties, step.DependsOn, step.Inputs, step.Outputs))
+		for j, subStep := range step.SubSteps {
+			addNode(newWorkflowNode(app, stepPath.Child("subSteps").Index(j), subStep.Name, subStep.Type,
+				subStep.Properties, subStep.DependsOn, subStep.Inputs, subStep.Outputs))
+		}
+	}
+
+	steps := map[string]int{}
+	outputs := map[string]int{}
+	for i, node := range nodes {
+		if _, ok := steps[node.name]; !ok {
+			steps[node.name] = i
+		}
+		for _, output := range node.outputs {
+			if _, ok := outputs[output.Name]; !ok {
+				outputs[output.Name] = i
+			}
+		}
+	}
+	for i, node := range nodes {
+		if _, ok := steps[node.target]; !ok && node.target != "" {
+			steps[node.target] = i
+		}
+	}
+
+	loader := template.NewWorkflowStepTemplateLoader(h.Client, h.dm)
+	edges := make([][]workflowEdge, len(nodes))
+	for i, node := range nodes {
+		if !builtinStepTypes[node.typ] {
+			templateName := node.typ
+			if templateName == applyComponentStepType {
+				templateName = applyComponentStepTemplate
+			}
+			if _, err := loader.LoadTaskTemplate(ctx, templateName); err != nil {
+				if apierrors.IsNotFound(errors.Cause(err)) {
+					errs = append(errs, field.NotFound(node.path.Child("type"), node.typ))
+				} else {
+					errs = append(errs, field.Invalid(node.path.Child("type"), node.typ, err.Error()))
+				}
+			}
+		}
+		for j, dep := range node.dependsOn {
+			path := node.path.Child("dependsOn").Index(j)
+			to, ok := steps[dep]
+			if !ok {
+				errs = append(errs, field.NotFound(path, dep))
+				continue
+			}
+			edges[i] = append(edges[i], workflowEdge{to: to, value: dep, path: path})
+		}
+		for j, input := range node.inputs {
+			path := node.path.Child("inputs").Index(j).Child("from")
+			to, ok := outputs[strings.Split(input.From, ".")[0]]
+			if !ok {
+				errs = append(errs, field.NotFound(path, input.From))
+				continue
+			}
+			edges[i] = append(edges[i], workflowEdge{to: to, value: input.From, path: path})
+		}
+	}
+	return append(errs, findWorkflowCycles(nodes, edges)...)
+}
+
+// newWorkflowNode builds the node of the workflow step. The apply-component step applies the component in its
+// properties, it's marked as the component once succeeded and inherits the inputs, outputs and dependsOn of the
+// component, the same as the step converted by the application controller.
+func newWorkflowNode(app *v1beta1.Application, path *field.Path, name, typ string, properties *runtime.RawExtension,
+	dependsOn []string, inputs common.StepInputs, outputs common.StepOutputs) (workflowNode, *field.Error) {
+	node := workflowNode{path: path, name: name, typ: typ, dependsOn: dependsOn, inputs: inputs, outputs: outputs}
+	o := struct {
+		Name      string `json:"name"`
+		Component string `json:"component"`
+	}{}
+	if properties != nil && len(properties.Raw) > 0 {
+		if err := json.Unmarshal(properties.Raw, &o); err == nil {
+			node.target = o.Name
+		}
+	}
+	if typ != applyComponentStepType {
+		return node, nil
+	}
+	for _, comp := range app.Spec.Components {
+		if comp.Name == o.Component {
+			node.target = comp.Name
+			node.dependsOn = append(append([]string{}, dependsOn...), comp.DependsOn...)
+			node.inputs = append(append(common.StepInputs{}, inputs...), comp.Inputs...)
+			node.outputs = append(append(common.StepOutputs{}, outputs...), comp.Outputs...)
+			return node, nil
+		}
+	}
+	return node, field.NotFound(path.Child("properties", "component"), o.Component)
+}
+
+// findWorkflowCycles reports the dependencies that close a cycle among the workflow steps
+func findWorkflowCycles(nodes []workflowNode, edges [][]workflowEdge) field.ErrorList {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	var errs field.ErrorList
+	state := make([]int, len(nodes))
+	var stack []int
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = visiting
+		stack = append(stack, i)
+		for _, edge := range edges[i] {
+			switch state[edge.to] {
+			case unvisited:
+				visit(edge.to)
+			case visiting:
+				// each step in the stack depends on the next one, and the last one depends on edge.to
+				var names []string
+				for k := len(stack) - 1; k >= 0; k-- {
+					if stack[k] == edge.to {
+						for _, n := range stack[k:] {
+							names = append(names, nodes[n].name)
+						}
+						break
+					}
+				}
+				names = append(names, nodes[edge.to].name)
+				errs = append(errs, field.Invalid(edge.path, edge.value, fmt.Sprintf("dependency cycle detected: %s", strings.Join(names, " -> "))))
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = visited
+	}
+	for i := range nodes {
+		if state[i] == unvisited {
+			visit(i)
+		}
+	}
+	return errs
+}
+
+// validateParameterSchemas validates the properties of the components and traits conform to
+// the OpenAPI v3 schema stored by the definition controllers, definitions without stored schema are skipped.
+// The parameters filled by the inputs at runtime are not required.
+func (h *ValidatingHandler) validateParameterSchemas(ctx context.Context, app *v1beta1.Application) field.ErrorList {
+	inputKeys := componentInputKeys(app)
+	var errs field.ErrorList
+	for i, comp := range app.Spec.Components {
+		compPath := field.NewPath("spec", "components").Index(i)
+		keys := inputKeys[comp.Name]
+		errs = append(errs, h.validateProperties(ctx, compPath.Child("properties"), new(v1beta1.ComponentDefinition),
+			common.ComponentType, comp.Type, comp.Properties, keys["properties"])...)
+		for j, trait := range comp.Traits {
+			errs = append(errs, h.validateProperties(ctx, compPath.Child("traits").Index(j).Child("properties"),
+				new(v1beta1.TraitDefinition), common.TraitType, trait.Type, trait.Properties, keys[fmt.Sprintf("traits[%d].properties", j)])...)
+		}
+	}
+	return errs
+}
+
+// componentInputKeys returns the parameter keys filled by the inputs of the components and the apply-component steps,
+// the keys are grouped by the component name and then the properties they belong to, e.g. properties or
+// traits[0].properties, a parameter key without such prefix belongs to the properties of the component
+func componentInputKeys(app *v1beta1.Application) map[string]map[string][]string {
+	keys := map[string]map[string][]string{}
+	add := func(component string, inputs common.StepInputs) {
+		for _, input := range inputs {
+			key := strings.TrimSpace(input.ParameterKey)
+			if !strings.HasPrefix(key, "properties") && !strings.HasPrefix(key, "traits[") {
+				key = "properties." + key
+			}
+			i := strings.Index(key, "properties.")
+			if i < 0 {
+				continue
+			}
+			if keys[component] == nil {
+				keys[component] = map[string][]string{}
+			}
+			prefix := key[:i+len("properties")]
+			keys[component][prefix] = append(keys[component][prefix], key[i+len("properties."):])
+		}
+	}
+	for _, comp := range app.Spec.Components {
+		add(comp.Name, comp.Inputs)
+	}
+	if app.Spec.Workflow == nil {
+		return keys
+	}
+	addStep := func(typ string, properties *runtime.RawExtension, inputs common.StepInputs) {
+		if typ != applyComponentStepType || properties == nil || len(properties.Raw) == 0 {
+			return
+		}
+		o := struct {
+			Component string `json:"component"`
+		}{}
+		if err := json.Unmarshal(properties.Raw, &o); err == nil {
+			add(o.Component, inputs)
+		}
+	}
+	for _, step := range app.Spec.Workflow.Steps {
+		addStep(step.Type, step.Properties, step.Inputs)
+		for _, subStep := range step.SubSteps {
+			addStep(subStep.Type, subStep.Properties, subStep.Inputs)
+		}
+	}
+	return keys
+}
+
+func (h *ValidatingHandler) validateProperties(ctx context.Context, path *field.Path, definition client.Object,
+	defType common.DefinitionType, definitionName string, properties *runtime.RawExtension, inputKeys []string) field.ErrorList {
+	schema, err := h.loadParameterSchema(ctx, definition, defType, definitionName)
+	if err != nil {
+		return field.ErrorList{field.InternalError(path, err)}
+	}
+	if schema == nil {
+		return nil
+	}
+	for _, key := range inputKeys {
+		relaxRequiredInput(schema, strings.Split(key, "."))
+	}
+	params, err := util.RawExtension2Map(properties)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, string(properties.Raw), err.Error())}
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	err = schema.VisitJSON(params, openapi3.MultiErrors())
+	if err == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	for _, e := range flattenSchemaErrors(err) {
+		errs = append(errs, schemaErrorToFieldError(path, e))
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+// loadParameterSchema loads the OpenAPI v3 schema of the definition parameter from the ConfigMap generated by the definition controller
+func (h *ValidatingHandler) loadParameterSchema(ctx context.Context, definition client.Object, defType common.DefinitionType,
+	definitionName string) (*openapi3.Schema, error) {
+	var namespace, name string
+	if util.IsDefinitionRevisionRef(definitionName) {
+		defRev, err := util.GetDefinitionRevisionByRef(ctx, h.Client, definitionName, defType)
+		if err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		namespace, name = defRev.Namespace, defRev.Name
+	} else {
+		if err := util.GetDefinition(ctx, h.Client, definition, definitionName); err != nil {
+			// the component may be defined by WorkloadDefinition which has no schema
+			return nil, client.IgnoreNotFound(err)
+		}
+		namespace, name = definition.GetNamespace(), definition.GetName()
+	}
+	cm := new(corev1.ConfigMap)
+	cmName := fmt.Sprintf("%s-%s%s", strings.ToLower(string(defType)), types.CapabilityConfigMapNamePrefix, name)
+	if err := h.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: cmName}, cm); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	data := cm.Data[types.OpenapiV3JSONSchema]
+	if data == "" {
+		return nil, nil
+	}
+	schema := &openapi3.Schema{}
+	if err := schema.UnmarshalJSON([]byte(data)); err != nil {
+		return nil, errors.Wrapf(err, "invalid parameter schema in configmap %s/%s", namespace, cmName)
+	}
+	relaxRequiredWithDefault(schema)
+	return schema, nil
+}
+
+// relaxRequiredWithDefault removes the parameters with default value from the required list,
+// since CUE marks them as required while they can be omitted in the application
+func relaxRequiredWithDefault(schema *openapi3.Schema) {
+	if schema == nil {
+		return
+	}
+	var required []string
+	for _, key := range schema.Required {
+		if prop := schema.Properties[key]; prop != nil && prop.Value != nil && prop.Value.Default != nil {
+			continue
+		}
+		required = append(required, key)
+	}
+	schema.Required = required
+	for _, prop := range schema.Properties {
+		if prop != nil {
+			relaxRequiredWithDefault(prop.Value)
+		}
+	}
+	if schema.Items != nil {
+		relaxRequiredWithDefault(schema.Items.Value)
+	}
+	if schema.AdditionalProperties != nil {
+		relaxRequiredWithDefault(schema.AdditionalProperties.Value)
+	}
+}
+
+// relaxRequiredInput removes the parameter filled by the input from the required list, along with the parent
+// parameters of it since they are created by the input as well
+func relaxRequiredInput(schema *openapi3.Schema, segments []string) {
+	if schema == nil || len(segments) == 0 {
+		return
+	}
+	var required []string
+	for _, key := range schema.Required {
+		if key != segments[0] {
+			required = append(required, key)
+		}
+	}
+	schema.Required = required
+	if prop := schema.Properties[segments[0]]; prop != nil {
+		relaxRequiredInput(prop.Value, segments[1:])
+	}
+}
+
+func flattenSchemaErrors(err error) []*openapi3.SchemaError {
+	switch e := err.(type) {
+	case *openapi3.SchemaError:
+		return []*openapi3.SchemaError{e}
+	case openapi3.MultiError:
+		var errs []*openapi3.SchemaError
+		for _, sub := range e {
+			errs = append(errs, flattenSchemaErrors(sub)...)
+		}
+		return errs
+	default:
+		return []*openapi3.SchemaError{{Reason: err.Error()}}
+	}
+}
+
+func schemaErrorToFieldError(path *field.Path, err *openapi3.SchemaError) *field.Error {
+	for _, key := range err.JSONPointer() {
+		if index, e := strconv.Atoi(key); e == nil {
+			path = path.Index(index)
+		} else {
+			path = path.Child(key)
+		}
+	}
+	reason := err.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("doesn't match schema %q", err.SchemaField)
+	}
+	switch err.SchemaField {
+	case "required":
+		return field.Required(path, reason)
+	case "properties":
+		return field.Forbidden(path, reason)
+	default:
+		return field.Invalid(path, err.Value, reason)
+	}
+}
+
 func (h *ValidatingHandler) validateExternalRevisionName(ctx context.Context, app *v1beta1.Application) field.ErrorList {
 	var componentErrs field.ErrorList
 
@@ -0,0 +1,283 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+	velacommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateDefinitionRevisions(t *testing.T) {
+	r := require.New(t)
+	cli := fake.NewClientBuilder().WithScheme(velacommon.Scheme).WithObjects(&v1beta1.DefinitionRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-v2", Namespace: "vela-system"},
+		Spec: v1beta1.DefinitionRevisionSpec{
+			Revision:            2,
+			DefinitionType:      common.ComponentType,
+			ComponentDefinition: v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Name: "worker"}},
+		},
+	}).Build()
+	h := &ValidatingHandler{Client: cli}
+	ctx := util.SetNamespaceInCtx(context.Background(), "default")
+
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: v1beta1.ApplicationSpec{
+			Components: []common.ApplicationComponent{{
+				Name: "backend",
+				Type: "worker@v2",
+				Traits: []common.ApplicationTrait{
+					{Type: "scaler"},
+					{Type: "worker@v2"},
+				},
+			}, {
+				Name: "frontend",
+				Type: "worker@v3",
+			}},
+		},
+	}
+	errs := h.validateDefinitionRevisions(ctx, app)
+	r.Len(errs, 2)
+	r.Equal(field.ErrorTypeInvalid, errs[0].Type)
+	r.Equal("spec.components[0].traits[1].type", errs[0].Field)
+	r.Equal(field.ErrorTypeNotFound, errs[1].Type)
+	r.Equal("spec.components[1].type", errs[1].Field)
+
+	app.Spec.Components = app.Spec.Components[:1]
+	app.Spec.Components[0].Traits = app.Spec.Components[0].Traits[:1]
+	r.Empty(h.validateDefinitionRevisions(ctx, app))
+}
+
+func TestValidatePolicies(t *testing.T) {
+	r := require.New(t)
+	cli := fake.NewClientBuilder().WithScheme(velacommon.Scheme).WithObjects(&v1beta1.PolicyDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "env-binding", Namespace: "vela-system"},
+	}).Build()
+	h := &ValidatingHandler{Client: cli}
+	ctx := util.SetNamespaceInCtx(context.Background(), "default")
+
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: v1beta1.ApplicationSpec{
+			Policies: []v1beta1.AppPolicy{
+				{Name: "gc", Type: "garbage-collect"},
+				{Name: "env", Type: "env-binding"},
+				{Name: "unknown", Type: "unknown"},
+			},
+		},
+	}
+	errs := h.validatePolicies(ctx, app)
+	r.Len(errs, 1)
+	r.Equal(field.ErrorTypeNotFound, errs[0].Type)
+	r.Equal("spec.policies[2].type", errs[0].Field)
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	r := require.New(t)
+	cli := fake.NewClientBuilder().WithScheme(velacommon.Scheme).WithObjects(&v1beta1.WorkflowStepDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "apply-object", Namespace: "vela-system"},
+		Spec: v1beta1.WorkflowStepDefinitionSpec{
+			Schematic: &common.Schematic{CUE: &common.CUE{Template: "parameter: {}"}},
+		},
+	}).Build()
+	h := &ValidatingHandler{Client: cli}
+	ctx := util.SetNamespaceInCtx(context.Background(), "default")
+
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: v1beta1.ApplicationSpec{
+			Workflow: &v1beta1.Workflow{Steps: []v1beta1.WorkflowStep{{
+				Name:       "apply-backend",
+				Type:       "builtin-apply-component",
+				Properties: &runtime.RawExtension{Raw: []byte(`{"name":"backend"}`)},
+				Outputs:    common.StepOutputs{{Name: "backend-ip", ValueFrom: "output.value.ip"}},
+			}, {
+				Name:      "apply-frontend",
+				Type:      "apply-object",
+				DependsOn: []string{"backend", "apply-cache"},
+				Inputs:    common.StepInputs{{From: "backend-ip.value", ParameterKey: "ip"}, {From: "cache-ip", ParameterKey: "cache"}},
+			}, {
+				Name:      "notify",
+				Type:      "notification",
+				DependsOn: []string{"group"},
+			}, {
+				Name:      "group",
+				Type:      "step-group",
+				DependsOn: []string{"notify"},
+				SubSteps: []v1beta1.WorkflowSubStep{{
+					Name:      "pause",
+					Type:      "suspend",
+					DependsOn: []string{"pause"},
+				}},
+			}}},
+		},
+	}
+	errs := h.validateWorkflow(ctx, app)
+	r.Len(errs, 5)
+	r.Equal(field.ErrorTypeNotFound, errs[0].Type)
+	r.Equal("spec.workflow.steps[1].dependsOn[1]", errs[0].Field)
+	r.Equal(field.ErrorTypeNotFound, errs[1].Type)
+	r.Equal("spec.workflow.steps[1].inputs[1].from", errs[1].Field)
+	r.Equal(field.ErrorTypeNotFound, errs[2].Type)
+	r.Equal("spec.workflow.steps[2].type", errs[2].Field)
+	r.Equal(field.ErrorTypeInvalid, errs[3].Type)
+	r.Equal("spec.workflow.steps[3].dependsOn[0]", errs[3].Field)
+	r.Contains(errs[3].Detail, "notify -> group -> notify")
+	r.Equal("spec.workflow.steps[3].subSteps[0].dependsOn[0]", errs[4].Field)
+	r.Contains(errs[4].Detail, "pause -> pause")
+
+	app.Spec.Workflow.Steps = app.Spec.Workflow.Steps[:1]
+	r.Empty(h.validateWorkflow(ctx, app))
+
+	// the apply-component steps are marked as the components and inherit the inputs, outputs and dependsOn of them
+	app.Spec.Components = []common.ApplicationComponent{{
+		Name:    "backend",
+		Outputs: common.StepOutputs{{Name: "backend-ip", ValueFrom: "output.value.ip"}},
+	}, {
+		Name:      "frontend",
+		DependsOn: []string{"backend"},
+		Inputs:    common.StepInputs{{From: "backend-ip", ParameterKey: "ip"}},
+	}}
+	app.Spec.Workflow.Steps = []v1beta1.WorkflowStep{{
+		Name:       "apply-backend",
+		Type:       "apply-component",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"component":"backend"}`)},
+	}, {
+		Name:       "apply-frontend",
+		Type:       "apply-component",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"component":"frontend"}`)},
+	}, {
+		Name:      "notify",
+		Type:      "apply-object",
+		DependsOn: []string{"frontend"},
+	}, {
+		Name:       "apply-cache",
+		Type:       "apply-component",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"component":"cache"}`)},
+	}}
+	errs = h.validateWorkflow(ctx, app)
+	r.Len(errs, 1)
+	r.Equal(field.ErrorTypeNotFound, errs[0].Type)
+	r.Equal("spec.workflow.steps[3].properties.component", errs[0].Field)
+
+	app.Spec.Components[0].DependsOn = []string{"frontend"}
+	errs = h.validateWorkflow(ctx, app)
+	r.Len(errs, 3)
+	r.Equal("spec.workflow.steps[3].properties.component", errs[0].Field)
+	r.Equal("spec.workflow.steps[1].dependsOn[0]", errs[1].Field)
+	r.Contains(errs[1].Detail, "apply-backend -> apply-frontend -> apply-backend")
+}
+
+func TestValidateWorkflowExamples(t *testing.T) {
+	r := require.New(t)
+	builder := fake.NewClientBuilder().WithScheme(velacommon.Scheme)
+	for _, typ := range []string{"read-object", "export-config", "export-secret", "apply-object", "apply-remaining", "depends-on-app"} {
+		builder.WithObjects(&v1beta1.WorkflowStepDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: typ, Namespace: "vela-system"},
+			Spec: v1beta1.WorkflowStepDefinitionSpec{
+				Schematic: &common.Schematic{CUE: &common.CUE{Template: "parameter: {}"}},
+			},
+		})
+	}
+	h := &ValidatingHandler{Client: builder.Build()}
+	ctx := util.SetNamespaceInCtx(context.Background(), "default")
+
+	for _, example := range []string{"read-object", "export2config", "export2secret", "apply-object", "apply-remaining", "depends-on-app"} {
+		data, err := ioutil.ReadFile(filepath.Join("../../../../../docs/examples/workflow", example, "app.yaml"))
+		r.NoError(err)
+		app := &v1beta1.Application{}
+		r.NoError(yaml.Unmarshal(data, app))
+		r.Empty(h.validateWorkflow(ctx, app), example)
+	}
+}
+
+func TestValidateParameterSchemas(t *testing.T) {
+	r := require.New(t)
+	cli := fake.NewClientBuilder().WithScheme(velacommon.Scheme).WithObjects(&v1beta1.ComponentDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "vela-system"},
+	}, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "component-schema-worker", Namespace: "vela-system"},
+		Data: map[string]string{
+			types.OpenapiV3JSONSchema: `{"properties":{"image":{"title":"image","type":"string"},"cmd":{"items":{"type":"string"},"title":"cmd","type":"array"},"replicas":{"default":1,"title":"replicas","type":"integer"}},"required":["image","replicas"],"type":"object"}`,
+		},
+	}, &v1beta1.TraitDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "vela-system"},
+	}).Build()
+	h := &ValidatingHandler{Client: cli}
+	ctx := util.SetNamespaceInCtx(context.Background(), "default")
+
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: v1beta1.ApplicationSpec{
+			Components: []common.ApplicationComponent{{
+				Name:       "backend",
+				Type:       "worker",
+				Properties: &runtime.RawExtension{Raw: []byte(`{"image":"busybox","cmd":["sleep",1000]}`)},
+				Traits:     []common.ApplicationTrait{{Type: "scaler", Properties: &runtime.RawExtension{Raw: []byte(`{"replicas":"1"}`)}}},
+			}, {
+				Name:       "frontend",
+				Type:       "worker",
+				Properties: &runtime.RawExtension{Raw: []byte(`{"replicas":1.5}`)},
+			}, {
+				Name: "webservice",
+				Type: "webservice",
+			}},
+		},
+	}
+	errs := h.validateParameterSchemas(ctx, app)
+	r.Len(errs, 3)
+	r.Equal(field.ErrorTypeInvalid, errs[0].Type)
+	r.Equal("spec.components[0].properties.cmd[1]", errs[0].Field)
+	r.Equal(field.ErrorTypeRequired, errs[1].Type)
+	r.Equal("spec.components[1].properties.image", errs[1].Field)
+	r.Equal(field.ErrorTypeInvalid, errs[2].Type)
+	r.Equal("spec.components[1].properties.replicas", errs[2].Field)
+
+	app.Spec.Components = app.Spec.Components[:1]
+	app.Spec.Components[0].Properties = &runtime.RawExtension{Raw: []byte(`{"image":"busybox","cmd":["sleep","1000"]}`)}
+	r.Empty(h.validateParameterSchemas(ctx, app))
+
+	// the parameters filled by the inputs are not required
+	app.Spec.Components[0].Properties = &runtime.RawExtension{Raw: []byte(`{"cmd":["sleep","1000"]}`)}
+	r.Len(h.validateParameterSchemas(ctx, app), 1)
+	app.Spec.Components[0].Inputs = common.StepInputs{{From: "image", ParameterKey: "image"}}
+	r.Empty(h.validateParameterSchemas(ctx, app))
+	app.Spec.Components[0].Inputs = nil
+	app.Spec.Workflow = &v1beta1.Workflow{Steps: []v1beta1.WorkflowStep{{
+		Name:       "apply-backend",
+		Type:       "apply-component",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"component":"backend"}`)},
+		Inputs:     common.StepInputs{{From: "image", ParameterKey: "properties.image"}},
+	}}}
+	r.Empty(h.validateParameterSchemas(ctx, app))
+}
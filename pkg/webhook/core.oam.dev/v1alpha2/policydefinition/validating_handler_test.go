@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policydefinition
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestValidatingHandler(t *testing.T) {
+	r := require.New(t)
+	scheme := runtime.NewScheme()
+	r.NoError(v1beta1.AddToScheme(scheme))
+	decoder, err := admission.NewDecoder(scheme)
+	r.NoError(err)
+	h := &ValidatingHandler{}
+	r.NoError(h.InjectDecoder(decoder))
+
+	request := func(template string) admission.Request {
+		def := &v1beta1.PolicyDefinition{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.PolicyDefinitionKind},
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "vela-system"},
+			Spec: v1beta1.PolicyDefinitionSpec{
+				Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+			},
+		}
+		raw, err := json.Marshal(def)
+		r.NoError(err)
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Resource:  metav1.GroupVersionResource{Group: v1beta1.Group, Version: v1beta1.Version, Resource: "policydefinitions"},
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+	}
+
+	resp := h.Handle(context.Background(), request(`
+output: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	metadata: name: context.name
+	data: parameter.data
+}
+parameter: data: [string]: string`))
+	r.True(resp.Allowed, resp.Result)
+
+	resp = h.Handle(context.Background(), request(`output: {`))
+	r.False(resp.Allowed)
+	r.Contains(string(resp.Result.Reason), "failed to compile the CUE template")
+
+	resp = h.Handle(context.Background(), request(`parameter: [...string]`))
+	r.False(resp.Allowed)
+	r.Contains(string(resp.Result.Reason), "the parameter of the CUE template must be a struct")
+}
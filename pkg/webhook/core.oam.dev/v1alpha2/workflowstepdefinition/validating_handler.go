@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/cue/model"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/stdlib"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
+)
+
+var workflowStepDefGVR = v1beta1.SchemeGroupVersion.WithResource("workflowstepdefinitions")
+
+// ValidatingHandler handles validation of workflow step definition
+type ValidatingHandler struct {
+	Client client.Client
+	pd     *packages.PackageDiscover
+
+	// Decoder decodes object
+	Decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ValidatingHandler{}
+
+// Handle validate workflow step definition
+func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &v1beta1.WorkflowStepDefinition{}
+	if req.Resource.String() != workflowStepDefGVR.String() {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("expect resource to be %s", workflowStepDefGVR))
+	}
+
+	if req.Operation == admissionv1.Create || req.Operation == admissionv1.Update {
+		err := h.Decoder.Decode(req, obj)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		klog.InfoS("validating", "name", obj.Name, "operation", string(req.Operation))
+		if err := ValidateWorkflowStepDefinition(h.pd, obj); err != nil {
+			klog.InfoS("validation failed", "name", obj.Name, "err", err.Error())
+			return admission.Denied(err.Error())
+		}
+		revisionName := obj.GetAnnotations()[oam.AnnotationDefinitionRevisionName]
+		if len(revisionName) != 0 {
+			defRevName := fmt.Sprintf("%s-v%s", obj.Name, revisionName)
+			err = webhookutils.ValidateDefinitionRevision(ctx, h.Client, obj, client.ObjectKey{Namespace: obj.Namespace, Name: defRevName})
+			if err != nil {
+				return admission.Denied(err.Error())
+			}
+		}
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+var _ inject.Client = &ValidatingHandler{}
+
+// InjectClient injects the client into the ValidatingHandler
+func (h *ValidatingHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ admission.DecoderInjector = &ValidatingHandler{}
+
+// InjectDecoder injects the decoder into the ValidatingHandler
+func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
+
+// RegisterValidatingHandler will register WorkflowStepDefinition validation to webhook
+func RegisterValidatingHandler(mgr manager.Manager, args controller.Args) {
+	server := mgr.GetWebhookServer()
+	server.Register("/validating-core-oam-dev-v1beta1-workflowstepdefinitions", &webhook.Admission{Handler: &ValidatingHandler{
+		pd: args.PackageDiscover,
+	}})
+}
+
+// ValidateWorkflowStepDefinition validates the CUE template of the workflow step definition, the template must be
+// compiled and all the operations it refers by `#do` and `#provider` must exist in the stdlib
+func ValidateWorkflowStepDefinition(pd *packages.PackageDiscover, def *v1beta1.WorkflowStepDefinition) error {
+	if def.Spec.Schematic == nil || def.Spec.Schematic.CUE == nil {
+		return nil
+	}
+	val, err := webhookutils.ValidateCueTemplate(def.Spec.Schematic.CUE.Template, pd)
+	if err != nil {
+		return err
+	}
+	ops, err := stdlib.GetOps()
+	if err != nil {
+		return errors.WithMessage(err, "failed to load the operations in stdlib")
+	}
+	return validateOps(val.CueValue(), ops, nil)
+}
+
+// validateOps walks the fields of the template and checks the operations declared by `#do` and `#provider`
+func validateOps(v cue.Value, ops map[stdlib.Op]bool, path []string) error {
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		if do, err := v.LookupDef("#do").String(); err == nil {
+			provider, err := v.LookupDef("#provider").String()
+			if err != nil {
+				provider = stdlib.BuiltinProvider
+			}
+			if !ops[stdlib.Op{Provider: provider, Do: do}] {
+				return fmt.Errorf("the operation `%s` of provider `%s` used by %s is not found", do, provider, formatPath(path))
+			}
+		}
+		iter, err := v.Fields()
+		if err != nil {
+			return nil
+		}
+		for iter.Next() {
+			if len(path) == 0 && (iter.Label() == model.ParameterFieldName || iter.Label() == "context") {
+				continue
+			}
+			if err := validateOps(iter.Value(), ops, append(path, iter.Label())); err != nil {
+				return err
+			}
+		}
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return nil
+		}
+		for i := 0; iter.Next(); i++ {
+			if err := validateOps(iter.Value(), ops, append(path, fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+	default:
+	}
+	return nil
+}
+
+func formatPath(path []string) string {
+	if len(path) == 0 {
+		return "the template"
+	}
+	return strings.Join(path, ".")
+}
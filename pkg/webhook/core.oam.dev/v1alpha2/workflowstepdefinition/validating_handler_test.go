@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestValidateWorkflowStepDefinition(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		template string
+		wantErr  string
+	}{
+		"ValidTemplate": {
+			reason: "No error should be returned if the template only uses the ops in stdlib",
+			template: `
+import "vela/op"
+
+apply: op.#Apply & {
+	value: parameter.value
+}
+wait: op.#ConditionalWait & {
+	continue: apply.value.status.ready
+}
+custom: {
+	#provider: "kube"
+	#do:       "read"
+	value:     parameter.value
+}
+parameter: {
+	value: {...}
+}`,
+		},
+		"NoTemplate": {
+			reason: "No error should be returned if the definition has no CUE template",
+		},
+		"InvalidCUE": {
+			reason:   "An error should be returned if the template can't be compiled",
+			template: `apply: op.#Apply & {`,
+			wantErr:  "failed to compile the CUE template",
+		},
+		"ConflictValues": {
+			reason: "An error should be returned if the template has conflicting values",
+			template: `
+replicas: 1
+replicas: 2`,
+			wantErr: "invalid CUE template",
+		},
+		"InvalidParameter": {
+			reason:   "An error should be returned if the parameter is not a struct",
+			template: `parameter: string`,
+			wantErr:  "the parameter of the CUE template must be a struct",
+		},
+		"UnknownOp": {
+			reason: "An error should be returned if the template refers to an op not in stdlib",
+			template: `
+steps: {
+	read: {
+		#provider: "kube"
+		#do:       "watch"
+	}
+}`,
+			wantErr: "the operation `watch` of provider `kube` used by steps.read is not found",
+		},
+		"UnknownProvider": {
+			reason: "An error should be returned if the template refers to a provider not in stdlib",
+			template: `
+send: {
+	#provider: "sms"
+	#do:       "send"
+}`,
+			wantErr: "the operation `send` of provider `sms` used by send is not found",
+		},
+	}
+	for caseName, tc := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			def := &v1beta1.WorkflowStepDefinition{}
+			if tc.template != "" {
+				def.Spec.Schematic = &common.Schematic{CUE: &common.CUE{Template: tc.template}}
+			}
+			err := ValidateWorkflowStepDefinition(nil, def)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("\n%s\nValidateWorkflowStepDefinition(...): unexpected error %v\n", tc.reason, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("\n%s\nValidateWorkflowStepDefinition(...): want error %q, got %v\n", tc.reason, tc.wantErr, err)
+			}
+		})
+	}
+}
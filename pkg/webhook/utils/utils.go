@@ -18,8 +18,10 @@ package utils
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
+	"cuelang.org/go/cue"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -28,6 +30,9 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core"
+	"github.com/oam-dev/kubevela/pkg/cue/model"
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
 )
 
 // ValidateDefinitionRevision validate whether definition will modify the immutable object definitionRevision
@@ -53,3 +58,21 @@ func ValidateDefinitionRevision(ctx context.Context, cli client.Client, def runt
 	}
 	return nil
 }
+
+// ValidateCueTemplate compiles the CUE template of the definition with the packages and checks that the parameter,
+// if declared, is a struct. The compiled template is returned for further validation.
+func ValidateCueTemplate(template string, pd *packages.PackageDiscover) (*value.Value, error) {
+	// the context is filled at runtime, so it's left open here
+	val, err := value.NewValue(template+"\ncontext: {...}\n", pd, "", value.ProcessScript, value.TagFieldOrder)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to compile the CUE template")
+	}
+	if err := val.CueValue().Validate(); err != nil {
+		return nil, errors.WithMessage(err, "invalid CUE template")
+	}
+	parameter := val.CueValue().Lookup(model.ParameterFieldName)
+	if parameter.Exists() && parameter.IncompleteKind() != cue.StructKind {
+		return nil, fmt.Errorf("the %s of the CUE template must be a struct", model.ParameterFieldName)
+	}
+	return val, nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	oamcore "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	"github.com/oam-dev/kubevela/pkg/utils"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	wfTypes "github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+const (
+	// StatusReasonSkip is the reason of the workflow step which is skipped.
+	StatusReasonSkip = "Skip"
+	// StatusReasonCondition is the reason of the workflow step which is failed since its `if` condition can't be evaluated.
+	StatusReasonCondition = "Condition"
+
+	conditionFieldName = "condition__"
+)
+
+type conditionStepStatus struct {
+	Phase   common.WorkflowStepPhase `json:"phase"`
+	Reason  string                   `json:"reason"`
+	Message string                   `json:"message"`
+}
+
+func (e *engine) getStepSpec(name string) *oamcore.WorkflowStep {
+	for i := range e.stepSpecs {
+		if e.stepSpecs[i].Name == name {
+			return &e.stepSpecs[i]
+		}
+	}
+	return nil
+}
+
+// checkStepCondition decides whether the step should be skipped. Steps with `if` are skipped if the
+// condition evaluates to false, other steps are skipped once any previous step is failed.
+func (e *engine) checkStepCondition(wfCtx wfContext.Context, name string) (skip bool, reason string, err error) {
+	step := e.getStepSpec(name)
+	if step == nil || strings.TrimSpace(step.If) == "" {
+		if e.hasFailedStep() {
+			return true, "skipped since the previous step is failed", nil
+		}
+		return false, "", nil
+	}
+	ok, err := e.evalCondition(wfCtx, step.If)
+	if err != nil {
+		return false, "", errors.WithMessagef(err, "evaluate condition %q", step.If)
+	}
+	return !ok, fmt.Sprintf("condition %q is not met", step.If), nil
+}
+
+// evalCondition evaluates the expression with the workflow context and the status of steps.
+func (e *engine) evalCondition(wfCtx wfContext.Context, expr string) (bool, error) {
+	var template strings.Builder
+	outputs := "{}"
+	if vars, err := wfCtx.GetVar(); err == nil {
+		s, err := vars.String()
+		if err != nil {
+			return false, err
+		}
+		outputs = fmt.Sprintf("{%s}", s)
+	}
+	fmt.Fprintf(&template, "outputs: %s\n", outputs)
+	if meta, err := wfCtx.GetVar(wfTypes.ContextKeyMetadata); err == nil {
+		s, err := meta.String()
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintf(&template, "context: {%s}\n", s)
+	}
+
+	steps := map[string]conditionStepStatus{}
+	for _, ss := range e.status.Steps {
+		steps[ss.Name] = conditionStepStatus{Phase: ss.Phase, Reason: ss.Reason, Message: ss.Message}
+	}
+	js, err := json.Marshal(steps)
+	if err != nil {
+		return false, err
+	}
+	fmt.Fprintf(&template, "status: %s\n", js)
+	fmt.Fprintf(&template, "%s: %s\n", conditionFieldName, expr)
+
+	v, err := value.NewValue(template.String(), nil, "")
+	if err != nil {
+		return false, err
+	}
+	return v.GetBool(conditionFieldName)
+}
+
+// skipStep records the status of the skipped step.
+func (e *engine) skipStep(name string, message string) {
+	status := common.WorkflowStepStatus{
+		ID:      e.stepID(name),
+		Name:    name,
+		Phase:   common.WorkflowStepPhaseSkipped,
+		Reason:  StatusReasonSkip,
+		Message: message,
+	}
+	if step := e.getStepSpec(name); step != nil {
+		status.Type = step.Type
+	}
+	e.updateStepStatus(status)
+}
+
+// stepID returns the recorded id of the step, the id of the step which has never been executed is generated.
+func (e *engine) stepID(name string) string {
+	if last := e.getStepStatus(name); last != nil && last.ID != "" {
+		return last.ID
+	}
+	return utils.RandomString(10)
+}
+
+// hasFailedStep checks whether any step is failed and won't be executed anymore.
+func (e *engine) hasFailedStep() bool {
+	for _, ss := range e.status.Steps {
+		if isStepFailed(ss) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStepFinished checks whether the step won't be executed anymore.
+func isStepFinished(status common.WorkflowStepStatus) bool {
+	return status.Phase == common.WorkflowStepPhaseSucceeded || status.Phase == common.WorkflowStepPhaseSkipped || isStepFailed(status)
+}
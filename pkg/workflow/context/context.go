@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"time"
 
 	"cuelang.org/go/cue"
@@ -42,6 +43,8 @@ const (
 	ConfigMapKeyComponents = "components"
 	// ConfigMapKeyVars is the key in ConfigMap Data field for containing data of variable
 	ConfigMapKeyVars = "vars"
+	// ConfigMapKeyDebug is the key in ConfigMap Data field for containing debug info of steps
+	ConfigMapKeyDebug = "debug"
 	// AnnotationStartTimestamp is the annotation key of the workflow start  timestamp
 	AnnotationStartTimestamp = "vela.io/startTime"
 )
@@ -52,6 +55,7 @@ type WorkflowContext struct {
 	store      corev1.ConfigMap
 	components map[string]*ComponentManifest
 	vars       *value.Value
+	debug      map[string]*StepDebugInfo
 	modified   bool
 }
 
@@ -103,6 +107,34 @@ func (wf *WorkflowContext) SetVar(v *value.Value, paths ...string) error {
 	return nil
 }
 
+// GetStepDebugInfo get the debug info of the step, it returns nil if the step has no debug info.
+func (wf *WorkflowContext) GetStepDebugInfo(name string) *StepDebugInfo {
+	return wf.debug[name]
+}
+
+// SetStepDebugInfo set the debug info of the step. The empty debug info removes the one of the last execution,
+// so that the steps not in debug mode don't write anything unless they fail.
+func (wf *WorkflowContext) SetStepDebugInfo(name string, info *StepDebugInfo) {
+	if info != nil && reflect.DeepEqual(*info, StepDebugInfo{}) {
+		info = nil
+	}
+	if info == nil {
+		if _, ok := wf.debug[name]; ok {
+			delete(wf.debug, name)
+			wf.modified = true
+		}
+		return
+	}
+	if reflect.DeepEqual(wf.debug[name], info) {
+		return
+	}
+	if wf.debug == nil {
+		wf.debug = map[string]*StepDebugInfo{}
+	}
+	wf.debug[name] = info
+	wf.modified = true
+}
+
 // MakeParameter make 'value' with interface{}
 func (wf *WorkflowContext) MakeParameter(parameter interface{}) (*value.Value, error) {
 	var s = "{}"
@@ -149,6 +181,9 @@ func (wf *WorkflowContext) writeToStore() error {
 		ConfigMapKeyComponents: string(util.MustJSONMarshal(jsonObject)),
 		ConfigMapKeyVars:       varStr,
 	}
+	if len(wf.debug) > 0 {
+		wf.store.Data[ConfigMapKeyDebug] = string(util.MustJSONMarshal(wf.debug))
+	}
 	return nil
 }
 
@@ -184,6 +219,12 @@ func (wf *WorkflowContext) LoadFromConfigMap(cm corev1.ConfigMap) error {
 	if err != nil {
 		return errors.WithMessage(err, "decode vars")
 	}
+	wf.debug = map[string]*StepDebugInfo{}
+	if debugJs, ok := data[ConfigMapKeyDebug]; ok {
+		if err := json.Unmarshal([]byte(debugJs), &wf.debug); err != nil {
+			return errors.WithMessage(err, "decode debug info")
+		}
+	}
 	return nil
 }
 
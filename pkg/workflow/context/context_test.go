@@ -280,6 +280,43 @@ func TestContext(t *testing.T) {
 	assert.Equal(t, err != nil, true)
 }
 
+func TestStepDebugInfo(t *testing.T) {
+	wfCtx := newContextForTest(t)
+	assert.Equal(t, wfCtx.GetStepDebugInfo("step1") == nil, true)
+
+	info := &StepDebugInfo{
+		Parameter:     `image: "nginx"`,
+		ProviderCalls: []ProviderCall{{Phase: "stepStart", Provider: "kube", Do: "apply", Value: "value: {}"}},
+		Error:         "apply error",
+	}
+	wfCtx.modified = false
+	wfCtx.SetStepDebugInfo("step1", info)
+	assert.Equal(t, wfCtx.modified, true)
+	// the same debug info won't modify the context.
+	wfCtx.modified = false
+	wfCtx.SetStepDebugInfo("step1", &StepDebugInfo{
+		Parameter:     `image: "nginx"`,
+		ProviderCalls: []ProviderCall{{Phase: "stepStart", Provider: "kube", Do: "apply", Value: "value: {}"}},
+		Error:         "apply error",
+	})
+	assert.Equal(t, wfCtx.modified, false)
+
+	// the empty debug info isn't written.
+	wfCtx.SetStepDebugInfo("step2", &StepDebugInfo{})
+	assert.Equal(t, wfCtx.modified, false)
+	assert.Equal(t, wfCtx.GetStepDebugInfo("step2") == nil, true)
+
+	assert.NilError(t, wfCtx.writeToStore())
+	loaded := new(WorkflowContext)
+	assert.NilError(t, loaded.LoadFromConfigMap(wfCtx.store))
+	assert.DeepEqual(t, loaded.GetStepDebugInfo("step1"), info)
+
+	// the empty debug info removes the one of the last execution.
+	loaded.SetStepDebugInfo("step1", &StepDebugInfo{})
+	assert.Equal(t, loaded.modified, true)
+	assert.Equal(t, loaded.GetStepDebugInfo("step1") == nil, true)
+}
+
 func newContextForTest(t *testing.T) *WorkflowContext {
 	var cm corev1.ConfigMap
 	testCaseJson, err := yamlUtil.YAMLToJSON([]byte(testCaseYaml))
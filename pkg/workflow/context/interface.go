@@ -32,4 +32,25 @@ type Context interface {
 	Commit() error
 	MakeParameter(parameter interface{}) (*value.Value, error)
 	StoreRef() *corev1.ObjectReference
+	GetStepDebugInfo(name string) *StepDebugInfo
+	SetStepDebugInfo(name string, info *StepDebugInfo)
+}
+
+// StepDebugInfo records what a workflow step did in its last execution.
+type StepDebugInfo struct {
+	// Parameter is the rendered parameter of the step, it's recorded only in debug mode with the credentials redacted.
+	Parameter string `json:"parameter,omitempty"`
+	// ProviderCalls are the provider calls made by the step, they are recorded only in debug mode with the credentials
+	// redacted and the values truncated.
+	ProviderCalls []ProviderCall `json:"providerCalls,omitempty"`
+	// Error is the error of the step.
+	Error string `json:"error,omitempty"`
+}
+
+// ProviderCall records a provider call of the workflow step.
+type ProviderCall struct {
+	Phase    string `json:"phase"`
+	Provider string `json:"provider"`
+	Do       string `json:"do,omitempty"`
+	Value    string `json:"value,omitempty"`
 }
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	oamcore "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	wfTypes "github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+const (
+	// StatusReasonTimeout is the reason of the workflow step which exceeds its timeout.
+	StatusReasonTimeout = "Timeout"
+	// StatusReasonRetriesExhausted is the reason of the failed workflow step which has no retries left.
+	StatusReasonRetriesExhausted = "RetriesExhausted"
+)
+
+// stepPolicy is the parsed timeout, retry and backoff settings of a workflow step.
+type stepPolicy struct {
+	timeout     time.Duration
+	retries     *int
+	backoff     time.Duration
+	factor      int
+	maxBackoff  time.Duration
+	hasSettings bool
+}
+
+func newStepPolicy(step oamcore.WorkflowStep) (*stepPolicy, error) {
+	var err error
+	p := &stepPolicy{factor: 1, retries: step.Retries}
+	if step.Timeout != "" {
+		if p.timeout, err = time.ParseDuration(step.Timeout); err != nil {
+			return nil, errors.WithMessagef(err, "parse timeout of step %s", step.Name)
+		}
+	}
+	if b := step.Backoff; b != nil {
+		if b.Duration != "" {
+			if p.backoff, err = time.ParseDuration(b.Duration); err != nil {
+				return nil, errors.WithMessagef(err, "parse backoff duration of step %s", step.Name)
+			}
+		}
+		if b.MaxDuration != "" {
+			if p.maxBackoff, err = time.ParseDuration(b.MaxDuration); err != nil {
+				return nil, errors.WithMessagef(err, "parse backoff maxDuration of step %s", step.Name)
+			}
+		}
+		if b.Factor > 1 {
+			p.factor = b.Factor
+		}
+	}
+	p.hasSettings = p.timeout > 0 || p.retries != nil || p.backoff > 0
+	return p, nil
+}
+
+// timedOut checks whether the step has run longer than its timeout since the first execution.
+func (p *stepPolicy) timedOut(status common.WorkflowStepStatus, now time.Time) bool {
+	if p.timeout <= 0 || status.FirstExecuteTime.IsZero() {
+		return false
+	}
+	return now.Sub(status.FirstExecuteTime.Time) >= p.timeout
+}
+
+// retriesExhausted checks whether the failed step has used up all its retries.
+func (p *stepPolicy) retriesExhausted(status common.WorkflowStepStatus) bool {
+	if p.retries == nil {
+		return false
+	}
+	return status.Attempts > *p.retries
+}
+
+// nextRetryTime computes when the step can be retried after the given number of attempts.
+func (p *stepPolicy) nextRetryTime(attempts int, now time.Time) *metav1.Time {
+	if p.backoff <= 0 {
+		return nil
+	}
+	interval := p.backoff
+	for i := 1; i < attempts; i++ {
+		if p.maxBackoff > 0 && interval >= p.maxBackoff {
+			break
+		}
+		interval *= time.Duration(p.factor)
+	}
+	if p.maxBackoff > 0 && interval > p.maxBackoff {
+		interval = p.maxBackoff
+	}
+	next := metav1.NewTime(now.Add(interval))
+	return &next
+}
+
+// isStepFailed checks whether the step is failed and won't be executed anymore.
+func isStepFailed(status common.WorkflowStepStatus) bool {
+	if status.Phase == common.WorkflowStepPhaseTimedOut {
+		return true
+	}
+	return status.Phase == common.WorkflowStepPhaseFailed &&
+		(status.Reason == StatusReasonRetriesExhausted || status.Reason == wfTypes.StatusReasonSubStepsFailed ||
+			status.Reason == wfTypes.StatusReasonApprovalExpired || status.Reason == StatusReasonCondition)
+}
+
+// waitingForRetry checks whether the failed step is still in its backoff interval.
+func waitingForRetry(status common.WorkflowStepStatus, now time.Time) bool {
+	return status.Phase == common.WorkflowStepPhaseFailed && status.NextRetryTime != nil && now.Before(status.NextRetryTime.Time)
+}
+
+// getStepPolicy returns the policy of the step defined in application workflow, nil if the step has no policy.
+func (e *engine) getStepPolicy(name string) (*stepPolicy, error) {
+	step := e.getStepSpec(name)
+	if step == nil {
+		return nil, nil
+	}
+	p, err := newStepPolicy(*step)
+	if err != nil || !p.hasSettings {
+		return nil, err
+	}
+	return p, nil
+}
+
+// checkStepTimeout marks the unfinished step as timedOut if the step exceeds its timeout.
+func (e *engine) checkStepTimeout(policy *stepPolicy, name string) {
+	status := e.getStepStatus(name)
+	if policy == nil || status == nil || status.Phase == common.WorkflowStepPhaseSucceeded {
+		return
+	}
+	if policy.timedOut(*status, time.Now()) {
+		status.Phase = common.WorkflowStepPhaseTimedOut
+		status.Reason = StatusReasonTimeout
+		status.Message = fmt.Sprintf("step timed out after %s", policy.timeout)
+		status.NextRetryTime = nil
+	}
+}
+
+// checkStepPolicy is called after the step is executed, it checks the timeout of the unfinished step
+// and schedules the retry of the failed step.
+func (e *engine) checkStepPolicy(policy *stepPolicy, name string) {
+	e.checkStepTimeout(policy, name)
+	status := e.getStepStatus(name)
+	if policy == nil || status == nil || status.Phase != common.WorkflowStepPhaseFailed {
+		return
+	}
+	if policy.retriesExhausted(*status) {
+		status.Reason = StatusReasonRetriesExhausted
+		status.NextRetryTime = nil
+		return
+	}
+	status.NextRetryTime = policy.nextRetryTime(status.Attempts, time.Now())
+}
+
+func (e *engine) getStepStatus(name string) *common.WorkflowStepStatus {
+	for i := range e.status.Steps {
+		if e.status.Steps[i].Name == name {
+			return &e.status.Steps[i]
+		}
+	}
+	return nil
+}
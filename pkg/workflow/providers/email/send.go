@@ -20,11 +20,14 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/pkg/errors"
 	"gopkg.in/gomail.v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/pkg/cue/model/value"
 	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers/secret"
 	"github.com/oam-dev/kubevela/pkg/workflow/types"
 )
 
@@ -34,14 +37,15 @@ const (
 )
 
 type provider struct {
+	cli client.Client
 }
 
 type sender struct {
-	Address  string `json:"address"`
-	Alias    string `json:"alias,omitempty"`
-	Password string `json:"password"`
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
+	Address  string       `json:"address"`
+	Alias    string       `json:"alias,omitempty"`
+	Password secret.Value `json:"password"`
+	Host     string       `json:"host"`
+	Port     int          `json:"port"`
 }
 
 type content struct {
@@ -87,6 +91,11 @@ func (h *provider) Send(ctx wfContext.Context, v *value.Value, act types.Action)
 	if err := s.UnmarshalTo(senderValue); err != nil {
 		return err
 	}
+	password, err := senderValue.Password.Resolve(ctx, h.cli)
+	if err != nil {
+		emailRoutine.Delete(id)
+		return errors.WithMessage(err, "resolve password of the sender")
+	}
 
 	r, err := v.LookupValue("to")
 	if err != nil {
@@ -112,7 +121,7 @@ func (h *provider) Send(ctx wfContext.Context, v *value.Value, act types.Action)
 	m.SetHeader("Subject", contentValue.Subject)
 	m.SetBody("text/html", contentValue.Body)
 
-	dial := gomail.NewDialer(senderValue.Host, senderValue.Port, senderValue.Address, senderValue.Password)
+	dial := gomail.NewDialer(senderValue.Host, senderValue.Port, senderValue.Address, password)
 	go func() {
 		if routine, ok := emailRoutine.Load(id); ok && routine == "initializing" {
 			emailRoutine.Store(id, "sending")
@@ -128,8 +137,8 @@ func (h *provider) Send(ctx wfContext.Context, v *value.Value, act types.Action)
 }
 
 // Install register handlers to provider discover.
-func Install(p providers.Providers) {
-	prd := &provider{}
+func Install(p providers.Providers, cli client.Client) {
+	prd := &provider{cli: cli}
 	p.Register(ProviderName, map[string]providers.Handler{
 		"send": prd.Send,
 	})
@@ -154,7 +154,7 @@ stepID: "send-fail"
 
 func TestInstall(t *testing.T) {
 	p := providers.NewProviders()
-	Install(p)
+	Install(p, nil)
 	h, ok := p.GetHandler("email", "send")
 	r := require.New(t)
 	r.Equal(ok, true)
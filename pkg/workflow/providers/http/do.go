@@ -17,13 +17,18 @@ limitations under the License.
 package http
 
 import (
+	"encoding/json"
+
 	"cuelang.org/go/cue"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/pkg/builtin"
 	"github.com/oam-dev/kubevela/pkg/builtin/registry"
 	"github.com/oam-dev/kubevela/pkg/cue/model/value"
 	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers/secret"
 	"github.com/oam-dev/kubevela/pkg/workflow/types"
 )
 
@@ -33,22 +38,99 @@ const (
 )
 
 type provider struct {
+	cli client.Client
+}
+
+type httpRequest struct {
+	Body    *string                 `json:"body,omitempty"`
+	Header  map[string]secret.Value `json:"header,omitempty"`
+	Trailer map[string]string       `json:"trailer,omitempty"`
 }
 
 // Do process http request.
 func (h *provider) Do(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	obj, secrets, err := h.resolveSecrets(ctx, v)
+	if err != nil {
+		return err
+	}
 	ret, err := builtin.RunTaskByKey("http", cue.Value{}, &registry.Meta{
-		Obj: v.CueValue(),
+		Obj: obj,
 	})
 	if err != nil {
-		return err
+		// the error may carry the url, e.g. the url.Error of the http client
+		return errors.New(secret.Redact(err.Error(), secrets...))
 	}
 	return v.FillObject(ret, "response")
 }
 
+// resolveSecrets returns the request in which the url and the headers are normalized to plain strings, the values
+// referred from secrets are resolved and returned as well to be redacted from the errors. The resolved request is
+// only used to send the request and never filled back, so the credentials are not persisted in the workflow context.
+func (h *provider) resolveSecrets(ctx wfContext.Context, v *value.Value) (cue.Value, []string, error) {
+	var (
+		u       secret.Value
+		req     httpRequest
+		secrets []string
+	)
+	resolve := func(sv secret.Value) (string, error) {
+		s, err := sv.Resolve(ctx, h.cli)
+		if err == nil && sv.SecretRef != nil && s != "" {
+			secrets = append(secrets, s)
+		}
+		return s, err
+	}
+
+	uv, err := v.LookupValue("url")
+	if err != nil {
+		return cue.Value{}, nil, errors.WithMessage(err, "lookup url")
+	}
+	if err := uv.UnmarshalTo(&u); err != nil {
+		return cue.Value{}, nil, err
+	}
+	url, err := resolve(u)
+	if err != nil {
+		return cue.Value{}, nil, errors.WithMessage(err, "resolve url")
+	}
+	obj := map[string]interface{}{"url": url}
+	if method, err := v.CueValue().Lookup("method").String(); err == nil {
+		obj["method"] = method
+	}
+	if rv, err := v.LookupValue("request"); err == nil {
+		if err := rv.UnmarshalTo(&req); err != nil {
+			return cue.Value{}, nil, err
+		}
+		request := map[string]interface{}{}
+		if req.Body != nil {
+			request["body"] = *req.Body
+		}
+		if req.Header != nil {
+			header := map[string]string{}
+			for k, hv := range req.Header {
+				if header[k], err = resolve(hv); err != nil {
+					return cue.Value{}, nil, errors.WithMessagef(err, "resolve header %s", k)
+				}
+			}
+			request["header"] = header
+		}
+		if req.Trailer != nil {
+			request["trailer"] = req.Trailer
+		}
+		obj["request"] = request
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return cue.Value{}, nil, err
+	}
+	ov, err := value.NewValue(string(b), nil, "")
+	if err != nil {
+		return cue.Value{}, nil, err
+	}
+	return ov.CueValue(), secrets, nil
+}
+
 // Install register handlers to provider discover.
-func Install(p providers.Providers) {
-	prd := &provider{}
+func Install(p providers.Providers, cli client.Client) {
+	prd := &provider{cli: cli}
 	p.Register(ProviderName, map[string]providers.Handler{
 		"do": prd.Do,
 	})
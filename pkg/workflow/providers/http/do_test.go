@@ -19,13 +19,19 @@ package http
 import (
 	"io"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+	"github.com/oam-dev/kubevela/pkg/workflow/types"
 )
 
 func TestHttpDo(t *testing.T) {
@@ -98,11 +104,82 @@ request:{
 		assert.NilError(t, err, tName)
 		assert.Equal(t, ret, tCase.expectedBody, tName)
 	}
+
+	// the url and headers referred from the secret are resolved only for the request.
+	cli := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+		Data: map[string][]byte{
+			"url":   []byte("http://127.0.0.1:1229/auth"),
+			"token": []byte("Bearer my-token"),
+		},
+	}).Build()
+	wfCtx, err := wfContext.NewContext(cli, "default", "app", "")
+	assert.NilError(t, err)
+	meta, err := value.NewValue(`namespace: "default"`, nil, "")
+	assert.NilError(t, err)
+	assert.NilError(t, wfCtx.SetVar(meta, types.ContextKeyMetadata))
+	v, err := value.NewValue(`
+method: "POST"
+url: secretRef: {name: "auth", key: "url"}
+request: {
+	body: "I am vela"
+	header: {
+		"Content-Type": "text/plain; charset=utf-8"
+		"Authorization": secretRef: {name: "auth", key: "token"}
+	}
+}`, nil, "")
+	assert.NilError(t, err)
+	prd := &provider{cli: cli}
+	assert.NilError(t, prd.Do(wfCtx, v, nil))
+	body, err := v.LookupValue("response", "body")
+	assert.NilError(t, err)
+	ret, err := body.CueValue().String()
+	assert.NilError(t, err)
+	assert.Equal(t, ret, "I am vela")
+	s, err := v.String()
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(s, "my-token"))
+
+	// the plain values given as {value: ...} are sent as they are.
+	v, err = value.NewValue(`
+method: "POST"
+url: value: "http://127.0.0.1:1229/auth"
+request: {
+	body: "I am vela"
+	header: "Authorization": value: "Bearer my-token"
+}`, nil, "")
+	assert.NilError(t, err)
+	assert.NilError(t, prd.Do(wfCtx, v, nil))
+	body, err = v.LookupValue("response", "body")
+	assert.NilError(t, err)
+	ret, err = body.CueValue().String()
+	assert.NilError(t, err)
+	assert.Equal(t, ret, "I am vela")
+
+	// the url resolved from the secret is redacted in the error.
+	cli = fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+		Data:       map[string][]byte{"url": []byte("http://127.0.0.1:1/my-token")},
+	}).Build()
+	prd = &provider{cli: cli}
+	v, err = value.NewValue(`
+method: "GET"
+url: secretRef: {name: "auth", key: "url"}`, nil, "")
+	assert.NilError(t, err)
+	err = prd.Do(wfCtx, v, nil)
+	assert.Assert(t, err != nil)
+	assert.Assert(t, !strings.Contains(err.Error(), "my-token"), err.Error())
+	v, err = value.NewValue(`
+method: "GET"
+url: "http://127.0.0.1:1229/hello"
+request: header: "Authorization": secretRef: {name: "auth", key: "not-exist"}`, nil, "")
+	assert.NilError(t, err)
+	assert.Error(t, prd.Do(wfCtx, v, nil), "resolve header Authorization: key not-exist not found in secret default/auth")
 }
 
 func TestInstall(t *testing.T) {
 	p := providers.NewProviders()
-	Install(p)
+	Install(p, nil)
 	h, ok := p.GetHandler("http", "do")
 	assert.Equal(t, ok, true)
 	assert.Equal(t, h != nil, true)
@@ -112,6 +189,14 @@ func runMockServer(shutdown chan struct{}) {
 	http.HandleFunc("/hello", func(w http.ResponseWriter, req *http.Request) {
 		w.Write([]byte("hello"))
 	})
+	http.HandleFunc("/auth", func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer my-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		bt, _ := io.ReadAll(req.Body)
+		w.Write(bt)
+	})
 	http.HandleFunc("/echo", func(w http.ResponseWriter, req *http.Request) {
 		bt, _ := io.ReadAll(req.Body)
 		w.Write(bt)
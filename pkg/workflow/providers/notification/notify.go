@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers/secret"
+	"github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+const (
+	// ProviderName is provider name for install.
+	ProviderName = "notification"
+)
+
+const (
+	// TypeSlack sends the message to the slack incoming webhook.
+	TypeSlack = "slack"
+	// TypeDingTalk sends the message to the dingtalk robot webhook.
+	TypeDingTalk = "dingding"
+	// TypeLark sends the message to the lark(feishu) bot webhook.
+	TypeLark = "lark"
+	// TypeWebhook posts the message as json to any webhook.
+	TypeWebhook = "webhook"
+)
+
+// defaultTimeout bounds the request, since the notification is sent in the reconcile loop of the application controller.
+// The notification is sent only once in a reconcile, the failed step is retried with its retries and backoff.
+const defaultTimeout = 10 * time.Second
+
+type notification struct {
+	Type    string                 `json:"type"`
+	URL     secret.Value           `json:"url"`
+	Message map[string]interface{} `json:"message"`
+}
+
+type response struct {
+	StatusCode int    `json:"statusCode"`
+	Body       string `json:"body"`
+}
+
+type provider struct {
+	cli        client.Client
+	httpClient *http.Client
+}
+
+// Notify sends the message to the webhook of slack, dingtalk, lark or a generic json webhook.
+// The string fields in the message are rendered as go templates with the workflow context.
+func (p *provider) Notify(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	n := &notification{}
+	if err := v.UnmarshalTo(n); err != nil {
+		return err
+	}
+	switch n.Type {
+	case TypeSlack, TypeDingTalk, TypeLark, TypeWebhook:
+	default:
+		return errors.Errorf("unknown notification type %s", n.Type)
+	}
+
+	data, err := templateData(ctx)
+	if err != nil {
+		return err
+	}
+	url, err := n.URL.Resolve(ctx, p.cli)
+	if err != nil {
+		return errors.WithMessage(err, "resolve url")
+	}
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return errors.New("url of the notification is not set")
+	}
+	msg, err := render(n.Message, data)
+	if err != nil {
+		return errors.WithMessage(err, "render message")
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.send(url, n.Type, body)
+	if err != nil {
+		if n.URL.SecretRef != nil {
+			// the error of the http client carries the url
+			err = errors.New(secret.Redact(err.Error(), url))
+		}
+		return errors.WithMessagef(err, "send %s notification", n.Type)
+	}
+	return v.FillObject(resp, "response")
+}
+
+// send posts the message to the url.
+func (p *provider) send(url, typ string, body []byte) (*response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	ret := &response{StatusCode: resp.StatusCode, Body: string(b)}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return ret, errors.Errorf("status code %d: %s", resp.StatusCode, ret.Body)
+	}
+	return ret, checkResponse(typ, b)
+}
+
+// checkResponse checks the error code in the response body of the im webhooks, which may fail with the status code 200.
+func checkResponse(typ string, body []byte) error {
+	result := struct {
+		ErrCode    *int   `json:"errcode"`
+		ErrMsg     string `json:"errmsg"`
+		Code       *int   `json:"code"`
+		Msg        string `json:"msg"`
+		StatusCode *int   `json:"StatusCode"`
+	}{}
+	switch typ {
+	case TypeDingTalk:
+		if err := json.Unmarshal(body, &result); err != nil {
+			return errors.WithMessage(err, "decode response")
+		}
+		if result.ErrCode != nil && *result.ErrCode != 0 {
+			return errors.Errorf("error code %d: %s", *result.ErrCode, result.ErrMsg)
+		}
+	case TypeLark:
+		if err := json.Unmarshal(body, &result); err != nil {
+			return errors.WithMessage(err, "decode response")
+		}
+		if result.Code != nil && *result.Code != 0 {
+			return errors.Errorf("error code %d: %s", *result.Code, result.Msg)
+		}
+		if result.StatusCode != nil && *result.StatusCode != 0 {
+			return errors.Errorf("error code %d", *result.StatusCode)
+		}
+	}
+	return nil
+}
+
+// templateData returns the workflow variables to render the message, the application metadata is under the key context.
+func templateData(ctx wfContext.Context) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	vars, err := ctx.GetVar()
+	if err != nil {
+		return data, nil
+	}
+	if err := vars.UnmarshalTo(&data); err != nil {
+		return nil, errors.WithMessage(err, "decode workflow context")
+	}
+	if meta, ok := data[types.ContextKeyMetadata]; ok {
+		data["context"] = meta
+		delete(data, types.ContextKeyMetadata)
+	}
+	return data, nil
+}
+
+func render(v interface{}, data map[string]interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case string:
+		if !strings.Contains(t, "{{") {
+			return t, nil
+		}
+		tmpl, err := template.New("message").Option("missingkey=error").Parse(t)
+		if err != nil {
+			return nil, err
+		}
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, data); err != nil {
+			return nil, err
+		}
+		return buf.String(), nil
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			r, err := render(item, data)
+			if err != nil {
+				return nil, err
+			}
+			ret[k] = r
+		}
+		return ret, nil
+	case []interface{}:
+		ret := make([]interface{}, len(t))
+		for i, item := range t {
+			r, err := render(item, data)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = r
+		}
+		return ret, nil
+	default:
+		return v, nil
+	}
+}
+
+// Install register handlers to provider discover.
+func Install(p providers.Providers, cli client.Client) {
+	prd := &provider{
+		cli:        cli,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+	p.Register(ProviderName, map[string]providers.Handler{
+		"notify": prd.Notify,
+	})
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers/mock"
+	"github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+func TestNotify(t *testing.T) {
+	r := require.New(t)
+	var (
+		received  map[string]interface{}
+		failTimes int
+		reply     string
+		code      int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("application/json", req.Header.Get("Content-Type"))
+		b, err := ioutil.ReadAll(req.Body)
+		r.NoError(err)
+		received = map[string]interface{}{}
+		r.NoError(json.Unmarshal(b, &received))
+		if failTimes > 0 {
+			failTimes--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(reply))
+	}))
+	defer srv.Close()
+
+	cli := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: "default"},
+		Data:       map[string][]byte{"url": []byte(srv.URL + "\n")},
+	}).Build()
+	wfCtx, err := wfContext.NewContext(cli, "default", "app", "")
+	r.NoError(err)
+	meta, err := value.NewValue(`name: "app", namespace: "default"`, nil, "")
+	r.NoError(err)
+	r.NoError(wfCtx.SetVar(meta, types.ContextKeyMetadata))
+	ip, err := value.NewValue(`"1.1.1.1"`, nil, "")
+	r.NoError(err)
+	r.NoError(wfCtx.SetVar(ip, "podIP"))
+
+	prd := &provider{cli: cli, httpClient: srv.Client()}
+
+	testCases := map[string]struct {
+		params    string
+		failTimes int
+		code      int
+		reply     string
+		expected  map[string]interface{}
+		errMsg    string
+	}{
+		"slack": {
+			params:   `type: "slack", message: {text: "{{ .context.name }} is running on {{ .podIP }}"}`,
+			reply:    "ok",
+			expected: map[string]interface{}{"text": "app is running on 1.1.1.1"},
+		},
+		"dingding": {
+			params: `type: "dingding", message: {msgtype: "text", text: content: "hello {{ .context.namespace }}"}`,
+			reply:  `{"errcode":0,"errmsg":"ok"}`,
+			expected: map[string]interface{}{
+				"msgtype": "text",
+				"text":    map[string]interface{}{"content": "hello default"},
+			},
+		},
+		"dingding-error": {
+			params: `type: "dingding", message: {msgtype: "text", text: content: "hello"}`,
+			reply:  `{"errcode":310000,"errmsg":"keywords not in content"}`,
+			errMsg: "send dingding notification: error code 310000: keywords not in content",
+		},
+		"lark": {
+			params: `type: "lark", message: {msg_type: "text", content: text: "hello"}`,
+			reply:  `{"StatusCode":0,"StatusMessage":"success"}`,
+			expected: map[string]interface{}{
+				"msg_type": "text",
+				"content":  map[string]interface{}{"text": "hello"},
+			},
+		},
+		"lark-error": {
+			params: `type: "lark", message: {msg_type: "text", content: text: "hello"}`,
+			reply:  `{"code":19021,"msg":"sign match fail"}`,
+			errMsg: "send lark notification: error code 19021: sign match fail",
+		},
+		"webhook": {
+			params: `type: "webhook", message: {event: "deployed", items: ["{{ .podIP }}"]}`,
+			expected: map[string]interface{}{
+				"event": "deployed",
+				"items": []interface{}{"1.1.1.1"},
+			},
+		},
+		"webhook-unavailable": {
+			// the notification is sent only once, the step is retried by the workflow
+			params:    `type: "webhook", message: {event: "deployed"}`,
+			failTimes: 2,
+			errMsg:    "send webhook notification: status code 500: ",
+		},
+		"webhook-bad-request": {
+			params: `type: "webhook", message: {event: "deployed"}`,
+			code:   http.StatusBadRequest,
+			reply:  "bad request",
+			errMsg: "send webhook notification: status code 400: bad request",
+		},
+		"missing-key": {
+			params: `type: "webhook", message: {text: "{{ .notExist }}"}`,
+			errMsg: `render message: template: message:1:3: executing "message" at <.notExist>: map has no entry for key "notExist"`,
+		},
+		"unknown-type": {
+			params: `type: "wechat", message: {text: "hello"}`,
+			errMsg: "unknown notification type wechat",
+		},
+	}
+
+	for name, tc := range testCases {
+		for _, url := range []string{fmt.Sprintf(`url: value: "%s"`, srv.URL), `url: secretRef: {name: "webhook", key: "url"}`} {
+			received, failTimes, reply, code = nil, tc.failTimes, tc.reply, tc.code
+			if code == 0 {
+				code = http.StatusOK
+			}
+			v, err := value.NewValue(tc.params+"\n"+url, nil, "")
+			r.NoError(err)
+			err = prd.Notify(wfCtx, v, &mock.Action{})
+			if tc.errMsg != "" {
+				r.EqualError(err, tc.errMsg, name)
+				continue
+			}
+			r.NoError(err, name)
+			r.Equal(tc.expected, received, name)
+			statusCode, err := v.LookupValue("response", "statusCode")
+			r.NoError(err)
+			s, err := statusCode.CueValue().Int64()
+			r.NoError(err)
+			r.Equal(int64(http.StatusOK), s, name)
+		}
+	}
+
+	v, err := value.NewValue(`type: "slack", message: {text: "hello"}, url: secretRef: {name: "not-exist", key: "url"}`, nil, "")
+	r.NoError(err)
+	r.Error(prd.Notify(wfCtx, v, &mock.Action{}))
+}
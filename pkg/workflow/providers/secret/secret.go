@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+// Ref refers to a key of the Secret in the namespace of the application.
+type Ref struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	// Cluster is the cluster where the secret locates, the secret is read from the control plane if not set.
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// Value is a credential of the provider, which is given in plain text or referred from a secret.
+// The referred secret is only resolved when the provider runs, so that the credential is kept
+// out of the application, the workflow context and the debug info.
+type Value struct {
+	Value     string `json:"value,omitempty"`
+	SecretRef *Ref   `json:"secretRef,omitempty"`
+}
+
+// UnmarshalJSON decodes the value from a plain string or a struct with value or secretRef.
+func (v *Value) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		v.Value = s
+		v.SecretRef = nil
+		return nil
+	}
+	type plain Value
+	return json.Unmarshal(b, (*plain)(v))
+}
+
+// Resolve returns the plain text of the value, the referred secret is read with the client
+// in the namespace of the application.
+func (v Value) Resolve(ctx wfContext.Context, cli client.Client) (string, error) {
+	if v.SecretRef == nil {
+		return v.Value, nil
+	}
+	if cli == nil {
+		return "", errors.Errorf("can't read secret %s without client", v.SecretRef.Name)
+	}
+	namespace, err := appNamespace(ctx)
+	if err != nil {
+		return "", err
+	}
+	return Read(cli, namespace, v.SecretRef)
+}
+
+// Read reads the value of the key in the referred secret.
+func Read(cli client.Client, namespace string, ref *Ref) (string, error) {
+	ctx := context.Background()
+	if ref.Cluster != "" {
+		ctx = multicluster.ContextWithClusterName(ctx, ref.Cluster)
+	}
+	secret := &corev1.Secret{}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return "", errors.WithMessagef(err, "get secret %s/%s", namespace, ref.Name)
+	}
+	data, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", errors.Errorf("key %s not found in secret %s/%s", ref.Key, namespace, ref.Name)
+	}
+	return string(data), nil
+}
+
+// Redact masks the resolved secret values in the message, it's used to keep the credentials out of the errors
+// which are recorded in the workflow status and the debug info.
+func Redact(msg string, secrets ...string) string {
+	for _, s := range secrets {
+		if s != "" {
+			msg = strings.ReplaceAll(msg, s, "******")
+		}
+	}
+	return msg
+}
+
+func appNamespace(ctx wfContext.Context) (string, error) {
+	if ctx == nil {
+		return "", errors.New("workflow context is not set")
+	}
+	v, err := ctx.GetVar(types.ContextKeyMetadata, "namespace")
+	if err != nil {
+		return "", errors.WithMessage(err, "get namespace of the application")
+	}
+	return v.CueValue().String()
+}
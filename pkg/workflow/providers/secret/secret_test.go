@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+func TestValue(t *testing.T) {
+	r := require.New(t)
+	cli := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cred", Namespace: "test"},
+		Data:       map[string][]byte{"password": []byte("pwd")},
+	}).Build()
+	wfCtx, err := wfContext.NewContext(cli, "test", "app", "")
+	r.NoError(err)
+	meta, err := value.NewValue(`name: "app", namespace: "test"`, nil, "")
+	r.NoError(err)
+	r.NoError(wfCtx.SetVar(meta, types.ContextKeyMetadata))
+
+	testCases := map[string]struct {
+		raw      string
+		expected string
+		errMsg   string
+	}{
+		"plain": {
+			raw:      `"plain"`,
+			expected: "plain",
+		},
+		"value": {
+			raw:      `{"value":"plain"}`,
+			expected: "plain",
+		},
+		"secret": {
+			raw:      `{"secretRef":{"name":"cred","key":"password"}}`,
+			expected: "pwd",
+		},
+		"secret-not-found": {
+			raw:    `{"secretRef":{"name":"not-exist","key":"password"}}`,
+			errMsg: `get secret test/not-exist: secrets "not-exist" not found`,
+		},
+		"key-not-found": {
+			raw:    `{"secretRef":{"name":"cred","key":"token"}}`,
+			errMsg: "key token not found in secret test/cred",
+		},
+	}
+	for name, tc := range testCases {
+		v := Value{}
+		r.NoError(json.Unmarshal([]byte(tc.raw), &v), name)
+		s, err := v.Resolve(wfCtx, cli)
+		if tc.errMsg != "" {
+			r.EqualError(err, tc.errMsg, name)
+			continue
+		}
+		r.NoError(err, name)
+		r.Equal(tc.expected, s, name)
+	}
+
+	v := Value{SecretRef: &Ref{Name: "cred", Key: "password"}}
+	_, err = v.Resolve(nil, cli)
+	r.EqualError(err, "workflow context is not set")
+	_, err = v.Resolve(wfCtx, nil)
+	r.EqualError(err, "can't read secret cred without client")
+}
+
+func TestRedact(t *testing.T) {
+	r := require.New(t)
+	r.Equal(`Get "https://******": timeout`, Redact(`Get "https://hooks.slack.com/my-token": timeout`, "hooks.slack.com/my-token", ""))
+	r.Equal("no secret", Redact("no secret"))
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	oamcore "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+const applyComponentStepType = "apply-component"
+
+// RetryStep resets the status of the failed step and the steps following it, which are the later steps in
+// StepByStep mode or the steps depending on it in DAG mode, so that only these steps are executed again.
+// The status of the other steps and their outputs in the workflow context are kept.
+// The step can be a sub step of the step group, then the group is executed again with its succeeded sub steps kept.
+func RetryStep(app *oamcore.Application, stepName string) error {
+	status := app.Status.Workflow
+	if status == nil {
+		return errors.New("the workflow is not started")
+	}
+	var specs []oamcore.WorkflowStep
+	if app.Spec.Workflow != nil {
+		specs = app.Spec.Workflow.Steps
+	}
+	comps := app.Spec.Components
+	dagMode := status.Mode == common.WorkflowModeDAG
+
+	if ss := findStepStatus(status.Steps, stepName); ss != nil {
+		if !isStepRetryable(ss.Phase) {
+			return errors.Errorf("step %s is %s, only the failed step can be retried", stepName, ss.Phase)
+		}
+		status.Steps = resetSteps(status.Steps, stepsToRetry(specs, comps, dagMode, stepName))
+	} else {
+		group, sub := findSubStepStatus(status.Steps, stepName)
+		if sub == nil {
+			return errors.Errorf("step %s is not executed", stepName)
+		}
+		if !isStepRetryable(sub.Phase) {
+			return errors.Errorf("step %s is %s, only the failed step can be retried", stepName, sub.Phase)
+		}
+		var subSpecs []oamcore.WorkflowStep
+		for _, spec := range specs {
+			if spec.Name == group.Name {
+				for _, s := range spec.SubSteps {
+					subSpecs = append(subSpecs, s.WorkflowStep())
+				}
+			}
+		}
+		var subSteps []common.WorkflowSubStepStatus
+		retrySubSteps := stepsToRetry(subSpecs, comps, group.SubSteps.Mode == common.WorkflowModeDAG, stepName)
+		for _, ss := range group.SubSteps.Steps {
+			if !retrySubSteps[ss.Name] {
+				subSteps = append(subSteps, ss)
+			}
+		}
+		group.SubSteps.Steps = subSteps
+		group.Phase = common.WorkflowStepPhaseRunning
+		group.Reason = ""
+		group.Message = ""
+		group.NextRetryTime = nil
+		// the group itself is kept to recover the status of its sub steps.
+		retrySteps := stepsToRetry(specs, comps, dagMode, group.Name)
+		delete(retrySteps, group.Name)
+		status.Steps = resetSteps(status.Steps, retrySteps)
+	}
+
+	status.Suspend = false
+	status.Terminated = false
+	status.Finished = false
+	// the retried execution is recorded as a new workflow run.
+	status.StartTime = metav1.NewTime(time.Now())
+	return nil
+}
+
+func isStepRetryable(phase common.WorkflowStepPhase) bool {
+	return phase == common.WorkflowStepPhaseFailed || phase == common.WorkflowStepPhaseTimedOut
+}
+
+func findStepStatus(steps []common.WorkflowStepStatus, name string) *common.WorkflowStepStatus {
+	for i := range steps {
+		if steps[i].Name == name {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+func findSubStepStatus(steps []common.WorkflowStepStatus, name string) (*common.WorkflowStepStatus, *common.WorkflowSubStepStatus) {
+	for i := range steps {
+		if steps[i].SubSteps == nil {
+			continue
+		}
+		for j := range steps[i].SubSteps.Steps {
+			if steps[i].SubSteps.Steps[j].Name == name {
+				return &steps[i], &steps[i].SubSteps.Steps[j]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// stepsToRetry returns the names of the step and the steps following it.
+// In DAG mode, the dependents are matched in the way the workflow engine resolves them.
+func stepsToRetry(specs []oamcore.WorkflowStep, comps []common.ApplicationComponent, dagMode bool, name string) map[string]bool {
+	names := map[string]bool{name: true}
+	if !dagMode {
+		found := false
+		for _, spec := range specs {
+			found = found || spec.Name == name
+			if found {
+				names[spec.Name] = true
+			}
+		}
+		return names
+	}
+	var steps []dagStep
+	for _, spec := range specs {
+		steps = append(steps, resolveDAGStep(spec, comps))
+	}
+	readyNames, outputs := map[string]bool{}, map[string]bool{}
+	retry := func(step dagStep) {
+		names[step.name] = true
+		if step.readyName != "" {
+			readyNames[step.readyName] = true
+		}
+		for _, output := range step.outputs {
+			outputs[output.Name] = true
+		}
+	}
+	for _, step := range steps {
+		if step.name == name {
+			retry(step)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, step := range steps {
+			if names[step.name] {
+				continue
+			}
+			depends := false
+			for _, dep := range step.dependsOn {
+				depends = depends || readyNames[dep]
+			}
+			for _, input := range step.inputs {
+				depends = depends || outputs[strings.Split(input.From, ".")[0]]
+			}
+			if depends {
+				retry(step)
+				changed = true
+			}
+		}
+	}
+	return names
+}
+
+// dagStep is the step as the workflow engine resolves it in DAG mode. The step is marked as ready by the name
+// in its properties, and the apply-component step takes the name, dependsOn, inputs and outputs of its component.
+type dagStep struct {
+	name      string
+	readyName string
+	dependsOn []string
+	inputs    common.StepInputs
+	outputs   common.StepOutputs
+}
+
+func resolveDAGStep(spec oamcore.WorkflowStep, comps []common.ApplicationComponent) dagStep {
+	step := dagStep{name: spec.Name, dependsOn: spec.DependsOn, inputs: spec.Inputs, outputs: spec.Outputs}
+	if spec.Properties == nil {
+		return step
+	}
+	o := struct {
+		Name      string `json:"name"`
+		Component string `json:"component"`
+	}{}
+	js, err := common.RawExtensionPointer{RawExtension: spec.Properties}.MarshalJSON()
+	if err != nil {
+		return step
+	}
+	if err := json.Unmarshal(js, &o); err != nil {
+		return step
+	}
+	step.readyName = o.Name
+	if spec.Type != applyComponentStepType {
+		return step
+	}
+	for _, c := range comps {
+		if c.Name == o.Component {
+			step.readyName = c.Name
+			step.dependsOn = append(append([]string{}, step.dependsOn...), c.DependsOn...)
+			step.inputs = append(append(common.StepInputs{}, step.inputs...), c.Inputs...)
+			step.outputs = append(append(common.StepOutputs{}, step.outputs...), c.Outputs...)
+		}
+	}
+	return step
+}
+
+func resetSteps(steps []common.WorkflowStepStatus, names map[string]bool) []common.WorkflowStepStatus {
+	var kept []common.WorkflowStepStatus
+	for _, ss := range steps {
+		if !names[ss.Name] {
+			kept = append(kept, ss)
+		}
+	}
+	return kept
+}
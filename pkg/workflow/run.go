@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	oamcore "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+)
+
+// RecordRun persists the finished workflow execution of the application as a WorkflowRun,
+// the oldest runs of the application over the limit are garbage collected.
+func RecordRun(ctx context.Context, cli client.Client, app *oamcore.Application, state common.WorkflowState, limit int) error {
+	status := app.Status.Workflow
+	if status == nil || limit <= 0 {
+		return nil
+	}
+	startTime := status.StartTime
+	if startTime.IsZero() {
+		startTime = metav1.NewTime(time.Now())
+	}
+	run := &oamcore.WorkflowRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RunName(app.Name, status.AppRevision, startTime.Time),
+			Namespace: app.Namespace,
+			Labels:    map[string]string{oam.LabelAppName: app.Name},
+		},
+		Spec: oamcore.WorkflowRunSpec{
+			AppName:  app.Name,
+			Workflow: app.Spec.Workflow.DeepCopy(),
+		},
+		Status: oamcore.WorkflowRunStatus{
+			Phase:     state,
+			Mode:      status.Mode,
+			StartTime: startTime,
+			EndTime:   metav1.NewTime(time.Now()),
+			Steps:     status.DeepCopy().Steps,
+		},
+	}
+	if app.Status.LatestRevision != nil {
+		run.Spec.AppRevision = app.Status.LatestRevision.Name
+	}
+	ownerRef := metav1.NewControllerRef(app, oamcore.ApplicationKindVersionKind)
+	run.SetOwnerReferences([]metav1.OwnerReference{*ownerRef})
+
+	if status.ContextBackend != nil {
+		wfCtx, err := wfContext.LoadContext(cli, app.Namespace, app.Name)
+		if err != nil {
+			return errors.WithMessage(err, "load workflow context")
+		}
+		if vars, err := wfCtx.GetVar(); err == nil {
+			if run.Status.Outputs, err = vars.String(); err != nil {
+				return errors.WithMessage(err, "encode workflow outputs")
+			}
+		}
+	}
+
+	if err := cli.Create(ctx, run); err != nil && !kerrors.IsAlreadyExists(err) {
+		return errors.WithMessagef(err, "create workflow run %s/%s", run.Namespace, run.Name)
+	}
+	return gcRuns(ctx, cli, app, limit)
+}
+
+// RunName returns the name of the workflow run of the application which executes the revision and starts at the given time.
+// The start time is persisted in seconds, so the revision is hashed into the name to tell apart the runs starting in the same second.
+func RunName(appName, revision string, startTime time.Time) string {
+	h := fnv.New32a()
+	_, _ = fmt.Fprintf(h, "%s/%d", revision, startTime.Unix())
+	return fmt.Sprintf("%s-%d-%08x", appName, startTime.Unix(), h.Sum32())
+}
+
+// ListRuns lists the recorded workflow runs of the application, the latest run comes first.
+func ListRuns(ctx context.Context, cli client.Client, namespace, appName string) ([]oamcore.WorkflowRun, error) {
+	runs := &oamcore.WorkflowRunList{}
+	if err := cli.List(ctx, runs, client.InNamespace(namespace), client.MatchingLabels{oam.LabelAppName: appName}); err != nil {
+		return nil, errors.WithMessagef(err, "list workflow runs of application %s/%s", namespace, appName)
+	}
+	items := runs.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[j].Status.StartTime.Before(&items[i].Status.StartTime)
+	})
+	return items, nil
+}
+
+// gcRuns deletes the oldest workflow runs of the application over the limit.
+func gcRuns(ctx context.Context, cli client.Client, app *oamcore.Application, limit int) error {
+	runs, err := ListRuns(ctx, cli, app.Namespace, app.Name)
+	if err != nil {
+		return err
+	}
+	for i := limit; i < len(runs); i++ {
+		if err := cli.Delete(ctx, &runs[i]); err != nil && !kerrors.IsNotFound(err) {
+			return errors.WithMessagef(err, "delete workflow run %s/%s", runs[i].Namespace, runs[i].Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	oamcore "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestRecordRun(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	r.NoError(oamcore.AddToScheme(s))
+	cli := fake.NewClientBuilder().WithScheme(s).Build()
+
+	app := &oamcore.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "test-uid"},
+		Spec: oamcore.ApplicationSpec{
+			Workflow: &oamcore.Workflow{Steps: []oamcore.WorkflowStep{{Name: "s1", Type: "success"}}},
+		},
+		Status: common.AppStatus{
+			LatestRevision: &common.Revision{Name: "app-v1"},
+		},
+	}
+	r.NoError(RecordRun(ctx, cli, app, common.WorkflowStateSucceeded, 2))
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		app.Status.Workflow = &common.WorkflowStatus{
+			AppRevision: "app-v1:hash",
+			Mode:        common.WorkflowModeStep,
+			StartTime:   metav1.NewTime(start.Add(time.Duration(i) * time.Minute)),
+			Steps: []common.WorkflowStepStatus{{
+				Name:  "s1",
+				Type:  "success",
+				Phase: common.WorkflowStepPhaseSucceeded,
+			}},
+		}
+		r.NoError(RecordRun(ctx, cli, app, common.WorkflowStateSucceeded, 2))
+		// recording the same run again is a no-op.
+		r.NoError(RecordRun(ctx, cli, app, common.WorkflowStateSucceeded, 2))
+	}
+
+	runs, err := ListRuns(ctx, cli, "default", "app")
+	r.NoError(err)
+	r.Equal(2, len(runs))
+	r.Equal(RunName("app", "app-v1:hash", start.Add(2*time.Minute)), runs[0].Name)
+	r.Equal(RunName("app", "app-v1:hash", start.Add(time.Minute)), runs[1].Name)
+	r.Equal("app-v1", runs[0].Spec.AppRevision)
+	r.Equal(common.WorkflowStateSucceeded, runs[0].Status.Phase)
+	r.Equal(1, len(runs[0].Status.Steps))
+	r.Equal(1, len(runs[0].Spec.Workflow.Steps))
+	r.Equal("app", runs[0].OwnerReferences[0].Name)
+}
+
+func TestRunName(t *testing.T) {
+	r := require.New(t)
+	start := time.Now()
+	r.Equal(RunName("app", "app-v1:hash", start), RunName("app", "app-v1:hash", start.Truncate(time.Second)))
+	r.NotEqual(RunName("app", "app-v1:hash", start), RunName("app", "app-v2:hash", start))
+	r.NotEqual(RunName("app", "app-v1:hash", start), RunName("app", "app-v1:hash", start.Add(time.Second)))
+}
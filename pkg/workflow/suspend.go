@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+// NextResumeTime returns the earliest time the suspended workflow should be resumed automatically,
+// that's the resume time of the timed suspend steps or the expire time of the pending approvals.
+// It returns nil if the workflow can only be resumed manually.
+func NextResumeTime(status *common.WorkflowStatus) *metav1.Time {
+	if status == nil {
+		return nil
+	}
+	var next *metav1.Time
+	visit := func(phase common.WorkflowStepPhase, resumeTime *metav1.Time, approval *common.WorkflowStepApproval) {
+		if phase != common.WorkflowStepPhaseRunning {
+			return
+		}
+		t := resumeTime
+		if approval != nil && approval.ApproveTime == nil {
+			t = approval.ExpireTime
+		}
+		if t != nil && (next == nil || t.Before(next)) {
+			next = t
+		}
+	}
+	for _, ss := range status.Steps {
+		visit(ss.Phase, ss.ResumeTime, ss.Approval)
+		if ss.SubSteps != nil {
+			for _, sub := range ss.SubSteps.Steps {
+				visit(sub.Phase, sub.ResumeTime, sub.Approval)
+			}
+		}
+	}
+	return next
+}
+
+// ApproveSuspendedSteps records the approver on all the suspend steps waiting for approval, unverified should be
+// true if the approver is claimed by the client rather than authenticated. It returns false if there is no step waiting for approval.
+func ApproveSuspendedSteps(status *common.WorkflowStatus, approver string, unverified bool) bool {
+	if status == nil {
+		return false
+	}
+	var (
+		approved bool
+		now      = metav1.NewTime(time.Now())
+	)
+	approve := func(phase common.WorkflowStepPhase, approval *common.WorkflowStepApproval) {
+		if phase != common.WorkflowStepPhaseRunning || approval == nil || approval.ApproveTime != nil {
+			return
+		}
+		approval.Approver = approver
+		approval.Unverified = unverified
+		approval.ApproveTime = &now
+		approved = true
+	}
+	for i := range status.Steps {
+		ss := &status.Steps[i]
+		approve(ss.Phase, ss.Approval)
+		if ss.SubSteps != nil {
+			for j := range ss.SubSteps.Steps {
+				approve(ss.SubSteps.Steps[j].Phase, ss.SubSteps.Steps[j].Approval)
+			}
+		}
+	}
+	return approved
+}
+
+// shouldResume checks whether the suspended workflow reaches the time to be resumed automatically.
+func shouldResume(status *common.WorkflowStatus, now time.Time) bool {
+	next := NextResumeTime(status)
+	return next != nil && !now.Before(next.Time)
+}
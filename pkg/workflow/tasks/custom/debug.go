@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custom
+
+import (
+	"encoding/json"
+	"strings"
+
+	"cuelang.org/go/cue"
+)
+
+const (
+	// maxDebugValueLength limits the length of a value recorded in the debug info, the debug info is stored in
+	// the workflow context ConfigMap which is rewritten on every reconcile.
+	maxDebugValueLength = 4096
+	// maxDebugProviderCalls limits the number of provider calls recorded for a step.
+	maxDebugProviderCalls = 20
+
+	redactedValue = "******"
+	truncatedMark = "...(truncated)"
+)
+
+// sensitiveFields are the fields which may carry credentials, such as the secret.#Value fields of the providers,
+// the values under them are redacted in the debug info.
+var sensitiveFields = map[string]bool{
+	"url":           true,
+	"header":        true,
+	"password":      true,
+	"token":         true,
+	"secret":        true,
+	"authorization": true,
+}
+
+// debugString encodes the value for the debug info, the credentials are redacted and the result is truncated.
+// The fields which are not concrete yet are shown as their kinds.
+func debugString(v cue.Value) string {
+	b, err := json.MarshalIndent(debugValue(v, false), "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return truncate(string(b))
+}
+
+func debugValue(v cue.Value, redact bool) interface{} {
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		obj := map[string]interface{}{}
+		iter, err := v.Fields()
+		if err != nil {
+			return err.Error()
+		}
+		for iter.Next() {
+			label := iter.Label()
+			obj[label] = debugValue(iter.Value(), redact || sensitiveFields[strings.ToLower(label)])
+		}
+		return obj
+	case cue.ListKind:
+		list := []interface{}{}
+		iter, err := v.List()
+		if err != nil {
+			return err.Error()
+		}
+		for iter.Next() {
+			list = append(list, debugValue(iter.Value(), redact))
+		}
+		return list
+	}
+	if !v.IsConcrete() {
+		return v.IncompleteKind().String()
+	}
+	if redact {
+		return redactedValue
+	}
+	var x interface{}
+	if err := v.Decode(&x); err != nil {
+		return err.Error()
+	}
+	return x
+}
+
+func truncate(s string) string {
+	if len(s) <= maxDebugValueLength {
+		return s
+	}
+	return s[:maxDebugValueLength] + truncatedMark
+}
@@ -156,8 +156,13 @@ func (t *TaskLoader) makeTaskGenerator(templ string) (wfTypes.TaskGenerator, err
 				}
 			}
 			tracer := options.GetTracer(exec.wfStatus.ID, wfStep).AddTag("step_name", wfStep.Name, "step_type", wfStep.Type)
+			exec.debugInfo = &wfContext.StepDebugInfo{}
 			defer func() {
 				tracer.Commit(string(exec.status().Phase))
+				if exec.status().Phase == common.WorkflowStepPhaseFailed {
+					exec.debugInfo.Error = exec.status().Message
+				}
+				ctx.SetStepDebugInfo(wfStep.Name, exec.debugInfo)
 			}()
 
 			if t.runOptionsProcess != nil {
@@ -198,6 +203,9 @@ func (t *TaskLoader) makeTaskGenerator(templ string) (wfTypes.TaskGenerator, err
 
 			exec.tracer = tracer
 			if isDebugMode(taskv) {
+				if params != nil {
+					exec.debugInfo.Parameter = debugString(paramsValue.CueValue())
+				}
 				exec.printStep("workflowStepStart", "workflow", "", taskv)
 				defer exec.printStep("workflowStepEnd", "workflow", "", taskv)
 			}
@@ -242,7 +250,8 @@ type executor struct {
 	terminated bool
 	wait       bool
 
-	tracer monitorContext.Context
+	tracer    monitorContext.Context
+	debugInfo *wfContext.StepDebugInfo
 }
 
 // Suspend let workflow pause.
@@ -287,8 +296,16 @@ func (exec *executor) status() common.WorkflowStepStatus {
 }
 
 func (exec *executor) printStep(phase string, provider string, do string, v *value.Value) {
-	msg, _ := v.String()
+	msg := debugString(v.CueValue())
 	exec.tracer.Info("cue eval: "+msg, "phase", phase, "provider", provider, "do", do)
+	if exec.debugInfo != nil && len(exec.debugInfo.ProviderCalls) < maxDebugProviderCalls {
+		exec.debugInfo.ProviderCalls = append(exec.debugInfo.ProviderCalls, wfContext.ProviderCall{
+			Phase:    phase,
+			Provider: provider,
+			Do:       do,
+			Value:    msg,
+		})
+	}
 }
 
 // Handle process task-step value by provider and do.
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -112,6 +113,11 @@ myIP: value: "1.1.1.1"
 			Name: "steps",
 			Type: "steps",
 		},
+		{
+			Name:       "debug",
+			Type:       "debug",
+			Properties: &runtime.RawExtension{Raw: []byte(`{"url":"https://hooks.slack.com/services/my-token","message":"hello"}`)},
+		},
 	}
 
 	for _, step := range steps {
@@ -141,13 +147,49 @@ myIP: value: "1.1.1.1"
 		if step.Name == "execute" {
 			r.Equal(status.Phase, common.WorkflowStepPhaseFailed)
 			r.Equal(status.Reason, StatusReasonExecute)
+			r.Equal(status.Message, wfCtx.GetStepDebugInfo(step.Name).Error)
 			continue
 		}
 		r.Equal(status.Phase, common.WorkflowStepPhaseSucceeded)
+		if step.Name == "debug" {
+			info := wfCtx.GetStepDebugInfo(step.Name)
+			r.Contains(info.Parameter, "hello")
+			r.NotContains(info.Parameter, "my-token")
+			calls := info.ProviderCalls
+			r.Equal(2, len(calls))
+			r.Equal("workflowStepStart", calls[0].Phase)
+			r.Equal("workflowStepEnd", calls[1].Phase)
+			r.Contains(calls[1].Value, "process")
+			r.NotContains(calls[1].Value, "my-token")
+		} else {
+			// the debug info is only recorded in debug mode or when the step fails
+			r.Nil(wfCtx.GetStepDebugInfo(step.Name))
+		}
 	}
 
 }
 
+func TestDebugString(t *testing.T) {
+	r := require.New(t)
+	v, err := value.NewValue(`
+url: "https://hooks.slack.com/services/my-token"
+request: header: Authorization: "Bearer my-token"
+password: value: "my-token"
+response: body: string
+message: "hello"
+`, nil, "")
+	r.NoError(err)
+	s := debugString(v.CueValue())
+	r.NotContains(s, "my-token")
+	r.Contains(s, "hello")
+	r.Contains(s, `"body": "string"`)
+
+	v, err = value.NewValue(fmt.Sprintf("body: %q", strings.Repeat("a", 2*maxDebugValueLength)), nil, "")
+	r.NoError(err)
+	s = debugString(v.CueValue())
+	r.Equal(maxDebugValueLength+len(truncatedMark), len(s))
+}
+
 func TestErrCases(t *testing.T) {
 	wfCtx := newWorkflowContextForTest(t)
 	r := require.New(t)
@@ -476,6 +518,8 @@ output: xx
 		return fmt.Sprintf(templ, "executeFailed"), nil
 	case "ok":
 		return fmt.Sprintf(templ, "ok"), nil
+	case "debug":
+		return fmt.Sprintf(templ+"#debug: true\n", "ok"), nil
 	case "error":
 		return fmt.Sprintf(templ, "error"), nil
 	case "steps":
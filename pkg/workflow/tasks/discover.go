@@ -18,6 +18,7 @@ package tasks
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,6 +34,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/workflow/providers/email"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers/http"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers/kube"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers/notification"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers/time"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers/workspace"
 	"github.com/oam-dev/kubevela/pkg/workflow/tasks/custom"
@@ -65,10 +67,27 @@ func (td *taskDiscover) GetTaskGenerator(ctx context.Context, name string) (type
 	return nil, errors.Errorf("can't find task generator: %s", name)
 }
 
-func suspend(step v1beta1.WorkflowStep, opt *types.GeneratorOptions) (types.TaskRunner, error) {
-	return &suspendTaskRunner{
-		id:   opt.ID,
-		name: step.Name,
+func stepGroup(step v1beta1.WorkflowStep, opt *types.GeneratorOptions) (types.TaskRunner, error) {
+	props := struct {
+		Mode common.WorkflowMode `json:"mode"`
+	}{}
+	if step.Properties != nil && len(step.Properties.Raw) > 0 {
+		if err := json.Unmarshal(step.Properties.Raw, &props); err != nil {
+			return nil, errors.WithMessagef(err, "decode properties of step group %s", step.Name)
+		}
+	}
+	switch props.Mode {
+	case "":
+		props.Mode = common.WorkflowModeDAG
+	case common.WorkflowModeDAG, common.WorkflowModeStep:
+	default:
+		return nil, errors.Errorf("unknown mode %s of step group %s", props.Mode, step.Name)
+	}
+	return &stepGroupTaskRunner{
+		id:             opt.ID,
+		name:           step.Name,
+		mode:           props.Mode,
+		subTaskRunners: opt.SubTaskRunners,
 	}, nil
 }
 
@@ -76,41 +95,104 @@ func suspend(step v1beta1.WorkflowStep, opt *types.GeneratorOptions) (types.Task
 func NewTaskDiscover(providerHandlers providers.Providers, pd *packages.PackageDiscover, cli client.Client, dm discoverymapper.DiscoveryMapper) types.TaskDiscover {
 	// install builtin provider
 	workspace.Install(providerHandlers)
-	http.Install(providerHandlers)
+	http.Install(providerHandlers, cli)
 	convert.Install(providerHandlers)
-	email.Install(providerHandlers)
+	email.Install(providerHandlers, cli)
+	notification.Install(providerHandlers, cli)
 	templateLoader := template.NewWorkflowStepTemplateLoader(cli, dm)
 	return &taskDiscover{
 		builtins: map[string]types.TaskGenerator{
-			"suspend": suspend,
+			"suspend":    suspend,
+			"step-group": stepGroup,
 		},
 		remoteTaskDiscover: custom.NewTaskLoader(templateLoader.LoadTaskTemplate, pd, providerHandlers),
 		templateLoader:     templateLoader,
 	}
 }
 
-type suspendTaskRunner struct {
-	id   string
-	name string
+type stepGroupTaskRunner struct {
+	id             string
+	name           string
+	mode           common.WorkflowMode
+	subTaskRunners []types.TaskRunner
 }
 
-// Name return suspend step name.
-func (tr *suspendTaskRunner) Name() string {
+// Name return step group name.
+func (tr *stepGroupTaskRunner) Name() string {
 	return tr.name
 }
 
-// Run make workflow suspend.
-func (tr *suspendTaskRunner) Run(ctx wfContext.Context, options *types.TaskRunOptions) (common.WorkflowStepStatus, *types.Operation, error) {
-	return common.WorkflowStepStatus{
-		ID:    tr.id,
-		Name:  tr.name,
-		Type:  "suspend",
-		Phase: common.WorkflowStepPhaseSucceeded,
-	}, &types.Operation{Suspend: true}, nil
+// Run executes the sub steps of the step group.
+func (tr *stepGroupTaskRunner) Run(ctx wfContext.Context, options *types.TaskRunOptions) (common.WorkflowStepStatus, *types.Operation, error) {
+	if options.RunSteps == nil {
+		return common.WorkflowStepStatus{}, nil, errors.Errorf("step group %s can't run sub steps", tr.name)
+	}
+	subStatus, err := options.RunSteps(tr.mode == common.WorkflowModeDAG, tr.subTaskRunners...)
+	if err != nil {
+		return common.WorkflowStepStatus{}, nil, errors.WithMessagef(err, "run sub steps of step group %s", tr.name)
+	}
+	status := common.WorkflowStepStatus{
+		ID:       tr.id,
+		Name:     tr.name,
+		Type:     "step-group",
+		Phase:    common.WorkflowStepPhaseRunning,
+		SubSteps: &common.SubStepsStatus{Mode: tr.mode},
+	}
+	done, failed := true, false
+	for _, runner := range tr.subTaskRunners {
+		phase := common.WorkflowStepPhase("")
+		for _, ss := range subStatus.Steps {
+			if ss.Name == runner.Name() {
+				phase = ss.Phase
+				break
+			}
+		}
+		switch phase {
+		case common.WorkflowStepPhaseSucceeded, common.WorkflowStepPhaseSkipped:
+		case common.WorkflowStepPhaseFailed, common.WorkflowStepPhaseTimedOut:
+			done, failed = false, true
+		default:
+			done = false
+		}
+	}
+	for _, ss := range subStatus.Steps {
+		status.SubSteps.Steps = append(status.SubSteps.Steps, common.WorkflowSubStepStatus{
+			ID:               ss.ID,
+			Name:             ss.Name,
+			Type:             ss.Type,
+			Phase:            ss.Phase,
+			Message:          ss.Message,
+			Reason:           ss.Reason,
+			FirstExecuteTime: ss.FirstExecuteTime,
+			LastExecuteTime:  ss.LastExecuteTime,
+			Attempts:         ss.Attempts,
+			NextRetryTime:    ss.NextRetryTime,
+			ResumeTime:       ss.ResumeTime,
+			Approval:         ss.Approval,
+		})
+	}
+
+	operation := &types.Operation{}
+	switch {
+	case subStatus.Terminated && failed:
+		status.Phase = common.WorkflowStepPhaseFailed
+		status.Reason = types.StatusReasonSubStepsFailed
+		status.Message = "some sub steps are failed"
+	case subStatus.Terminated || subStatus.Suspend:
+		// the operation of sub steps takes effect on the whole workflow.
+		operation.Suspend = subStatus.Suspend
+		operation.Terminated = subStatus.Terminated
+		if done {
+			status.Phase = common.WorkflowStepPhaseSucceeded
+		}
+	case done:
+		status.Phase = common.WorkflowStepPhaseSucceeded
+	}
+	return status, operation, nil
 }
 
 // Pending check task should be executed or not.
-func (tr *suspendTaskRunner) Pending(ctx wfContext.Context) bool {
+func (tr *stepGroupTaskRunner) Pending(ctx wfContext.Context) bool {
 	return false
 }
 
@@ -122,9 +204,9 @@ func NewViewTaskDiscover(pd *packages.PackageDiscover, cli client.Client, apply
 	query.Install(handlerProviders, cli)
 	time.Install(handlerProviders)
 	kube.Install(handlerProviders, cli, apply, delete)
-	http.Install(handlerProviders)
+	http.Install(handlerProviders, cli)
 	convert.Install(handlerProviders)
-	email.Install(handlerProviders)
+	email.Install(handlerProviders, cli)
 
 	templateLoader := template.NewViewTemplateLoader(cli, viewNs)
 	return &taskDiscover{
@@ -19,12 +19,15 @@ package tasks
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 
 	"github.com/pkg/errors"
 	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/oam-dev/kubevela/pkg/workflow/tasks/custom"
 	"github.com/oam-dev/kubevela/pkg/workflow/types"
@@ -83,3 +86,153 @@ func TestSuspendStep(t *testing.T) {
 	assert.Equal(t, status.Name, "test")
 	assert.Equal(t, status.Phase, common.WorkflowStepPhaseSucceeded)
 }
+
+func TestTimedSuspendStep(t *testing.T) {
+	_, err := suspend(v1beta1.WorkflowStep{
+		Name:       "test",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"duration":"1x"}`)},
+	}, &types.GeneratorOptions{ID: "124"})
+	assert.ErrorContains(t, err, "parse duration of suspend step test")
+
+	runner, err := suspend(v1beta1.WorkflowStep{
+		Name:       "test",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"duration":"10m"}`)},
+	}, &types.GeneratorOptions{ID: "124"})
+	assert.NilError(t, err)
+	status, act, err := runner.Run(nil, &types.TaskRunOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, act.Suspend, true)
+	assert.Equal(t, status.Phase, common.WorkflowStepPhaseRunning)
+	assert.Assert(t, status.ResumeTime != nil)
+	assert.Assert(t, status.ResumeTime.Time.After(time.Now().Add(9*time.Minute)))
+
+	// the workflow is resumed manually or automatically.
+	status, act, err = runner.Run(nil, &types.TaskRunOptions{PreStatus: &status})
+	assert.NilError(t, err)
+	assert.Assert(t, act == nil)
+	assert.Equal(t, status.Phase, common.WorkflowStepPhaseSucceeded)
+}
+
+func TestApprovalSuspendStep(t *testing.T) {
+	_, err := suspend(v1beta1.WorkflowStep{
+		Name:       "test",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"approval":{"onExpire":"ignore"}}`)},
+	}, &types.GeneratorOptions{ID: "124"})
+	assert.Error(t, err, "unknown onExpire ignore of suspend step test")
+	_, err = suspend(v1beta1.WorkflowStep{
+		Name:       "test",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"duration":"1m","approval":{}}`)},
+	}, &types.GeneratorOptions{ID: "124"})
+	assert.Error(t, err, "duration and approval can't be set at the same time in suspend step test")
+
+	runner, err := suspend(v1beta1.WorkflowStep{
+		Name:       "test",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"approval":{"expire":"1h"}}`)},
+	}, &types.GeneratorOptions{ID: "124"})
+	assert.NilError(t, err)
+	status, act, err := runner.Run(nil, &types.TaskRunOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, act.Suspend, true)
+	assert.Equal(t, status.Phase, common.WorkflowStepPhaseRunning)
+	assert.Assert(t, status.Approval != nil && status.Approval.ExpireTime != nil)
+
+	// resumed without approval
+	status, act, err = runner.Run(nil, &types.TaskRunOptions{PreStatus: &status})
+	assert.NilError(t, err)
+	assert.Equal(t, act.Suspend, true)
+	assert.Equal(t, status.Phase, common.WorkflowStepPhaseRunning)
+
+	approved := status.DeepCopy()
+	approveTime := metav1.Now()
+	approved.Approval.Approver = "admin"
+	approved.Approval.Unverified = true
+	approved.Approval.ApproveTime = &approveTime
+	status, act, err = runner.Run(nil, &types.TaskRunOptions{PreStatus: approved})
+	assert.NilError(t, err)
+	assert.Assert(t, act == nil)
+	assert.Equal(t, status.Phase, common.WorkflowStepPhaseSucceeded)
+	assert.Equal(t, status.Approval.Approver, "admin")
+	assert.Equal(t, status.Message, "approved by admin (unverified)")
+
+	expired := approved.DeepCopy()
+	expired.Approval.ApproveTime = nil
+	expireTime := metav1.NewTime(time.Now().Add(-time.Minute))
+	expired.Approval.ExpireTime = &expireTime
+	status, act, err = runner.Run(nil, &types.TaskRunOptions{PreStatus: expired})
+	assert.NilError(t, err)
+	assert.Assert(t, act == nil)
+	assert.Equal(t, status.Phase, common.WorkflowStepPhaseFailed)
+	assert.Equal(t, status.Reason, types.StatusReasonApprovalExpired)
+
+	runner, err = suspend(v1beta1.WorkflowStep{
+		Name:       "test",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"approval":{"expire":"1h","onExpire":"terminate"}}`)},
+	}, &types.GeneratorOptions{ID: "124"})
+	assert.NilError(t, err)
+	status, act, err = runner.Run(nil, &types.TaskRunOptions{PreStatus: expired})
+	assert.NilError(t, err)
+	assert.Equal(t, act.Terminated, true)
+	assert.Equal(t, status.Phase, common.WorkflowStepPhaseFailed)
+}
+
+func TestStepGroupStep(t *testing.T) {
+	discover := &taskDiscover{
+		builtins: map[string]types.TaskGenerator{
+			"suspend":    suspend,
+			"step-group": stepGroup,
+		},
+	}
+	gen, err := discover.GetTaskGenerator(context.Background(), "step-group")
+	assert.NilError(t, err)
+	subRunner, err := suspend(v1beta1.WorkflowStep{Name: "sub"}, &types.GeneratorOptions{ID: "125"})
+	assert.NilError(t, err)
+	runner, err := gen(v1beta1.WorkflowStep{
+		Name:       "group",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"mode":"StepByStep"}`)},
+	}, &types.GeneratorOptions{ID: "124", SubTaskRunners: []types.TaskRunner{subRunner}})
+	assert.NilError(t, err)
+	assert.Equal(t, runner.Name(), "group")
+	assert.Equal(t, runner.Pending(nil), false)
+
+	var subStatus common.WorkflowStatus
+	options := &types.TaskRunOptions{
+		RunSteps: func(isDag bool, runners ...types.TaskRunner) (*common.WorkflowStatus, error) {
+			assert.Equal(t, isDag, false)
+			assert.Equal(t, len(runners), 1)
+			return &subStatus, nil
+		},
+	}
+	status, act, err := runner.Run(nil, options)
+	assert.NilError(t, err)
+	assert.Equal(t, status.ID, "124")
+	assert.Equal(t, status.Phase, common.WorkflowStepPhaseRunning)
+	assert.Equal(t, status.SubSteps.Mode, common.WorkflowModeStep)
+	assert.Equal(t, act.Suspend, false)
+
+	subStatus = common.WorkflowStatus{
+		Suspend: true,
+		Steps:   []common.WorkflowStepStatus{{ID: "125", Name: "sub", Type: "suspend", Phase: common.WorkflowStepPhaseSucceeded}},
+	}
+	status, act, err = runner.Run(nil, options)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Phase, common.WorkflowStepPhaseSucceeded)
+	assert.Equal(t, act.Suspend, true)
+	assert.Equal(t, len(status.SubSteps.Steps), 1)
+	assert.Equal(t, status.SubSteps.Steps[0].ID, "125")
+
+	subStatus = common.WorkflowStatus{
+		Terminated: true,
+		Steps:      []common.WorkflowStepStatus{{ID: "125", Name: "sub", Phase: common.WorkflowStepPhaseTimedOut}},
+	}
+	status, act, err = runner.Run(nil, options)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Phase, common.WorkflowStepPhaseFailed)
+	assert.Equal(t, status.Reason, types.StatusReasonSubStepsFailed)
+	assert.Equal(t, act.Terminated, false)
+
+	_, err = gen(v1beta1.WorkflowStep{
+		Name:       "group",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"mode":"unknown"}`)},
+	}, &types.GeneratorOptions{ID: "124"})
+	assert.Error(t, err, "unknown mode unknown of step group group")
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/tasks/custom"
+	"github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+const (
+	// OnExpireFail marks the suspend step as failed once the approval expires.
+	OnExpireFail = "fail"
+	// OnExpireTerminate terminates the workflow once the approval expires.
+	OnExpireTerminate = "terminate"
+)
+
+type suspendProperties struct {
+	// Duration resumes the workflow automatically after the duration.
+	Duration string `json:"duration,omitempty"`
+	// Approval suspends the workflow until the step is approved.
+	Approval *struct {
+		// Expire is the duration the step is waiting for approval.
+		Expire string `json:"expire,omitempty"`
+		// OnExpire is the action to take once the approval expires, fail or terminate.
+		OnExpire string `json:"onExpire,omitempty"`
+	} `json:"approval,omitempty"`
+}
+
+func suspend(step v1beta1.WorkflowStep, opt *types.GeneratorOptions) (types.TaskRunner, error) {
+	tr := &suspendTaskRunner{
+		id:   opt.ID,
+		name: step.Name,
+	}
+	if step.Properties == nil || len(step.Properties.Raw) == 0 {
+		return tr, nil
+	}
+	props := suspendProperties{}
+	if err := json.Unmarshal(step.Properties.Raw, &props); err != nil {
+		return nil, errors.WithMessagef(err, "decode properties of suspend step %s", step.Name)
+	}
+	var err error
+	if props.Duration != "" {
+		if tr.duration, err = time.ParseDuration(props.Duration); err != nil {
+			return nil, errors.WithMessagef(err, "parse duration of suspend step %s", step.Name)
+		}
+	}
+	if props.Approval != nil {
+		if tr.duration > 0 {
+			return nil, errors.Errorf("duration and approval can't be set at the same time in suspend step %s", step.Name)
+		}
+		tr.approval = &suspendApproval{onExpire: props.Approval.OnExpire}
+		if props.Approval.Expire != "" {
+			if tr.approval.expire, err = time.ParseDuration(props.Approval.Expire); err != nil {
+				return nil, errors.WithMessagef(err, "parse approval expire of suspend step %s", step.Name)
+			}
+		}
+		switch tr.approval.onExpire {
+		case "":
+			tr.approval.onExpire = OnExpireFail
+		case OnExpireFail, OnExpireTerminate:
+		default:
+			return nil, errors.Errorf("unknown onExpire %s of suspend step %s", tr.approval.onExpire, step.Name)
+		}
+	}
+	return tr, nil
+}
+
+type suspendApproval struct {
+	expire   time.Duration
+	onExpire string
+}
+
+type suspendTaskRunner struct {
+	id       string
+	name     string
+	duration time.Duration
+	approval *suspendApproval
+}
+
+// Name return suspend step name.
+func (tr *suspendTaskRunner) Name() string {
+	return tr.name
+}
+
+// Run make workflow suspend. The timed suspend step is finished once the workflow is resumed manually
+// or the duration passes, the suspend step in approval mode is finished only if it's approved.
+func (tr *suspendTaskRunner) Run(ctx wfContext.Context, options *types.TaskRunOptions) (common.WorkflowStepStatus, *types.Operation, error) {
+	status := common.WorkflowStepStatus{
+		ID:    tr.id,
+		Name:  tr.name,
+		Type:  "suspend",
+		Phase: common.WorkflowStepPhaseSucceeded,
+	}
+	if tr.duration <= 0 && tr.approval == nil {
+		return status, &types.Operation{Suspend: true}, nil
+	}
+
+	var (
+		now = time.Now()
+		pre *common.WorkflowStepStatus
+	)
+	if options != nil {
+		pre = options.PreStatus
+	}
+	if pre == nil || pre.Phase != common.WorkflowStepPhaseRunning {
+		status.Phase = common.WorkflowStepPhaseRunning
+		status.Reason = custom.StatusReasonSuspend
+		if tr.approval == nil {
+			resumeTime := metav1.NewTime(now.Add(tr.duration))
+			status.ResumeTime = &resumeTime
+			status.Message = fmt.Sprintf("suspended until %s", resumeTime.Format(time.RFC3339))
+			return status, &types.Operation{Suspend: true}, nil
+		}
+		status.Approval = &common.WorkflowStepApproval{}
+		status.Message = "waiting for approval"
+		if tr.approval.expire > 0 {
+			expireTime := metav1.NewTime(now.Add(tr.approval.expire))
+			status.Approval.ExpireTime = &expireTime
+			status.Message = fmt.Sprintf("waiting for approval before %s", expireTime.Format(time.RFC3339))
+		}
+		return status, &types.Operation{Suspend: true}, nil
+	}
+
+	if tr.approval == nil {
+		status.ResumeTime = pre.ResumeTime
+		return status, nil, nil
+	}
+	approval := pre.Approval
+	if approval == nil {
+		approval = &common.WorkflowStepApproval{}
+	}
+	status.Approval = approval
+	switch {
+	case approval.ApproveTime != nil:
+		status.Message = approvalMessage(approval)
+		return status, nil, nil
+	case approval.ExpireTime != nil && !now.Before(approval.ExpireTime.Time):
+		status.Phase = common.WorkflowStepPhaseFailed
+		status.Reason = types.StatusReasonApprovalExpired
+		status.Message = fmt.Sprintf("approval expired at %s", approval.ExpireTime.Format(time.RFC3339))
+		if tr.approval.onExpire == OnExpireTerminate {
+			return status, &types.Operation{Terminated: true}, nil
+		}
+		return status, nil, nil
+	default:
+		// the workflow is resumed without approval, keep waiting.
+		status.Phase = common.WorkflowStepPhaseRunning
+		status.Reason = custom.StatusReasonSuspend
+		status.Message = pre.Message
+		return status, &types.Operation{Suspend: true}, nil
+	}
+}
+
+// Pending check task should be executed or not.
+func (tr *suspendTaskRunner) Pending(ctx wfContext.Context) bool {
+	return false
+}
+
+// approvalMessage describes who approved the step, the approver is marked if it's not authenticated
+func approvalMessage(approval *common.WorkflowStepApproval) string {
+	approver := approval.Approver
+	if approver == "" {
+		approver = "unknown approver"
+	}
+	if approval.Unverified {
+		return fmt.Sprintf("approved by %s (unverified)", approver)
+	}
+	return fmt.Sprintf("approved by %s", approver)
+}
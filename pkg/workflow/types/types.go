@@ -45,6 +45,8 @@ type TaskRunOptions struct {
 	PostStopHooks []TaskPostStopHook
 	GetTracer     func(id string, step v1beta1.WorkflowStep) monitorCtx.Context
 	RunSteps      func(isDag bool, runners ...TaskRunner) (*common.WorkflowStatus, error)
+	// PreStatus is the status recorded by the last execution of the step, it's nil if the step has never been executed.
+	PreStatus *common.WorkflowStepStatus
 }
 
 // TaskPreStartHook run before task execution.
@@ -67,6 +69,8 @@ type GeneratorOptions struct {
 	ID            string
 	PrePhase      common.WorkflowStepPhase
 	StepConvertor func(step v1beta1.WorkflowStep) (v1beta1.WorkflowStep, error)
+	// SubTaskRunners are the task runners of the sub steps, used by step group.
+	SubTaskRunners []TaskRunner
 }
 
 // Action is that workflow provider can do.
@@ -80,3 +84,10 @@ const (
 	// ContextKeyMetadata is key that refer to application metadata.
 	ContextKeyMetadata = "metadata__"
 )
+
+const (
+	// StatusReasonSubStepsFailed is the reason of the step group whose sub steps are failed.
+	StatusReasonSubStepsFailed = "SubStepsFailed"
+	// StatusReasonApprovalExpired is the reason of the suspend step which isn't approved before the deadline.
+	StatusReasonApprovalExpired = "ApprovalExpired"
+)
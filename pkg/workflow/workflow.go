@@ -96,7 +96,11 @@ func (w *workflow) ExecuteSteps(ctx monitorContext.Context, appRev *oamcore.Appl
 		return common.WorkflowStateTerminated, nil
 	}
 	if wfStatus.Suspend {
-		return common.WorkflowStateSuspended, nil
+		if !shouldResume(wfStatus, time.Now()) {
+			return common.WorkflowStateSuspended, nil
+		}
+		ctx.Info("Resume the timed suspended workflow")
+		wfStatus.Suspend = false
 	}
 	allTasksDone := w.allDone(taskRunners)
 	if allTasksDone {
@@ -115,6 +119,9 @@ func (w *workflow) ExecuteSteps(ctx monitorContext.Context, appRev *oamcore.Appl
 		monitorCtx: ctx,
 		app:        w.app,
 	}
+	if w.app.Spec.Workflow != nil {
+		e.stepSpecs = w.app.Spec.Workflow.Steps
+	}
 
 	err = e.run(wfCtx, taskRunners)
 	if err != nil {
@@ -148,7 +155,7 @@ func (w *workflow) allDone(taskRunners []wfTypes.TaskRunner) bool {
 		done := false
 		for _, ss := range status.Steps {
 			if ss.Name == t.Name() {
-				done = ss.Phase == common.WorkflowStepPhaseSucceeded
+				done = ss.Phase == common.WorkflowStepPhaseSucceeded || ss.Phase == common.WorkflowStepPhaseSkipped
 				break
 			}
 		}
@@ -208,7 +215,7 @@ func (e *engine) runAsDAG(wfCtx wfContext.Context, taskRunners []wfTypes.TaskRun
 		ready := false
 		for _, ss := range e.status.Steps {
 			if ss.Name == tRunner.Name() {
-				ready = ss.Phase == common.WorkflowStepPhaseSucceeded
+				ready = isStepFinished(ss)
 				break
 			}
 		}
@@ -225,6 +232,14 @@ func (e *engine) runAsDAG(wfCtx wfContext.Context, taskRunners []wfTypes.TaskRun
 		return nil
 	}
 
+	if len(todoTasks) == 0 && e.hasFailedStep() {
+		// the pending steps will never be ready as the steps they depend on are failed.
+		for _, tRunner := range pendingTasks {
+			e.skipStep(tRunner.Name(), "skipped since the previous step is failed")
+		}
+		return nil
+	}
+
 	if len(todoTasks) > 0 {
 		err := e.steps(wfCtx, todoTasks)
 		if err != nil {
@@ -243,11 +258,29 @@ func (e *engine) runAsDAG(wfCtx wfContext.Context, taskRunners []wfTypes.TaskRun
 }
 
 func (e *engine) run(wfCtx wfContext.Context, taskRunners []wfTypes.TaskRunner) error {
+	var err error
 	if e.dagMode {
-		return e.runAsDAG(wfCtx, taskRunners)
+		err = e.runAsDAG(wfCtx, taskRunners)
+	} else {
+		err = e.steps(wfCtx, e.todoByIndex(taskRunners))
+	}
+	if err != nil {
+		return err
+	}
+	if e.hasFailedStep() && e.allFinished(taskRunners) {
+		e.status.Terminated = true
 	}
+	return nil
+}
 
-	return e.steps(wfCtx, e.todoByIndex(taskRunners))
+func (e *engine) allFinished(taskRunners []wfTypes.TaskRunner) bool {
+	for _, t := range taskRunners {
+		status := e.getStepStatus(t.Name())
+		if status == nil || !isStepFinished(*status) {
+			return false
+		}
+	}
+	return true
 }
 
 func (e *engine) todoByIndex(taskRunners []wfTypes.TaskRunner) []wfTypes.TaskRunner {
@@ -255,7 +288,7 @@ func (e *engine) todoByIndex(taskRunners []wfTypes.TaskRunner) []wfTypes.TaskRun
 	for _, t := range taskRunners {
 		for _, ss := range e.status.Steps {
 			if ss.Name == t.Name() {
-				if ss.Phase == common.WorkflowStepPhaseSucceeded {
+				if isStepFinished(ss) {
 					index++
 				}
 				break
@@ -267,12 +300,59 @@ func (e *engine) todoByIndex(taskRunners []wfTypes.TaskRunner) []wfTypes.TaskRun
 
 func (e *engine) steps(wfCtx wfContext.Context, taskRunners []wfTypes.TaskRunner) error {
 	for _, runner := range taskRunners {
+		skip, message, err := e.checkStepCondition(wfCtx, runner.Name())
+		if err != nil {
+			e.updateStepStatus(common.WorkflowStepStatus{
+				ID:      e.stepID(runner.Name()),
+				Name:    runner.Name(),
+				Phase:   common.WorkflowStepPhaseFailed,
+				Reason:  StatusReasonCondition,
+				Message: err.Error(),
+			})
+			if e.isDag() {
+				continue
+			}
+			return nil
+		}
+		if skip {
+			e.skipStep(runner.Name(), message)
+			continue
+		}
+
+		policy, err := e.getStepPolicy(runner.Name())
+		if err != nil {
+			return err
+		}
+		if policy != nil {
+			if last := e.getStepStatus(runner.Name()); last != nil {
+				e.checkStepTimeout(policy, runner.Name())
+				if isStepFailed(*last) {
+					continue
+				}
+				if waitingForRetry(*last, time.Now()) {
+					if e.isDag() {
+						continue
+					}
+					return nil
+				}
+			}
+		}
+
+		var preStatus *common.WorkflowStepStatus
+		if last := e.getStepStatus(runner.Name()); last != nil {
+			preStatus = last.DeepCopy()
+		}
+		groupName := runner.Name()
 		status, operation, err := runner.Run(wfCtx, &wfTypes.TaskRunOptions{
+			PreStatus: preStatus,
 			GetTracer: func(id string, stepStatus oamcore.WorkflowStep) monitorContext.Context {
 				return e.monitorCtx.Fork(id, monitorContext.DurationMetric(func(v float64) {
 					metrics.StepDurationSummary.WithLabelValues(e.app.Namespace+"/"+e.app.Name, e.status.AppRevision, stepStatus.Name, stepStatus.Type).Observe(v)
 				}))
 			},
+			RunSteps: func(isDag bool, runners ...wfTypes.TaskRunner) (*common.WorkflowStatus, error) {
+				return e.runSubSteps(wfCtx, groupName, isDag, runners)
+			},
 		})
 		if err != nil {
 			return err
@@ -285,7 +365,15 @@ func (e *engine) steps(wfCtx wfContext.Context, taskRunners []wfTypes.TaskRunner
 		}
 
 		if status.Phase != common.WorkflowStepPhaseSucceeded {
-			if e.isDag() {
+			e.checkStepPolicy(policy, runner.Name())
+			// the step group can suspend or terminate the workflow before all its sub steps are finished.
+			if status.SubSteps != nil {
+				e.finishStep(operation)
+				if e.needStop() {
+					return nil
+				}
+			}
+			if e.isDag() || isStepFailed(*e.getStepStatus(runner.Name())) {
 				continue
 			}
 			return nil
@@ -299,11 +387,59 @@ func (e *engine) steps(wfCtx wfContext.Context, taskRunners []wfTypes.TaskRunner
 	return nil
 }
 
+// runSubSteps executes the sub steps of the step group with a sub engine,
+// whose status is recovered from the sub steps status of the step group.
+func (e *engine) runSubSteps(wfCtx wfContext.Context, groupName string, isDag bool, taskRunners []wfTypes.TaskRunner) (*common.WorkflowStatus, error) {
+	subStatus := &common.WorkflowStatus{
+		AppRevision: e.status.AppRevision,
+		Mode:        common.WorkflowModeStep,
+	}
+	if isDag {
+		subStatus.Mode = common.WorkflowModeDAG
+	}
+	if last := e.getStepStatus(groupName); last != nil && last.SubSteps != nil {
+		for _, ss := range last.SubSteps.Steps {
+			subStatus.Steps = append(subStatus.Steps, common.WorkflowStepStatus{
+				ID:               ss.ID,
+				Name:             ss.Name,
+				Type:             ss.Type,
+				Phase:            ss.Phase,
+				Message:          ss.Message,
+				Reason:           ss.Reason,
+				FirstExecuteTime: ss.FirstExecuteTime,
+				LastExecuteTime:  ss.LastExecuteTime,
+				Attempts:         ss.Attempts,
+				NextRetryTime:    ss.NextRetryTime,
+				ResumeTime:       ss.ResumeTime,
+				Approval:         ss.Approval,
+			})
+		}
+	}
+	var stepSpecs []oamcore.WorkflowStep
+	if group := e.getStepSpec(groupName); group != nil {
+		for _, sub := range group.SubSteps {
+			stepSpecs = append(stepSpecs, sub.WorkflowStep())
+		}
+	}
+	sub := &engine{
+		status:     subStatus,
+		dagMode:    isDag,
+		monitorCtx: e.monitorCtx,
+		app:        e.app,
+		stepSpecs:  stepSpecs,
+	}
+	if err := sub.run(wfCtx, taskRunners); err != nil {
+		return nil, err
+	}
+	return subStatus, nil
+}
+
 type engine struct {
 	dagMode    bool
 	status     *common.WorkflowStatus
 	monitorCtx monitorContext.Context
 	app        *oamcore.Application
+	stepSpecs  []oamcore.WorkflowStep
 }
 
 func (e *engine) isDag() bool {
@@ -326,6 +462,10 @@ func (e *engine) updateStepStatus(status common.WorkflowStepStatus) {
 	for i := range e.status.Steps {
 		if e.status.Steps[i].Name == status.Name {
 			status.FirstExecuteTime = e.status.Steps[i].FirstExecuteTime
+			status.Attempts = e.status.Steps[i].Attempts
+			if e.status.Steps[i].Phase == common.WorkflowStepPhaseFailed {
+				status.Attempts++
+			}
 			e.status.Steps[i] = status
 			conditionUpdated = true
 			break
@@ -333,6 +473,7 @@ func (e *engine) updateStepStatus(status common.WorkflowStepStatus) {
 	}
 	if !conditionUpdated {
 		status.FirstExecuteTime = now
+		status.Attempts = 1
 		e.status.Steps = append(e.status.Steps, status)
 	}
 }
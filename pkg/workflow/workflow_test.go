@@ -19,6 +19,7 @@ package workflow
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	monitorContext "github.com/oam-dev/kubevela/pkg/monitor/context"
 
@@ -32,11 +33,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/yaml"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	oamcore "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+	"github.com/oam-dev/kubevela/pkg/workflow/tasks"
 	wfTypes "github.com/oam-dev/kubevela/pkg/workflow/types"
 )
 
@@ -82,13 +86,15 @@ var _ = Describe("Test Workflow", func() {
 			AppRevision: workflowStatus.AppRevision,
 			Mode:        common.WorkflowModeStep,
 			Steps: []common.WorkflowStepStatus{{
-				Name:  "s1",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s1",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}, {
-				Name:  "s2",
-				Type:  "failed",
-				Phase: common.WorkflowStepPhaseFailed,
+				Name:     "s2",
+				Type:     "failed",
+				Phase:    common.WorkflowStepPhaseFailed,
+				Attempts: 1,
 			}},
 		})).Should(BeEquivalentTo(""))
 
@@ -122,17 +128,20 @@ var _ = Describe("Test Workflow", func() {
 			AppRevision: app.Status.Workflow.AppRevision,
 			Mode:        common.WorkflowModeStep,
 			Steps: []common.WorkflowStepStatus{{
-				Name:  "s1",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s1",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}, {
-				Name:  "s2",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s2",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}, {
-				Name:  "s3",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s3",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}},
 		})).Should(BeEquivalentTo(""))
 
@@ -166,13 +175,15 @@ var _ = Describe("Test Workflow", func() {
 			Mode:        common.WorkflowModeStep,
 			Suspend:     true,
 			Steps: []common.WorkflowStepStatus{{
-				Name:  "s1",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s1",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}, {
-				Name:  "s2",
-				Type:  "suspend",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s2",
+				Type:     "suspend",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}},
 		})).Should(BeEquivalentTo(""))
 
@@ -194,17 +205,20 @@ var _ = Describe("Test Workflow", func() {
 			AppRevision: app.Status.Workflow.AppRevision,
 			Mode:        common.WorkflowModeStep,
 			Steps: []common.WorkflowStepStatus{{
-				Name:  "s1",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s1",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}, {
-				Name:  "s2",
-				Type:  "suspend",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s2",
+				Type:     "suspend",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}, {
-				Name:  "s3",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s3",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}},
 		})).Should(BeEquivalentTo(""))
 
@@ -236,13 +250,15 @@ var _ = Describe("Test Workflow", func() {
 			Mode:        common.WorkflowModeStep,
 			Terminated:  true,
 			Steps: []common.WorkflowStepStatus{{
-				Name:  "s1",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s1",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}, {
-				Name:  "s2",
-				Type:  "terminate",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s2",
+				Type:     "terminate",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}},
 		})).Should(BeEquivalentTo(""))
 
@@ -273,9 +289,10 @@ var _ = Describe("Test Workflow", func() {
 			AppRevision: app.Status.Workflow.AppRevision,
 			Mode:        common.WorkflowModeStep,
 			Steps: []common.WorkflowStepStatus{{
-				Name:  "s1",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s1",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}},
 		})).Should(BeEquivalentTo(""))
 	})
@@ -316,13 +333,15 @@ var _ = Describe("Test Workflow", func() {
 			AppRevision: app.Status.Workflow.AppRevision,
 			Mode:        common.WorkflowModeDAG,
 			Steps: []common.WorkflowStepStatus{{
-				Name:  "s1",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s1",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}, {
-				Name:  "s3",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s3",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}},
 		})).Should(BeEquivalentTo(""))
 
@@ -340,21 +359,462 @@ var _ = Describe("Test Workflow", func() {
 			AppRevision: app.Status.Workflow.AppRevision,
 			Mode:        common.WorkflowModeDAG,
 			Steps: []common.WorkflowStepStatus{{
-				Name:  "s1",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s1",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}, {
-				Name:  "s3",
-				Type:  "success",
-				Phase: common.WorkflowStepPhaseSucceeded,
+				Name:     "s3",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}, {
-				Name:  "s2",
-				Type:  "pending",
+				Name:     "s2",
+				Type:     "pending",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
+			}},
+		})).Should(BeEquivalentTo(""))
+	})
+
+	It("test for step retries and backoff", func() {
+		retries := 1
+		app, runners := makeTestCase([]oamcore.WorkflowStep{
+			{
+				Name: "s1",
+				Type: "success",
+			},
+			{
+				Name:    "s2",
+				Type:    "failed",
+				Retries: &retries,
+			},
+		})
+		ctx := monitorContext.NewTraceContext(context.Background(), "test-app")
+		wf := NewWorkflow(app, k8sClient, common.WorkflowModeStep)
+		state, err := wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateExecuting))
+		Expect(app.Status.Workflow.Steps[1].Attempts).Should(BeEquivalentTo(1))
+		Expect(app.Status.Workflow.Steps[1].NextRetryTime).Should(BeNil())
+
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateTerminated))
+		Expect(app.Status.Workflow.Steps[1].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseFailed))
+		Expect(app.Status.Workflow.Steps[1].Attempts).Should(BeEquivalentTo(2))
+		Expect(app.Status.Workflow.Steps[1].Reason).Should(BeEquivalentTo(StatusReasonRetriesExhausted))
+
+		app, runners = makeTestCase([]oamcore.WorkflowStep{
+			{
+				Name:    "s1",
+				Type:    "failed",
+				Backoff: &oamcore.WorkflowStepBackoff{Duration: "1h"},
+			},
+		})
+		wf = NewWorkflow(app, k8sClient, common.WorkflowModeStep)
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateExecuting))
+		nextRetryTime := app.Status.Workflow.Steps[0].NextRetryTime
+		Expect(nextRetryTime).ShouldNot(BeNil())
+		Expect(nextRetryTime.Time.After(time.Now().Add(50 * time.Minute))).Should(BeTrue())
+
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateExecuting))
+		Expect(app.Status.Workflow.Steps[0].Attempts).Should(BeEquivalentTo(1))
+
+		app.Status.Workflow.Steps[0].NextRetryTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateExecuting))
+		Expect(app.Status.Workflow.Steps[0].Attempts).Should(BeEquivalentTo(2))
+	})
+
+	It("test for step timeout", func() {
+		app, runners := makeTestCase([]oamcore.WorkflowStep{
+			{
+				Name:    "s1",
+				Type:    "wait-with-set-var",
+				Timeout: "10m",
+			},
+			{
+				Name: "s2",
+				Type: "success",
+			},
+		})
+		ctx := monitorContext.NewTraceContext(context.Background(), "test-app")
+		wf := NewWorkflow(app, k8sClient, common.WorkflowModeStep)
+		state, err := wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateExecuting))
+		Expect(app.Status.Workflow.Steps[0].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseRunning))
+
+		app.Status.Workflow.Steps[0].FirstExecuteTime = metav1.NewTime(time.Now().Add(-time.Hour))
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateTerminated))
+		Expect(app.Status.Workflow.Steps[0].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseTimedOut))
+		Expect(app.Status.Workflow.Steps[0].Reason).Should(BeEquivalentTo(StatusReasonTimeout))
+		Expect(app.Status.Workflow.Steps[1].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseSkipped))
+	})
+
+	It("test for step condition", func() {
+		retries := 0
+		app, runners := makeTestCase([]oamcore.WorkflowStep{
+			{
+				Name: "s1",
+				Type: "success",
+				If:   `context.name == "not-the-app"`,
+			},
+			{
+				Name:    "s2",
+				Type:    "failed",
+				If:      `status.s1.phase == "skipped"`,
+				Retries: &retries,
+			},
+			{
+				Name: "s3",
+				Type: "success",
+			},
+			{
+				Name: "s4",
+				Type: "success",
+				If:   `status.s2.phase == "failed"`,
+			},
+			{
+				Name: "s5",
+				Type: "success",
+				If:   `status.s2.phase == "succeeded"`,
+			},
+		})
+		ctx := monitorContext.NewTraceContext(context.Background(), "test-app")
+		wf := NewWorkflow(app, k8sClient, common.WorkflowModeStep)
+		state, err := wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateTerminated))
+		phases := map[string]common.WorkflowStepPhase{}
+		for _, ss := range app.Status.Workflow.Steps {
+			phases[ss.Name] = ss.Phase
+		}
+		Expect(phases).Should(BeEquivalentTo(map[string]common.WorkflowStepPhase{
+			"s1": common.WorkflowStepPhaseSkipped,
+			"s2": common.WorkflowStepPhaseFailed,
+			"s3": common.WorkflowStepPhaseSkipped,
+			"s4": common.WorkflowStepPhaseSucceeded,
+			"s5": common.WorkflowStepPhaseSkipped,
+		}))
+
+		app, runners = makeTestCase([]oamcore.WorkflowStep{
+			{
+				Name: "s-1",
+				Type: "success",
+				If:   `status.s0.phase`,
+			},
+			{
+				Name: "s2",
+				Type: "success",
+				If:   `status["s-1"].reason == "Condition"`,
+			},
+		})
+		wf = NewWorkflow(app, k8sClient, common.WorkflowModeStep)
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateExecuting))
+		Expect(app.Status.Workflow.Steps[0].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseFailed))
+		Expect(app.Status.Workflow.Steps[0].Reason).Should(BeEquivalentTo(StatusReasonCondition))
+		Expect(app.Status.Workflow.Steps[0].Message).Should(ContainSubstring(`evaluate condition "status.s0.phase"`))
+
+		// the step whose condition can't be evaluated is failed and won't be evaluated again
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateTerminated))
+		Expect(app.Status.Workflow.Steps[0].Attempts).Should(BeEquivalentTo(1))
+		Expect(app.Status.Workflow.Steps[1].Name).Should(BeEquivalentTo("s2"))
+		Expect(app.Status.Workflow.Steps[1].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseSucceeded))
+	})
+
+	It("test for step group", func() {
+		app, runners := makeTestCase([]oamcore.WorkflowStep{
+			{
+				Name: "s1",
+				Type: "success",
+			},
+			{
+				Name: "group",
+				Type: "step-group",
+				SubSteps: []oamcore.WorkflowSubStep{
+					{
+						Name: "s2-sub1",
+						Type: "success",
+					},
+					{
+						Name: "s2-sub2",
+						Type: "suspend",
+					},
+				},
+			},
+			{
+				Name: "s3",
+				Type: "success",
+			},
+		})
+		ctx := monitorContext.NewTraceContext(context.Background(), "test-app")
+		wf := NewWorkflow(app, k8sClient, common.WorkflowModeStep)
+		state, err := wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateSuspended))
+		wfStatus := *app.Status.Workflow
+		Expect(wfStatus.Steps[1].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseSucceeded))
+		Expect(wfStatus.Steps[1].SubSteps.Mode).Should(BeEquivalentTo(common.WorkflowModeDAG))
+		Expect(len(wfStatus.Steps[1].SubSteps.Steps)).Should(BeEquivalentTo(2))
+		Expect(wfStatus.Steps[1].SubSteps.Steps[0].FirstExecuteTime.IsZero()).Should(BeFalse())
+
+		app.Status.Workflow.Suspend = false
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateSucceeded))
+		app.Status.Workflow.ContextBackend = nil
+		cleanStepTimeStamp(app.Status.Workflow)
+		Expect(cmp.Diff(*app.Status.Workflow, common.WorkflowStatus{
+			AppRevision: app.Status.Workflow.AppRevision,
+			Mode:        common.WorkflowModeStep,
+			Steps: []common.WorkflowStepStatus{{
+				Name:     "s1",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
+			}, {
+				ID:    "group",
+				Name:  "group",
+				Type:  "step-group",
 				Phase: common.WorkflowStepPhaseSucceeded,
+				SubSteps: &common.SubStepsStatus{
+					Mode: common.WorkflowModeDAG,
+					Steps: []common.WorkflowSubStepStatus{{
+						Name:     "s2-sub1",
+						Type:     "success",
+						Phase:    common.WorkflowStepPhaseSucceeded,
+						Attempts: 1,
+					}, {
+						Name:     "s2-sub2",
+						Type:     "suspend",
+						Phase:    common.WorkflowStepPhaseSucceeded,
+						Attempts: 1,
+					}},
+				},
+				Attempts: 1,
+			}, {
+				Name:     "s3",
+				Type:     "success",
+				Phase:    common.WorkflowStepPhaseSucceeded,
+				Attempts: 1,
 			}},
 		})).Should(BeEquivalentTo(""))
 	})
 
+	It("test for timed suspend and approval", func() {
+		app, runners := makeTestCase([]oamcore.WorkflowStep{
+			{
+				Name: "s1",
+				Type: "success",
+			},
+			{
+				Name:       "wait",
+				Type:       "suspend",
+				Properties: &runtime.RawExtension{Raw: []byte(`{"duration":"1h"}`)},
+			},
+			{
+				Name:       "approve",
+				Type:       "suspend",
+				Properties: &runtime.RawExtension{Raw: []byte(`{"approval":{"expire":"2h"}}`)},
+			},
+			{
+				Name: "s2",
+				Type: "success",
+			},
+		})
+		ctx := monitorContext.NewTraceContext(context.Background(), "test-app")
+		wf := NewWorkflow(app, k8sClient, common.WorkflowModeStep)
+		state, err := wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateSuspended))
+		Expect(app.Status.Workflow.Steps[1].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseRunning))
+		resumeTime := app.Status.Workflow.Steps[1].ResumeTime
+		Expect(resumeTime).ShouldNot(BeNil())
+		Expect(NextResumeTime(app.Status.Workflow)).Should(BeEquivalentTo(resumeTime))
+
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateSuspended))
+		Expect(app.Status.Workflow.Steps[1].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseRunning))
+
+		// the workflow is resumed automatically once the duration passes.
+		past := metav1.NewTime(time.Now().Add(-time.Second))
+		app.Status.Workflow.Steps[1].ResumeTime = &past
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateSuspended))
+		Expect(app.Status.Workflow.Steps[1].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseSucceeded))
+		Expect(app.Status.Workflow.Steps[2].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseRunning))
+		Expect(app.Status.Workflow.Steps[2].Approval.ExpireTime).ShouldNot(BeNil())
+
+		// the workflow is suspended again if it's resumed without approval.
+		app.Status.Workflow.Suspend = false
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateSuspended))
+		Expect(app.Status.Workflow.Steps[2].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseRunning))
+
+		Expect(ApproveSuspendedSteps(app.Status.Workflow, "admin", true)).Should(BeTrue())
+		app.Status.Workflow.Suspend = false
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateSucceeded))
+		Expect(app.Status.Workflow.Steps[2].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseSucceeded))
+		Expect(app.Status.Workflow.Steps[2].Approval.Approver).Should(BeEquivalentTo("admin"))
+		Expect(app.Status.Workflow.Steps[2].Approval.Unverified).Should(BeTrue())
+		Expect(app.Status.Workflow.Steps[2].Message).Should(BeEquivalentTo("approved by admin (unverified)"))
+		Expect(app.Status.Workflow.Steps[2].Approval.ApproveTime).ShouldNot(BeNil())
+	})
+
+	It("test for expired approval", func() {
+		app, runners := makeTestCase([]oamcore.WorkflowStep{
+			{
+				Name:       "approve",
+				Type:       "suspend",
+				Properties: &runtime.RawExtension{Raw: []byte(`{"approval":{"expire":"1h","onExpire":"terminate"}}`)},
+			},
+			{
+				Name: "s1",
+				Type: "success",
+			},
+		})
+		ctx := monitorContext.NewTraceContext(context.Background(), "test-app")
+		wf := NewWorkflow(app, k8sClient, common.WorkflowModeStep)
+		state, err := wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateSuspended))
+
+		past := metav1.NewTime(time.Now().Add(-time.Second))
+		app.Status.Workflow.Steps[0].Approval.ExpireTime = &past
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateTerminated))
+		Expect(app.Status.Workflow.Steps[0].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseFailed))
+		Expect(app.Status.Workflow.Steps[0].Reason).Should(BeEquivalentTo(wfTypes.StatusReasonApprovalExpired))
+		Expect(len(app.Status.Workflow.Steps)).Should(BeEquivalentTo(1))
+	})
+
+	It("test for retrying the failed step", func() {
+		retries := 0
+		app, runners := makeTestCase([]oamcore.WorkflowStep{
+			{Name: "s1", Type: "success"},
+			{Name: "s2", Type: "failed", Retries: &retries},
+			{Name: "s3", Type: "success"},
+		})
+		ctx := monitorContext.NewTraceContext(context.Background(), "test-app")
+		wf := NewWorkflow(app, k8sClient, common.WorkflowModeStep)
+		state, err := wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateTerminated))
+		Expect(app.Status.Workflow.Steps[2].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseSkipped))
+		app.Status.Workflow.Finished = true
+		s1 := app.Status.Workflow.Steps[0]
+
+		Expect(RetryStep(app, "s1")).Should(MatchError("step s1 is succeeded, only the failed step can be retried"))
+		Expect(RetryStep(app, "s4")).Should(MatchError("step s4 is not executed"))
+		Expect(RetryStep(app, "s2")).Should(BeNil())
+		Expect(app.Status.Workflow.Terminated).Should(BeFalse())
+		Expect(app.Status.Workflow.Finished).Should(BeFalse())
+		Expect(app.Status.Workflow.Steps).Should(BeEquivalentTo([]common.WorkflowStepStatus{s1}))
+
+		runners[1] = makeRunner("s2", "success")
+		state, err = wf.ExecuteSteps(ctx, revision, runners)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).Should(BeEquivalentTo(common.WorkflowStateSucceeded))
+		Expect(app.Status.Workflow.Steps[0]).Should(BeEquivalentTo(s1))
+		Expect(app.Status.Workflow.Steps[1].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseSucceeded))
+		Expect(app.Status.Workflow.Steps[2].Phase).Should(BeEquivalentTo(common.WorkflowStepPhaseSucceeded))
+
+		app, _ = makeTestCase([]oamcore.WorkflowStep{
+			{Name: "s1", Type: "success"},
+			{Name: "s2", Type: "failed", Retries: &retries, DependsOn: []string{"s1"}, Outputs: common.StepOutputs{{Name: "s2-output", ValueFrom: "output"}}},
+			{Name: "s3", Type: "success", Inputs: common.StepInputs{{From: "s2-output", ParameterKey: "input"}}},
+			{Name: "s4", Type: "success", DependsOn: []string{"s1"}},
+		})
+		app.Status.Workflow = &common.WorkflowStatus{
+			Mode: common.WorkflowModeDAG,
+			Steps: []common.WorkflowStepStatus{
+				{Name: "s1", Phase: common.WorkflowStepPhaseSucceeded},
+				{Name: "s2", Phase: common.WorkflowStepPhaseFailed, Reason: StatusReasonRetriesExhausted},
+				{Name: "s3", Phase: common.WorkflowStepPhaseSkipped},
+				{Name: "s4", Phase: common.WorkflowStepPhaseSucceeded},
+			},
+			Terminated: true,
+		}
+		Expect(RetryStep(app, "s2")).Should(BeNil())
+		Expect(app.Status.Workflow.Steps).Should(BeEquivalentTo([]common.WorkflowStepStatus{
+			{Name: "s1", Phase: common.WorkflowStepPhaseSucceeded},
+			{Name: "s4", Phase: common.WorkflowStepPhaseSucceeded},
+		}))
+
+		app, _ = makeTestCase([]oamcore.WorkflowStep{
+			{Name: "deploy-a", Type: "apply-component", Properties: &runtime.RawExtension{Raw: []byte(`{"component":"comp-a"}`)}},
+			{Name: "deploy-b", Type: "apply-component", Properties: &runtime.RawExtension{Raw: []byte(`{"component":"comp-b"}`)}},
+			{Name: "s3", Type: "success", DependsOn: []string{"comp-b"}},
+			{Name: "s4", Type: "success", DependsOn: []string{"deploy-a"}},
+		})
+		app.Spec.Components = []common.ApplicationComponent{
+			{Name: "comp-a", Type: "webservice"},
+			{Name: "comp-b", Type: "webservice", DependsOn: []string{"comp-a"}},
+		}
+		app.Status.Workflow = &common.WorkflowStatus{
+			Mode: common.WorkflowModeDAG,
+			Steps: []common.WorkflowStepStatus{
+				{Name: "deploy-a", Phase: common.WorkflowStepPhaseFailed, Reason: StatusReasonRetriesExhausted},
+				{Name: "deploy-b", Phase: common.WorkflowStepPhaseSkipped},
+				{Name: "s3", Phase: common.WorkflowStepPhaseSkipped},
+				{Name: "s4", Phase: common.WorkflowStepPhaseSkipped},
+			},
+			Terminated: true,
+		}
+		Expect(RetryStep(app, "deploy-a")).Should(BeNil())
+		Expect(app.Status.Workflow.Steps).Should(BeEquivalentTo([]common.WorkflowStepStatus{
+			{Name: "s4", Phase: common.WorkflowStepPhaseSkipped},
+		}))
+
+		app, _ = makeTestCase([]oamcore.WorkflowStep{
+			{Name: "group", Type: "step-group", SubSteps: []oamcore.WorkflowSubStep{
+				{Name: "sub1", Type: "success"},
+				{Name: "sub2", Type: "failed"},
+			}},
+			{Name: "s2", Type: "success"},
+		})
+		app.Status.Workflow = &common.WorkflowStatus{
+			Mode: common.WorkflowModeStep,
+			Steps: []common.WorkflowStepStatus{
+				{Name: "group", Phase: common.WorkflowStepPhaseFailed, Reason: wfTypes.StatusReasonSubStepsFailed, SubSteps: &common.SubStepsStatus{
+					Mode: common.WorkflowModeDAG,
+					Steps: []common.WorkflowSubStepStatus{
+						{Name: "sub1", Phase: common.WorkflowStepPhaseSucceeded},
+						{Name: "sub2", Phase: common.WorkflowStepPhaseFailed, Reason: StatusReasonRetriesExhausted},
+					},
+				}},
+				{Name: "s2", Phase: common.WorkflowStepPhaseSkipped},
+			},
+			Terminated: true,
+		}
+		Expect(RetryStep(app, "sub2")).Should(BeNil())
+		Expect(app.Status.Workflow.Steps).Should(BeEquivalentTo([]common.WorkflowStepStatus{
+			{Name: "group", Phase: common.WorkflowStepPhaseRunning, SubSteps: &common.SubStepsStatus{
+				Mode:  common.WorkflowModeDAG,
+				Steps: []common.WorkflowSubStepStatus{{Name: "sub1", Phase: common.WorkflowStepPhaseSucceeded}},
+			}},
+		}))
+	})
+
 	It("step commit data without success", func() {
 		app, runners := makeTestCase([]oamcore.WorkflowStep{
 			{
@@ -396,11 +856,28 @@ func makeTestCase(steps []oamcore.WorkflowStep) (*oamcore.Application, []wfTypes
 	app.Name = "app"
 	runners := []wfTypes.TaskRunner{}
 	for _, step := range steps {
+		if step.Type == "step-group" || (step.Type == "suspend" && step.Properties != nil) {
+			runners = append(runners, makeBuiltinRunner(step))
+			continue
+		}
 		runners = append(runners, makeRunner(step.Name, step.Type))
 	}
 	return app, runners
 }
 
+func makeBuiltinRunner(step oamcore.WorkflowStep) wfTypes.TaskRunner {
+	var subRunners []wfTypes.TaskRunner
+	for _, subStep := range step.SubSteps {
+		subRunners = append(subRunners, makeRunner(subStep.Name, subStep.Type))
+	}
+	discover := tasks.NewTaskDiscover(providers.NewProviders(), nil, k8sClient, nil)
+	gen, err := discover.GetTaskGenerator(context.Background(), step.Type)
+	Expect(err).ToNot(HaveOccurred())
+	runner, err := gen(step, &wfTypes.GeneratorOptions{ID: step.Name, SubTaskRunners: subRunners})
+	Expect(err).ToNot(HaveOccurred())
+	return runner
+}
+
 var pending bool
 
 func makeRunner(name string, tpy string) wfTypes.TaskRunner {
@@ -515,7 +992,7 @@ func (tr *testTaskRunner) Name() string {
 
 // Run execute task.
 func (tr *testTaskRunner) Run(ctx wfContext.Context, options *wfTypes.TaskRunOptions) (common.WorkflowStepStatus, *wfTypes.Operation, error) {
-	return tr.run(ctx, nil)
+	return tr.run(ctx, options)
 }
 
 // Pending check task should be executed or not.
@@ -528,5 +1005,11 @@ func cleanStepTimeStamp(wfStatus *common.WorkflowStatus) {
 	for index := range wfStatus.Steps {
 		wfStatus.Steps[index].FirstExecuteTime = metav1.Time{}
 		wfStatus.Steps[index].LastExecuteTime = metav1.Time{}
+		if wfStatus.Steps[index].SubSteps != nil {
+			for subIndex := range wfStatus.Steps[index].SubSteps.Steps {
+				wfStatus.Steps[index].SubSteps.Steps[subIndex].FirstExecuteTime = metav1.Time{}
+				wfStatus.Steps[index].SubSteps.Steps[subIndex].LastExecuteTime = metav1.Time{}
+			}
+		}
 	}
 }
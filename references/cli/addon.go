@@ -19,11 +19,17 @@ package cli
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"text/template"
 	"time"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/Masterminds/sprig"
 	"github.com/gosuri/uitable"
 	terraformv1beta1 "github.com/oam-dev/terraform-controller/api/v1beta1"
@@ -40,6 +46,7 @@ import (
 	common2 "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
+	pkgaddon "github.com/oam-dev/kubevela/pkg/addon"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/pkg/utils/apply"
@@ -58,6 +65,9 @@ const (
 	AddonTerraformProviderNamespace = "default"
 	// AddonTerraformProviderNameArgument is the argument name of addon terraform provider
 	AddonTerraformProviderNameArgument = "providerName"
+
+	// DefaultAddonRegistry is the registry to read addons from if not specified
+	DefaultAddonRegistry = "https://github.com/oam-dev/catalog/tree/master/addons"
 )
 
 var statusUninstalled = "uninstalled"
@@ -101,6 +111,8 @@ func NewAddonCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 		NewAddonListCommand(),
 		NewAddonEnableCommand(c, ioStreams),
 		NewAddonDisableCommand(ioStreams),
+		NewAddonUpgradeCommand(c, ioStreams),
+		NewAddonPackageCommand(ioStreams),
 	)
 	return cmd
 }
@@ -125,17 +137,29 @@ func NewAddonListCommand() *cobra.Command {
 // NewAddonEnableCommand create addon enable command
 func NewAddonEnableCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.Command {
 	ctx := context.Background()
-	return &cobra.Command{
-		Use:     "enable",
-		Short:   "enable an addon",
-		Long:    "enable an addon in cluster",
-		Example: "vela addon enable <addon-name>",
+	var registry, token, addonPath, digest string
+	var clusters []string
+	var clusterLabels map[string]string
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "enable",
+		Short: "enable an addon",
+		Long:  "enable an addon in cluster",
+		Example: `vela addon enable <addon-name>
+vela addon enable --path ./fluxcd-1.1.0.tgz [--sha256 <digest>] [key=value...]`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			k8sClient, err := c.GetClient()
 			if err != nil {
 				return err
 			}
 
+			if addonPath != "" {
+				return enableAddonFromPath(ctx, k8sClient, ioStream, addonPath, digest, registry, token, args,
+					pkgaddon.NewClusterSelectors(clusters, clusterLabels), yes)
+			}
+			if digest != "" {
+				return fmt.Errorf("--sha256 can only be used along with --path")
+			}
 			if len(args) < 1 {
 				return fmt.Errorf("must specify addon name")
 			}
@@ -144,7 +168,19 @@ func NewAddonEnableCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.Com
 			if err != nil {
 				return err
 			}
-			err = enableAddon(ctx, k8sClient, name, addonArgs)
+			selectors := pkgaddon.NewClusterSelectors(clusters, clusterLabels)
+			if registry != "" {
+				source, err := newAddonSource(registry, token)
+				if err != nil {
+					return err
+				}
+				err = enableAddonFromSource(ctx, k8sClient, ioStream, source, name, addonArgs, selectors, yes)
+			} else {
+				if len(selectors) != 0 {
+					return fmt.Errorf("--clusters and --cluster-labels can only be used along with --registry or --path")
+				}
+				err = enableAddon(ctx, k8sClient, name, addonArgs)
+			}
 			if err != nil {
 				return err
 			}
@@ -152,6 +188,42 @@ func NewAddonEnableCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.Com
 			return nil
 		},
 	}
+	cmd.Flags().StringVar(&registry, "registry", "", "the registry to read the addon from, it can be a GitHub url, a git url, oss://<bucket> or file://<path>")
+	cmd.Flags().StringVar(&token, "token", "", "the token to access the registry")
+	cmd.Flags().StringVar(&addonPath, "path", "", "the path of the addon package made by `vela addon package` or the addon directory, the dependencies are read from --registry if specified")
+	cmd.Flags().StringVar(&digest, "sha256", "", "the expected sha256 digest of the addon package, the <package>.sha256 file next to the package is used if not specified")
+	cmd.Flags().StringSliceVar(&clusters, "clusters", nil, "the names of the clusters to deploy the addon to, e.g. --clusters=local,cluster-1")
+	cmd.Flags().StringToStringVar(&clusterLabels, "cluster-labels", nil, "select the clusters to deploy the addon to by labels, e.g. --cluster-labels=region=hangzhou")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "enable the missing dependencies without confirmation")
+	return cmd
+}
+
+// NewAddonPackageCommand create addon package command
+func NewAddonPackageCommand(ioStream cmdutil.IOStreams) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:     "package",
+		Short:   "package an addon",
+		Long:    "package an addon directory into a versioned and checksummed archive, which can be enabled by `vela addon enable --path` without any registry",
+		Example: "vela addon package <addon-dir> [-o <output-dir>]",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify addon directory")
+			}
+			pkg, err := pkgaddon.PackageAddon(args[0], output)
+			if err != nil {
+				return err
+			}
+			data, err := ioutil.ReadFile(filepath.Clean(pkg))
+			if err != nil {
+				return err
+			}
+			ioStream.Infof("Successfully package addon to %s\nsha256: %x (saved in %s)\n", pkg, sha256.Sum256(data), pkg+pkgaddon.DigestFileExtension)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", ".", "the directory to save the addon package")
+	return cmd
 }
 
 func parseToMap(args []string) (map[string]string, error) {
@@ -192,21 +264,339 @@ func NewAddonDisableCommand(ioStream cmdutil.IOStreams) *cobra.Command {
 	}
 }
 
+// NewAddonUpgradeCommand create addon upgrade command
+func NewAddonUpgradeCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.Command {
+	var registry, token string
+	var dryRun, yes bool
+	cmd := &cobra.Command{
+		Use:     "upgrade",
+		Short:   "upgrade an addon",
+		Long:    "upgrade an enabled addon to the version in the registry or update its args, the diff including the dependencies to enable is shown before applying",
+		Example: "vela addon upgrade <addon-name> [key=value...]",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify addon name")
+			}
+			k8sClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			name := args[0]
+			addonArgs, err := parseToMap(args[1:])
+			if err != nil {
+				return err
+			}
+			source, err := newAddonSource(registry, token)
+			if err != nil {
+				return err
+			}
+			return upgradeAddon(context.Background(), k8sClient, ioStream, source, name, addonArgs, dryRun, yes)
+		},
+	}
+	cmd.Flags().StringVar(&registry, "registry", DefaultAddonRegistry, "the registry to read the addon from, it can be a GitHub url, a git url, oss://<bucket> or file://<path>")
+	cmd.Flags().StringVar(&token, "token", "", "the token to access the registry")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only show the diff without applying the changes")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "apply the changes without confirmation")
+	return cmd
+}
+
+// newAddonSource creates the addon source by the registry url, the url formats are the same as capability registries
+func newAddonSource(regURL, token string) (pkgaddon.AddonSource, error) {
+	tp, cfg, err := Parse(regURL)
+	if err != nil {
+		return nil, err
+	}
+	switch tp {
+	case TypeGithub:
+		return &pkgaddon.GitAddonSource{URL: regURL, Token: token}, nil
+	case TypeOss:
+		u, err := url.Parse(regURL)
+		if err != nil {
+			return nil, err
+		}
+		return &pkgaddon.OSSAddonSource{URL: fmt.Sprintf("https://%s/", cfg.BucketURL), Path: strings.Trim(u.Path, "/")}, nil
+	case TypeLocal:
+		return &pkgaddon.LocalAddonSource{Path: cfg.AbsDir}, nil
+	}
+	if strings.HasPrefix(regURL, "https://") || strings.HasPrefix(regURL, "http://") {
+		return &pkgaddon.GitRepoAddonSource{URL: regURL, Token: token}, nil
+	}
+	return nil, fmt.Errorf("not supported addon registry url %s", regURL)
+}
+
+func upgradeAddon(ctx context.Context, k8sClient client.Client, ioStream cmdutil.IOStreams, source pkgaddon.AddonSource,
+	name string, args map[string]string, dryRun, yes bool) error {
+	addon, err := source.GetAddon(name, pkgaddon.EnableLevelOptions)
+	if err != nil {
+		if errors.Is(err, pkgaddon.ErrNotExist) {
+			return AddonNotFoundErr{addonName: name}
+		}
+		return err
+	}
+	upgrade, err := pkgaddon.PrepareUpgrade(ctx, k8sClient, addon, pkgaddon.ConvertArgs(addon, args))
+	if err != nil {
+		if errors.Is(err, pkgaddon.ErrNotEnabled) {
+			return errors.Errorf("addon %s is not enabled", name)
+		}
+		return err
+	}
+	deps, err := pkgaddon.ResolveDependencies(ctx, k8sClient, source, addon)
+	if err != nil {
+		return errors.Wrapf(err, "fail to resolve the dependencies of addon %s", name)
+	}
+	if err := upgrade.PrepareDependencies(ctx, k8sClient, deps); err != nil {
+		return err
+	}
+
+	if upgrade.PreviousVersion != upgrade.Version {
+		ioStream.Infof("Upgrade addon %s from version %s to %s\n", name, upgrade.PreviousVersion, upgrade.Version)
+	}
+	if len(deps) != 0 {
+		var names []string
+		for _, dep := range deps {
+			names = append(names, dep.Name)
+		}
+		ioStream.Infof("Addon %s depends on the addons which are not enabled, they will be enabled: %s\n", name, strings.Join(names, ", "))
+	}
+	if len(upgrade.Diffs) == 0 {
+		ioStream.Infof("Addon %s is up to date\n", name)
+		return nil
+	}
+	for _, d := range upgrade.Diffs {
+		ioStream.Infof("--- %s %s/%s\n%s", d.Kind, d.Namespace, d.Name, d.Diff)
+	}
+	if dryRun {
+		return nil
+	}
+	if !yes {
+		confirmed := false
+		if err := survey.AskOne(&survey.Confirm{Message: "Do you want to apply the changes?"}, &confirmed); err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	for i, dep := range upgrade.Dependencies {
+		if err := applyAddonResources(ctx, k8sClient, dep.App, dep.Defs, dep.Secret); err != nil {
+			return err
+		}
+		if err := waitApplicationRunning(dep.App); err != nil {
+			return errors.Wrapf(err, "Error occurs when waiting addon %s running", deps[i].Name)
+		}
+		ioStream.Infof("Successfully enable addon:%s\n", deps[i].Name)
+	}
+	if err := applyAddonResources(ctx, k8sClient, upgrade.App, upgrade.Defs, upgrade.Secret); err != nil {
+		return err
+	}
+	ioStream.Infof("Successfully upgrade addon:%s\n", name)
+	return nil
+}
+
+// enableAddonFromSource enables the addon read from the addon source, the missing dependencies are enabled after confirmation
+func enableAddonFromSource(ctx context.Context, k8sClient client.Client, ioStream cmdutil.IOStreams, source pkgaddon.AddonSource,
+	name string, args map[string]string, selectors []common2.ClusterSelector, yes bool) error {
+	err := k8sClient.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: pkgaddon.Convert2AppName(name)}, &v1beta1.Application{})
+	if err == nil {
+		return errors.Errorf("addon %s is already enabled, use `vela addon upgrade` to update it", name)
+	}
+	if !kerrors.IsNotFound(err) {
+		return err
+	}
+	addon, err := source.GetAddon(name, pkgaddon.EnableLevelOptions)
+	if err != nil {
+		if errors.Is(err, pkgaddon.ErrNotExist) {
+			return AddonNotFoundErr{addonName: name}
+		}
+		return err
+	}
+	if err := pkgaddon.ValidateClusterSelectors(ctx, k8sClient, selectors); err != nil {
+		return err
+	}
+	if err := enableAddonDependencies(ctx, k8sClient, ioStream, source, addon, selectors, yes); err != nil {
+		return err
+	}
+
+	addonArgs := pkgaddon.ConvertArgs(addon, args)
+	app, defs, err := pkgaddon.RenderApplication(addon, addonArgs)
+	if err != nil {
+		return errors.Wrapf(err, "fail to render addon %s", name)
+	}
+	if defs, err = pkgaddon.RenderClusterPlacement(app, defs, selectors); err != nil {
+		return errors.Wrapf(err, "fail to render addon %s", name)
+	}
+	if err := applyAddonResources(ctx, k8sClient, app, defs, pkgaddon.RenderArgsSecret(addon, addonArgs)); err != nil {
+		return err
+	}
+	return waitApplicationRunning(app)
+}
+
+// enableAddonFromPath enables the addon read from the addon package or directory, the name of the addon can be omitted
+// from the args. The missing dependencies are read from the registry if specified, otherwise they must have been enabled.
+// The addon package is verified by the digest, or by the digest file next to it if the digest is empty.
+func enableAddonFromPath(ctx context.Context, k8sClient client.Client, ioStream cmdutil.IOStreams, addonPath, digest, registry, token string,
+	args []string, selectors []common2.ClusterSelector, yes bool) error {
+	info, err := os.Stat(addonPath)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.IsDir() && digest != "":
+		return errors.Errorf("--sha256 can only be used with an addon package rather than the directory %s", addonPath)
+	case !info.IsDir():
+		err := pkgaddon.VerifyPackageDigest(addonPath, digest)
+		if errors.Is(err, pkgaddon.ErrDigestNotFound) {
+			ioStream.Infof("Warning: the addon package %s is not verified, specify --sha256 to verify it\n", addonPath)
+		} else if err != nil {
+			return err
+		}
+	}
+	pkgSource, err := pkgaddon.NewPackageAddonSource(addonPath)
+	if err != nil {
+		return err
+	}
+	name := pkgSource.Name()
+	if len(args) > 0 && !strings.Contains(args[0], "=") {
+		if args[0] != name {
+			return errors.Errorf("the addon in %s is %s rather than %s", addonPath, name, args[0])
+		}
+		args = args[1:]
+	}
+	addonArgs, err := parseToMap(args)
+	if err != nil {
+		return err
+	}
+	var source pkgaddon.AddonSource = pkgSource
+	if registry != "" {
+		regSource, err := newAddonSource(registry, token)
+		if err != nil {
+			return err
+		}
+		source = pkgaddon.MultiSource{pkgSource, regSource}
+	}
+	if err := enableAddonFromSource(ctx, k8sClient, ioStream, source, name, addonArgs, selectors, yes); err != nil {
+		return err
+	}
+	ioStream.Infof("Successfully enable addon:%s\n", name)
+	return nil
+}
+
+// enableAddonDependencies resolves the dependencies of the addon and enables the missing ones in install order after confirmation,
+// the dependencies are deployed to the same clusters as the addon
+func enableAddonDependencies(ctx context.Context, k8sClient client.Client, ioStream cmdutil.IOStreams, source pkgaddon.AddonSource,
+	addon *types.Addon, selectors []common2.ClusterSelector, yes bool) error {
+	deps, err := pkgaddon.ResolveDependencies(ctx, k8sClient, source, addon)
+	if err != nil {
+		return errors.Wrapf(err, "fail to resolve the dependencies of addon %s", addon.Name)
+	}
+	if len(deps) == 0 {
+		return nil
+	}
+	var names []string
+	for _, dep := range deps {
+		names = append(names, dep.Name)
+	}
+	ioStream.Infof("Addon %s depends on the addons which are not enabled: %s\n", addon.Name, strings.Join(names, ", "))
+	if !yes {
+		confirmed := false
+		if err := survey.AskOne(&survey.Confirm{Message: "Do you want to enable them?"}, &confirmed); err != nil {
+			return err
+		}
+		if !confirmed {
+			return errors.Errorf("the dependencies of addon %s are not enabled", addon.Name)
+		}
+	}
+	for _, dep := range deps {
+		app, defs, err := pkgaddon.RenderApplication(dep, nil)
+		if err != nil {
+			return errors.Wrapf(err, "fail to render addon %s", dep.Name)
+		}
+		if defs, err = pkgaddon.RenderClusterPlacement(app, defs, selectors); err != nil {
+			return errors.Wrapf(err, "fail to render addon %s", dep.Name)
+		}
+		if err := applyAddonResources(ctx, k8sClient, app, defs, pkgaddon.RenderArgsSecret(dep, nil)); err != nil {
+			return err
+		}
+		if err := waitApplicationRunning(app); err != nil {
+			return errors.Wrapf(err, "Error occurs when waiting addon %s running", dep.Name)
+		}
+		ioStream.Infof("Successfully enable addon:%s\n", dep.Name)
+	}
+	return nil
+}
+
+// applyAddonResources applies the rendered addon application, definitions and args secret
+func applyAddonResources(ctx context.Context, k8sClient client.Client, app *v1beta1.Application, defs []*unstructured.Unstructured, sec *v1.Secret) error {
+	applicator := apply.NewAPIApplicator(k8sClient)
+	if err := applicator.Apply(ctx, app); err != nil {
+		return errors.Wrapf(err, "Error occurs when apply addon application: %s", app.Name)
+	}
+	for _, def := range defs {
+		def.SetOwnerReferences(append(def.GetOwnerReferences(), *metav1.NewControllerRef(app, v1beta1.ApplicationKindVersionKind)))
+		if err := applicator.Apply(ctx, def); err != nil {
+			return errors.Wrapf(err, "Error occurs when apply addon definition: %s", def.GetName())
+		}
+	}
+	if err := applicator.Apply(ctx, sec); err != nil {
+		return errors.Wrap(err, "Error occurs when apply addon args secret")
+	}
+	return nil
+}
+
 func listAddons() error {
 	repo, err := NewAddonRepo()
 	if err != nil {
 		return err
 	}
 	addons := repo.listAddons()
+
+	// the clusters of all the enabled addons, including the ones enabled from addon registries
+	apps := &v1beta1.ApplicationList{}
+	if err := clt.List(context.Background(), apps, client.InNamespace(types.DefaultKubeVelaNS), client.HasLabels{oam.LabelAddonName}); err != nil {
+		return errors.Wrap(err, "fail to list the enabled addons")
+	}
+	clusters := make(map[string]string, len(apps.Items))
+	for i := range apps.Items {
+		app := &apps.Items[i]
+		status, err := pkgaddon.GetClusterStatus(app)
+		if err != nil {
+			return err
+		}
+		clusters[app.Labels[oam.LabelAddonName]] = formatClusterStatus(status)
+	}
+
 	table := uitable.New()
-	table.AddRow("NAME", "DESCRIPTION", "STATUS")
+	table.AddRow("NAME", "DESCRIPTION", "STATUS", "CLUSTERS")
+	listed := map[string]bool{}
 	for _, addon := range addons {
-		table.AddRow(addon.name, addon.description, addon.getStatus())
+		listed[addon.name] = true
+		table.AddRow(addon.name, addon.description, addon.getStatus(), clusters[addon.name])
+	}
+	for i := range apps.Items {
+		app := &apps.Items[i]
+		name := app.Labels[oam.LabelAddonName]
+		if listed[name] {
+			continue
+		}
+		table.AddRow(name, app.Annotations[DescAnnotation], statusInstalled, clusters[name])
 	}
 	fmt.Println(table.String())
 	return nil
 }
 
+func formatClusterStatus(clusters []pkgaddon.ClusterStatus) string {
+	var res []string
+	for _, c := range clusters {
+		health := "healthy"
+		if !c.Healthy {
+			health = "unhealthy"
+		}
+		res = append(res, fmt.Sprintf("%s(%s)", c.Cluster, health))
+	}
+	return strings.Join(res, ",")
+}
+
 func enableAddon(ctx context.Context, k8sClient client.Client, name string, args map[string]string) error {
 	repo, err := NewAddonRepo()
 	if err != nil {
@@ -226,9 +616,31 @@ func enableAddon(ctx context.Context, k8sClient client.Client, name string, args
 }
 
 func disableAddon(name string) error {
+	ctx := context.Background()
+	// the dependencies of the addons enabled before they are recorded are read from the default registry
+	source, err := newAddonSource(DefaultAddonRegistry, "")
+	if err != nil {
+		return err
+	}
+	dependents, err := pkgaddon.FindDependents(ctx, clt, source, name)
+	if err != nil {
+		return err
+	}
+	if len(dependents) != 0 {
+		return errors.Errorf("addon %s is depended on by %s, please disable them first", name, strings.Join(dependents, ", "))
+	}
 	if isLegacyAddonExist(name) {
 		return tryDisableInitializerAddon(name)
 	}
+	app := &v1beta1.Application{}
+	err = clt.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: pkgaddon.Convert2AppName(name)}, app)
+	if err == nil {
+		// the addon is enabled from an addon registry
+		return clt.Delete(ctx, app)
+	}
+	if !kerrors.IsNotFound(err) {
+		return err
+	}
 	repo, err := NewAddonRepo()
 	if err != nil {
 		return err
@@ -44,6 +44,14 @@ const (
 	FlagNamespace = "namespace"
 	// FlagInteractive command flag to specify the use of interactive process
 	FlagInteractive = "interactive"
+	// FlagRevisions command flag to list the revisions of the definition
+	FlagRevisions = "revisions"
+	// FlagApprover command flag to specify the approver of the workflow
+	FlagApprover = "approver"
+	// FlagStep command flag to specify the workflow step
+	FlagStep = "step"
+	// FlagRevision command flag to specify the application revision
+	FlagRevision = "revision"
 )
 
 func addNamespaceArg(cmd *cobra.Command) {
@@ -290,7 +290,9 @@ func NewDefinitionGetCommand(c common.Args) *cobra.Command {
 		Example: "# Command below will get the ComponentDefinition(or other definitions if exists) of webservice in all namespaces\n" +
 			"> vela def get webservice\n" +
 			"# Command below will get the TraitDefinition of annotations in namespace vela-system\n" +
-			"> vela def get annotations --type trait --namespace vela-system",
+			"> vela def get annotations --type trait --namespace vela-system\n" +
+			"# Command below will list the revisions of webservice, which can be referred by `type: webservice@v2` in application\n" +
+			"> vela def get webservice --revisions",
 		Args: cobra.ExactValidArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			definitionType, err := cmd.Flags().GetString(FlagType)
@@ -301,10 +303,17 @@ func NewDefinitionGetCommand(c common.Args) *cobra.Command {
 			if err != nil {
 				return errors.Wrapf(err, "failed to get `%s`", Namespace)
 			}
+			listRevisions, err := cmd.Flags().GetBool(FlagRevisions)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get `%s`", FlagRevisions)
+			}
 			k8sClient, err := c.GetClient()
 			if err != nil {
 				return errors.Wrapf(err, "failed to get k8s client")
 			}
+			if listRevisions {
+				return printDefinitionRevisions(cmd, args[0], k8sClient, definitionType, namespace)
+			}
 			def, err := getSingleDefinition(cmd, args[0], k8sClient, definitionType, namespace)
 			if err != nil {
 				return err
@@ -321,9 +330,28 @@ func NewDefinitionGetCommand(c common.Args) *cobra.Command {
 	}
 	cmd.Flags().StringP(FlagType, "t", "", "Specify which definition type to get. If empty, all types will be searched. Valid types: "+strings.Join(common2.ValidDefinitionTypes(), ", "))
 	cmd.Flags().StringP(Namespace, "n", "", "Specify which namespace to get. If empty, all namespaces will be searched.")
+	cmd.Flags().BoolP(FlagRevisions, "", false, "List the revisions of the definition instead of getting the definition.")
 	return cmd
 }
 
+func printDefinitionRevisions(cmd *cobra.Command, definitionName string, client client.Client, definitionType string, namespace string) error {
+	revisions, err := common2.SearchDefinitionRevisions(definitionName, client, definitionType, namespace)
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 {
+		cmd.Println("No definition revision found.")
+		return nil
+	}
+	table := newUITable()
+	table.AddRow("NAME", "REVISION", "TYPE", "NAMESPACE", "HASH")
+	for _, rev := range revisions {
+		table.AddRow(common2.GetDefinitionRevisionRef(rev), rev.Spec.Revision, rev.Spec.DefinitionType, rev.Namespace, rev.Spec.RevisionHash)
+	}
+	cmd.Println(table)
+	return nil
+}
+
 // NewDefinitionListCommand create the `vela def list` command to list definition from k8s
 func NewDefinitionListCommand(c common.Args) *cobra.Command {
 	cmd := &cobra.Command{
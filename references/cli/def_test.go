@@ -17,6 +17,7 @@ limitations under the License.
 package cli
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -225,6 +226,48 @@ func TestNewDefinitionGetCommand(t *testing.T) {
 	}
 }
 
+func TestNewDefinitionGetRevisionsCommand(t *testing.T) {
+	c := initArgs()
+	traitName := createTrait(c, t)
+	for _, rev := range []int64{2, 1} {
+		if err := c.Client.Create(context.Background(), &v1beta1.DefinitionRevision{
+			ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("%s-v%d", traitName, rev), Namespace: VelaTestNamespace},
+			Spec: v1beta1.DefinitionRevisionSpec{
+				Revision:        rev,
+				DefinitionType:  common3.TraitType,
+				TraitDefinition: v1beta1.TraitDefinition{ObjectMeta: v1.ObjectMeta{Name: traitName}},
+			},
+		}); err != nil {
+			t.Fatalf("failed to create definition revision: %v", err)
+		}
+	}
+	cmd := NewDefinitionGetCommand(c)
+	initCommand(cmd)
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{traitName, "--revisions"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpeced error when executing get command: %v", err)
+	}
+	out := buf.String()
+	v1Index, v2Index := strings.Index(out, traitName+"@v1"), strings.Index(out, traitName+"@v2")
+	if v1Index < 0 || v2Index < v1Index {
+		t.Fatalf("expect the revisions are listed in order, got: %s", out)
+	}
+	// the revisions of other types are not listed
+	cmd = NewDefinitionGetCommand(c)
+	initCommand(cmd)
+	buf.Reset()
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{traitName, "--revisions", "--type", "component"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpeced error when executing get command: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No definition revision found.") {
+		t.Fatalf("expect no revision found, got: %s", buf.String())
+	}
+}
+
 func TestNewDefinitionListCommand(t *testing.T) {
 	c := initArgs()
 	// normal test
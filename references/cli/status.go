@@ -18,6 +18,7 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -132,7 +133,41 @@ func printAppStatus(_ context.Context, c client.Client, ioStreams cmdutil.IOStre
 	cmd.Printf("%s\n\n", table.String())
 
 	cmd.Printf("Services:\n\n")
-	return loopCheckStatus(c, ioStreams, appName, namespace)
+	if err := loopCheckStatus(c, ioStreams, appName, namespace); err != nil {
+		return err
+	}
+	return printDriftedResources(c, ioStreams, appName, namespace)
+}
+
+// printDriftedResources prints the applied resources that have drifted from their applied configurations
+func printDriftedResources(c client.Client, ioStreams cmdutil.IOStreams, appName string, namespace string) error {
+	remoteApp, err := loadRemoteApplication(c, namespace, appName)
+	if err != nil {
+		return err
+	}
+	if len(remoteApp.Status.DriftedResources) == 0 {
+		return nil
+	}
+	ioStreams.Info("Drifted Resources:\n")
+	for _, res := range remoteApp.Status.DriftedResources {
+		name := fmt.Sprintf("%s %s", res.Kind, res.Name)
+		if res.Namespace != "" {
+			name = fmt.Sprintf("%s %s/%s", res.Kind, res.Namespace, res.Name)
+		}
+		if res.Cluster != "" {
+			name = fmt.Sprintf("%s (cluster: %s)", name, res.Cluster)
+		}
+		ioStreams.Infof("  - %s\n", white.Sprint(name))
+		if res.Missing {
+			ioStreams.Infof("    %sresource is missing\n", emojiFail)
+			continue
+		}
+		for _, field := range res.Fields {
+			ioStreams.Infof("    * %s\n", field)
+		}
+	}
+	ioStreams.Info("")
+	return nil
 }
 
 func loadRemoteApplication(c client.Client, ns string, name string) (*v1beta1.Application, error) {
@@ -19,15 +19,22 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os/user"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	common2 "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/pkg/workflow"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
 	"github.com/oam-dev/kubevela/references/appfile"
 )
 
@@ -47,6 +54,9 @@ func NewWorkflowCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comma
 		NewWorkflowTerminateCommand(c, ioStreams),
 		NewWorkflowRestartCommand(c, ioStreams),
 		NewWorkflowRollbackCommand(c, ioStreams),
+		NewWorkflowListCommand(c, ioStreams),
+		NewWorkflowInspectCommand(c, ioStreams),
+		NewWorkflowDebugCommand(c, ioStreams),
 	)
 	return cmd
 }
@@ -97,8 +107,8 @@ func NewWorkflowResumeCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.
 	cmd := &cobra.Command{
 		Use:     "resume",
 		Short:   "Resume a suspend application workflow",
-		Long:    "Resume a suspend application workflow in cluster",
-		Example: "vela workflow resume <application-name>",
+		Long:    "Resume a suspend application workflow in cluster, the suspend steps waiting for approval are approved by the approver",
+		Example: "vela workflow resume <application-name> [--approver <approver>]",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 {
 				return fmt.Errorf("must specify application name")
@@ -132,7 +142,14 @@ func NewWorkflowResumeCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.
 				return err
 			}
 
-			err = resumeWorkflow(kubecli, app)
+			approver, err := cmd.Flags().GetString(FlagApprover)
+			if err != nil {
+				return err
+			}
+			if approver == "" {
+				approver = defaultApprover()
+			}
+			err = resumeWorkflow(kubecli, app, approver)
 			if err != nil {
 				return err
 			}
@@ -140,6 +157,7 @@ func NewWorkflowResumeCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.
 		},
 	}
 	addNamespaceArg(cmd)
+	cmd.Flags().String(FlagApprover, "", "specify the approver of the suspend steps waiting for approval, default to the current os user")
 	return cmd
 }
 
@@ -187,10 +205,11 @@ func NewWorkflowTerminateCommand(c common.Args, ioStream cmdutil.IOStreams) *cob
 // NewWorkflowRestartCommand create workflow restart command
 func NewWorkflowRestartCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "restart",
-		Short:   "Restart an application workflow",
-		Long:    "Restart an application workflow in cluster",
-		Example: "vela workflow restart <application-name>",
+		Use:   "restart",
+		Short: "Restart an application workflow",
+		Long: "Restart an application workflow in cluster. If a failed step is specified, only the step and the steps " +
+			"following it are executed again, the succeeded steps and their outputs are kept.",
+		Example: "vela workflow restart <application-name> [--step <failed-step-name>]",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 {
 				return fmt.Errorf("must specify application name")
@@ -213,6 +232,13 @@ func NewWorkflowRestartCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra
 			if err != nil {
 				return err
 			}
+			stepName, err := cmd.Flags().GetString(FlagStep)
+			if err != nil {
+				return err
+			}
+			if stepName != "" {
+				return retryWorkflowStep(kubecli, app, stepName)
+			}
 
 			err = restartWorkflow(kubecli, app)
 			if err != nil {
@@ -222,6 +248,7 @@ func NewWorkflowRestartCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra
 		},
 	}
 	addNamespaceArg(cmd)
+	cmd.Flags().String(FlagStep, "", "specify the failed step to retry, the whole workflow is restarted if not set")
 	return cmd
 }
 
@@ -266,6 +293,233 @@ func NewWorkflowRollbackCommand(c common.Args, ioStream cmdutil.IOStreams) *cobr
 	return cmd
 }
 
+// NewWorkflowListCommand create workflow list command
+func NewWorkflowListCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the workflow runs of an application",
+		Long:    "List the finished workflow runs of an application, the latest run comes first",
+		Example: "vela workflow list <application-name>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify application name")
+			}
+			namespace, err := GetFlagNamespaceOrEnv(cmd, c)
+			if err != nil {
+				return err
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			return listWorkflowRuns(kubecli, ioStream, namespace, args[0])
+		},
+	}
+	addNamespaceArg(cmd)
+	return cmd
+}
+
+// NewWorkflowInspectCommand create workflow inspect command
+func NewWorkflowInspectCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "inspect",
+		Short:   "Inspect a workflow run",
+		Long:    "Inspect the steps, timing and outputs of a workflow run",
+		Example: "vela workflow inspect <workflow-run-name>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify workflow run name")
+			}
+			namespace, err := GetFlagNamespaceOrEnv(cmd, c)
+			if err != nil {
+				return err
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			run := &v1beta1.WorkflowRun{}
+			if err := kubecli.Get(context.TODO(), k8stypes.NamespacedName{Namespace: namespace, Name: args[0]}, run); err != nil {
+				return err
+			}
+			printWorkflowRun(ioStream, run)
+			return nil
+		},
+	}
+	addNamespaceArg(cmd)
+	return cmd
+}
+
+// NewWorkflowDebugCommand create workflow debug command
+func NewWorkflowDebugCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "debug",
+		Short:   "Debug the workflow steps of an application",
+		Long:    "Show the rendered parameter, provider calls, outputs and errors of the workflow steps of an application",
+		Example: "vela workflow debug <application-name> [--step <step-name>] [--revision <revision-name>]",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify application name")
+			}
+			namespace, err := GetFlagNamespaceOrEnv(cmd, c)
+			if err != nil {
+				return err
+			}
+			stepName, err := cmd.Flags().GetString(FlagStep)
+			if err != nil {
+				return err
+			}
+			revision, err := cmd.Flags().GetString(FlagRevision)
+			if err != nil {
+				return err
+			}
+			app, err := appfile.LoadApplication(namespace, args[0], c)
+			if err != nil {
+				return err
+			}
+			if app.Spec.Workflow == nil {
+				return fmt.Errorf("the application must have workflow")
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			return debugWorkflow(kubecli, ioStream, app, stepName, revision)
+		},
+	}
+	addNamespaceArg(cmd)
+	cmd.Flags().String(FlagStep, "", "specify the workflow step to debug, default to all the steps")
+	cmd.Flags().String(FlagRevision, "", "specify the application revision to debug, default to the latest revision")
+	return cmd
+}
+
+func debugWorkflow(kubecli client.Client, ioStream cmdutil.IOStreams, app *v1beta1.Application, stepName, revision string) error {
+	if revision != "" && (app.Status.LatestRevision == nil || app.Status.LatestRevision.Name != revision) {
+		// only the latest workflow run keeps its context, the history runs are inspected instead.
+		runs, err := workflow.ListRuns(context.TODO(), kubecli, app.Namespace, app.Name)
+		if err != nil {
+			return err
+		}
+		for i := range runs {
+			if runs[i].Spec.AppRevision == revision {
+				printWorkflowRun(ioStream, &runs[i])
+				return nil
+			}
+		}
+		return fmt.Errorf("no workflow run of revision %s found", revision)
+	}
+	if app.Status.Workflow == nil || app.Status.Workflow.ContextBackend == nil {
+		return fmt.Errorf("the workflow in application is not running")
+	}
+	wfCtx, err := wfContext.LoadContext(kubecli, app.Namespace, app.Name)
+	if err != nil {
+		return errors.WithMessage(err, "load workflow context")
+	}
+
+	outputs := map[string]v1beta1.WorkflowStep{}
+	for _, step := range app.Spec.Workflow.Steps {
+		outputs[step.Name] = step
+		for _, sub := range step.SubSteps {
+			outputs[sub.Name] = sub.WorkflowStep()
+		}
+	}
+	found := false
+	printStep := func(name, tpy string, phase common2.WorkflowStepPhase, reason, message, indent string) {
+		if stepName != "" && name != stepName {
+			return
+		}
+		found = true
+		ioStream.Infof("%sStep: %s\tType: %s\tPhase: %s\n", indent, name, tpy, phase)
+		if reason != "" || message != "" {
+			ioStream.Infof("%s  Reason: %s\tMessage: %s\n", indent, reason, message)
+		}
+		if info := wfCtx.GetStepDebugInfo(name); info != nil {
+			if info.Error != "" {
+				ioStream.Infof("%s  Error: %s\n", indent, info.Error)
+			}
+			if info.Parameter != "" {
+				ioStream.Infof("%s  Parameter:\n%s\n", indent, indentText(info.Parameter, indent+"    "))
+			}
+			for _, call := range info.ProviderCalls {
+				ioStream.Infof("%s  Provider Call: phase=%s provider=%s do=%s\n%s\n", indent, call.Phase, call.Provider, call.Do, indentText(call.Value, indent+"    "))
+			}
+		}
+		for _, output := range outputs[name].Outputs {
+			v, err := wfCtx.GetVar(output.Name)
+			if err != nil {
+				ioStream.Infof("%s  Output %s: <not exported>\n", indent, output.Name)
+				continue
+			}
+			s, err := v.String()
+			if err != nil {
+				s = err.Error()
+			}
+			ioStream.Infof("%s  Output %s:\n%s\n", indent, output.Name, indentText(s, indent+"    "))
+		}
+	}
+	for _, step := range app.Status.Workflow.Steps {
+		printStep(step.Name, step.Type, step.Phase, step.Reason, step.Message, "")
+		if step.SubSteps != nil {
+			for _, sub := range step.SubSteps.Steps {
+				printStep(sub.Name, sub.Type, sub.Phase, sub.Reason, sub.Message, "  ")
+			}
+		}
+	}
+	if stepName != "" && !found {
+		return fmt.Errorf("step %s is not executed", stepName)
+	}
+	return nil
+}
+
+func indentText(s, indent string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i := range lines {
+		lines[i] = indent + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func listWorkflowRuns(kubecli client.Client, ioStream cmdutil.IOStreams, namespace, appName string) error {
+	runs, err := workflow.ListRuns(context.TODO(), kubecli, namespace, appName)
+	if err != nil {
+		return err
+	}
+	table := newUITable()
+	table.AddRow("NAME", "REVISION", "PHASE", "STEPS", "START-TIME", "DURATION")
+	for _, run := range runs {
+		table.AddRow(run.Name, run.Spec.AppRevision, run.Status.Phase, len(run.Status.Steps),
+			run.Status.StartTime.Format(time.RFC3339), run.Status.EndTime.Sub(run.Status.StartTime.Time).Round(time.Second))
+	}
+	ioStream.Info(table.String())
+	return nil
+}
+
+func printWorkflowRun(ioStream cmdutil.IOStreams, run *v1beta1.WorkflowRun) {
+	ioStream.Infof("Name:\t\t%s\n", run.Name)
+	ioStream.Infof("Application:\t%s\n", run.Spec.AppName)
+	ioStream.Infof("Revision:\t%s\n", run.Spec.AppRevision)
+	ioStream.Infof("Phase:\t\t%s\n", run.Status.Phase)
+	ioStream.Infof("Mode:\t\t%s\n", run.Status.Mode)
+	ioStream.Infof("Start Time:\t%s\n", run.Status.StartTime.Format(time.RFC3339))
+	ioStream.Infof("End Time:\t%s\n", run.Status.EndTime.Format(time.RFC3339))
+	ioStream.Info("Steps:")
+	table := newUITable()
+	table.AddRow("NAME", "TYPE", "PHASE", "ATTEMPTS", "REASON", "MESSAGE")
+	for _, step := range run.Status.Steps {
+		table.AddRow(step.Name, step.Type, step.Phase, step.Attempts, step.Reason, step.Message)
+		if step.SubSteps != nil {
+			for _, sub := range step.SubSteps.Steps {
+				table.AddRow("  "+sub.Name, sub.Type, sub.Phase, sub.Attempts, sub.Reason, sub.Message)
+			}
+		}
+	}
+	ioStream.Info(table.String())
+	if run.Status.Outputs != "" {
+		ioStream.Info("Outputs:")
+		ioStream.Info(run.Status.Outputs)
+	}
+}
+
 func suspendWorkflow(kubecli client.Client, app *v1beta1.Application) error {
 	// set the workflow suspend to true
 	app.Status.Workflow.Suspend = true
@@ -278,9 +532,12 @@ func suspendWorkflow(kubecli client.Client, app *v1beta1.Application) error {
 	return nil
 }
 
-func resumeWorkflow(kubecli client.Client, app *v1beta1.Application) error {
+func resumeWorkflow(kubecli client.Client, app *v1beta1.Application, approver string) error {
 	// set the workflow suspend to false
 	app.Status.Workflow.Suspend = false
+	if workflow.ApproveSuspendedSteps(app.Status.Workflow, approver, true) {
+		fmt.Printf("Workflow steps are approved by %s\n", approver)
+	}
 
 	if err := kubecli.Status().Patch(context.TODO(), app, client.Merge); err != nil {
 		return err
@@ -290,6 +547,14 @@ func resumeWorkflow(kubecli client.Client, app *v1beta1.Application) error {
 	return nil
 }
 
+// defaultApprover returns the name of the current os user as the approver.
+func defaultApprover() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "unknown"
+}
+
 func terminateWorkflow(kubecli client.Client, app *v1beta1.Application) error {
 	// set the workflow terminated to true
 	app.Status.Workflow.Terminated = true
@@ -314,6 +579,18 @@ func restartWorkflow(kubecli client.Client, app *v1beta1.Application) error {
 	return nil
 }
 
+func retryWorkflowStep(kubecli client.Client, app *v1beta1.Application, stepName string) error {
+	if err := workflow.RetryStep(app, stepName); err != nil {
+		return err
+	}
+	if err := kubecli.Status().Update(context.TODO(), app); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully retry workflow step %s: %s\n", stepName, app.Name)
+	return nil
+}
+
 func rollbackWorkflow(kubecli client.Client, app *v1beta1.Application) error {
 	if app.Status.LatestRevision == nil || app.Status.LatestRevision.Name == "" {
 		return fmt.Errorf("the latest revision is not set: %s", app.Name)
@@ -17,10 +17,13 @@ limitations under the License.
 package cli
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -30,7 +33,10 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	"github.com/oam-dev/kubevela/pkg/oam"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
 )
 
 var workflowSpec = v1beta1.ApplicationSpec{
@@ -142,8 +148,10 @@ func TestWorkflowResume(t *testing.T) {
 	ctx := context.TODO()
 
 	testCases := map[string]struct {
-		app         *v1beta1.Application
-		expectedErr error
+		app              *v1beta1.Application
+		approver         string
+		expectedErr      error
+		expectedApprover string
 	}{
 		"no app name specified": {
 			expectedErr: fmt.Errorf("must specify application name"),
@@ -211,6 +219,28 @@ func TestWorkflowResume(t *testing.T) {
 				},
 			},
 		},
+		"approve successfully": {
+			app: &v1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "workflow-approval",
+					Namespace: "default",
+				},
+				Spec: workflowSpec,
+				Status: common.AppStatus{
+					Workflow: &common.WorkflowStatus{
+						Suspend: true,
+						Steps: []common.WorkflowStepStatus{{
+							Name:     "approve",
+							Type:     "suspend",
+							Phase:    common.WorkflowStepPhaseRunning,
+							Approval: &common.WorkflowStepApproval{},
+						}},
+					},
+				},
+			},
+			approver:         "admin",
+			expectedApprover: "admin",
+		},
 	}
 
 	for name, tc := range testCases {
@@ -234,6 +264,9 @@ func TestWorkflowResume(t *testing.T) {
 				} else {
 					cmd.SetArgs([]string{tc.app.Name})
 				}
+				if tc.approver != "" {
+					r.NoError(cmd.Flags().Set(FlagApprover, tc.approver))
+				}
 			}
 			err := cmd.Execute()
 			if tc.expectedErr != nil {
@@ -249,6 +282,11 @@ func TestWorkflowResume(t *testing.T) {
 			}, wf)
 			r.NoError(err)
 			r.Equal(false, wf.Status.Workflow.Suspend)
+			if tc.expectedApprover != "" {
+				approval := wf.Status.Workflow.Steps[0].Approval
+				r.Equal(tc.expectedApprover, approval.Approver)
+				r.NotNil(approval.ApproveTime)
+			}
 		})
 	}
 }
@@ -430,6 +468,60 @@ func TestWorkflowRestart(t *testing.T) {
 	}
 }
 
+func TestWorkflowRestartStep(t *testing.T) {
+	c := initArgs()
+	r := require.New(t)
+	ioStream := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	ctx := context.TODO()
+
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "workflow-retry",
+			Namespace: "default",
+		},
+		Spec: v1beta1.ApplicationSpec{
+			Components: workflowSpec.Components,
+			Workflow: &v1beta1.Workflow{
+				Steps: []v1beta1.WorkflowStep{
+					{Name: "step1", Type: "foowf"},
+					{Name: "step2", Type: "foowf"},
+					{Name: "step3", Type: "foowf"},
+				},
+			},
+		},
+		Status: common.AppStatus{
+			Workflow: &common.WorkflowStatus{
+				Mode: common.WorkflowModeStep,
+				Steps: []common.WorkflowStepStatus{
+					{Name: "step1", Phase: common.WorkflowStepPhaseSucceeded},
+					{Name: "step2", Phase: common.WorkflowStepPhaseFailed},
+					{Name: "step3", Phase: common.WorkflowStepPhaseSkipped},
+				},
+				Terminated: true,
+				Finished:   true,
+			},
+		},
+	}
+	r.NoError(c.Client.Create(ctx, app))
+
+	cmd := NewWorkflowRestartCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{app.Name, "--step", "step1"})
+	r.EqualError(cmd.Execute(), "step step1 is succeeded, only the failed step can be retried")
+
+	cmd = NewWorkflowRestartCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{app.Name, "--step", "step2"})
+	r.NoError(cmd.Execute())
+
+	wf := &v1beta1.Application{}
+	r.NoError(c.Client.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, wf))
+	r.False(wf.Status.Workflow.Terminated)
+	r.False(wf.Status.Workflow.Finished)
+	r.Equal(1, len(wf.Status.Workflow.Steps))
+	r.Equal("step1", wf.Status.Workflow.Steps[0].Name)
+}
+
 func TestWorkflowRollback(t *testing.T) {
 	c := initArgs()
 	ioStream := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
@@ -563,3 +655,123 @@ func TestWorkflowRollback(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkflowListAndInspect(t *testing.T) {
+	c := initArgs()
+	r := require.New(t)
+	buffer := bytes.NewBuffer(nil)
+	ioStream := cmdutil.IOStreams{In: os.Stdin, Out: buffer, ErrOut: os.Stderr}
+	ctx := context.TODO()
+
+	start := metav1.NewTime(time.Now().Add(-time.Hour))
+	for i, name := range []string{"app-run-1", "app-run-2"} {
+		run := &v1beta1.WorkflowRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{oam.LabelAppName: "app-with-runs"},
+			},
+			Spec: v1beta1.WorkflowRunSpec{AppName: "app-with-runs", AppRevision: fmt.Sprintf("app-with-runs-v%d", i+1)},
+			Status: v1beta1.WorkflowRunStatus{
+				Phase:     common.WorkflowStateSucceeded,
+				StartTime: metav1.NewTime(start.Add(time.Duration(i) * time.Minute)),
+				EndTime:   metav1.NewTime(start.Add(time.Duration(i+1) * time.Minute)),
+				Steps: []common.WorkflowStepStatus{{
+					Name:  "deploy",
+					Type:  "apply-application",
+					Phase: common.WorkflowStepPhaseSucceeded,
+				}},
+				Outputs: `message: "hello"`,
+			},
+		}
+		r.NoError(c.Client.Create(ctx, run))
+	}
+
+	cmd := NewWorkflowListCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{"app-with-runs"})
+	r.NoError(cmd.Execute())
+	out := buffer.String()
+	r.Contains(out, "app-with-runs-v1")
+	r.Less(strings.Index(out, "app-run-2"), strings.Index(out, "app-run-1"))
+
+	buffer.Reset()
+	cmd = NewWorkflowInspectCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{"app-run-1"})
+	r.NoError(cmd.Execute())
+	out = buffer.String()
+	r.Contains(out, "apply-application")
+	r.Contains(out, `message: "hello"`)
+
+	cmd = NewWorkflowInspectCommand(c, ioStream)
+	initCommand(cmd)
+	r.Equal(fmt.Errorf("must specify workflow run name"), cmd.Execute())
+}
+
+func TestWorkflowDebug(t *testing.T) {
+	c := initArgs()
+	r := require.New(t)
+	buffer := bytes.NewBuffer(nil)
+	ioStream := cmdutil.IOStreams{In: os.Stdin, Out: buffer, ErrOut: os.Stderr}
+	ctx := context.TODO()
+
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-debug",
+			Namespace: "default",
+		},
+		Spec: v1beta1.ApplicationSpec{
+			Components: workflowSpec.Components,
+			Workflow: &v1beta1.Workflow{
+				Steps: []v1beta1.WorkflowStep{{
+					Name:    "step1",
+					Type:    "foowf",
+					Outputs: common.StepOutputs{{Name: "podIP", ValueFrom: "status.podIP"}},
+				}},
+			},
+		},
+	}
+	r.NoError(c.Client.Create(ctx, app))
+	wfCtx, err := wfContext.NewContext(c.Client, "default", "app-debug", "")
+	r.NoError(err)
+	v, err := value.NewValue(`"1.1.1.1"`, nil, "")
+	r.NoError(err)
+	r.NoError(wfCtx.SetVar(v, "podIP"))
+	wfCtx.SetStepDebugInfo("step1", &wfContext.StepDebugInfo{
+		Parameter: `namespace: "default"`,
+		Error:     "apply failed",
+	})
+	r.NoError(wfCtx.Commit())
+	app.Status.Workflow = &common.WorkflowStatus{
+		ContextBackend: wfCtx.StoreRef(),
+		Steps: []common.WorkflowStepStatus{{
+			Name:    "step1",
+			Type:    "foowf",
+			Phase:   common.WorkflowStepPhaseFailed,
+			Reason:  "Execute",
+			Message: "apply failed",
+		}},
+	}
+	r.NoError(c.Client.Status().Update(ctx, app))
+
+	cmd := NewWorkflowDebugCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{"app-debug"})
+	r.NoError(cmd.Execute())
+	out := buffer.String()
+	r.Contains(out, "Step: step1")
+	r.Contains(out, `namespace: "default"`)
+	r.Contains(out, "Error: apply failed")
+	r.Contains(out, `"1.1.1.1"`)
+
+	cmd = NewWorkflowDebugCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{"app-debug", "--step", "not-exist"})
+	r.Equal(fmt.Errorf("step not-exist is not executed"), cmd.Execute())
+
+	cmd = NewWorkflowDebugCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{"app-debug", "--revision", "app-debug-v0"})
+	r.Equal(fmt.Errorf("no workflow run of revision app-debug-v0 found"), cmd.Execute())
+}
@@ -19,6 +19,7 @@ package common
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"cuelang.org/go/cue"
@@ -33,6 +34,7 @@ import (
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	commontypes "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	velacue "github.com/oam-dev/kubevela/pkg/cue"
 	"github.com/oam-dev/kubevela/pkg/cue/model/sets"
@@ -59,6 +61,14 @@ var (
 		"scope":         v1beta1.ScopeDefinitionKind,
 		"workflow-step": v1beta1.WorkflowStepDefinitionKind,
 	}
+
+	// definitionKindToRevisionType maps the kinds of definitions which have revisions to the types in DefinitionRevision
+	definitionKindToRevisionType = map[string]commontypes.DefinitionType{
+		v1beta1.ComponentDefinitionKind:    commontypes.ComponentType,
+		v1beta1.TraitDefinitionKind:        commontypes.TraitType,
+		v1beta1.PolicyDefinitionKind:       commontypes.PolicyType,
+		v1beta1.WorkflowStepDefinitionKind: commontypes.WorkflowStepType,
+	}
 )
 
 // Definition the general struct for handling all kinds of definitions like ComponentDefinition or TraitDefinition
@@ -411,6 +421,68 @@ func SearchDefinition(definitionName string, c client.Client, definitionType str
 	return definitions, nil
 }
 
+// SearchDefinitionRevisions searches the DefinitionRevisions of the definition with given name, the type and namespace
+// of the definition are optional. The revisions are sorted by namespace and revision number.
+func SearchDefinitionRevisions(definitionName string, c client.Client, definitionType string, namespace string) ([]v1beta1.DefinitionRevision, error) {
+	var defType commontypes.DefinitionType
+	if definitionType != "" {
+		kind, ok := DefinitionTypeToKind[definitionType]
+		if !ok {
+			return nil, fmt.Errorf("invalid definition type %s", definitionType)
+		}
+		if defType, ok = definitionKindToRevisionType[kind]; !ok {
+			return nil, fmt.Errorf("definition type %s has no revision", definitionType)
+		}
+	}
+	var listOptions []client.ListOption
+	if namespace != "" {
+		listOptions = []client.ListOption{client.InNamespace(namespace)}
+	}
+	revs := v1beta1.DefinitionRevisionList{}
+	if err := c.List(context.Background(), &revs, listOptions...); err != nil {
+		return nil, errors.Wrapf(err, "failed to list DefinitionRevision")
+	}
+	var revisions []v1beta1.DefinitionRevision
+	for _, rev := range revs.Items {
+		if defType != "" && rev.Spec.DefinitionType != defType {
+			continue
+		}
+		if GetDefinitionNameOfRevision(rev) == definitionName {
+			revisions = append(revisions, rev)
+		}
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		if revisions[i].Namespace != revisions[j].Namespace {
+			return revisions[i].Namespace < revisions[j].Namespace
+		}
+		return revisions[i].Spec.Revision < revisions[j].Spec.Revision
+	})
+	return revisions, nil
+}
+
+// GetDefinitionNameOfRevision returns the name of the definition recorded in the DefinitionRevision
+func GetDefinitionNameOfRevision(rev v1beta1.DefinitionRevision) string {
+	switch rev.Spec.DefinitionType {
+	case commontypes.ComponentType:
+		return rev.Spec.ComponentDefinition.Name
+	case commontypes.TraitType:
+		return rev.Spec.TraitDefinition.Name
+	case commontypes.PolicyType:
+		return rev.Spec.PolicyDefinition.Name
+	case commontypes.WorkflowStepType:
+		return rev.Spec.WorkflowStepDefinition.Name
+	default:
+		return ""
+	}
+}
+
+// GetDefinitionRevisionRef returns the reference of the DefinitionRevision which can be used as the type in
+// Application, e.g., the revision worker-v2 of the definition worker is referred by worker@v2
+func GetDefinitionRevisionRef(rev v1beta1.DefinitionRevision) string {
+	name := GetDefinitionNameOfRevision(rev)
+	return name + "@" + strings.TrimPrefix(rev.Name, name+"-")
+}
+
 // GetDefinitionDefaultSpec returns the default spec of Definition with given kind. This may be implemented with cue in the future.
 func GetDefinitionDefaultSpec(kind string) map[string]interface{} {
 	switch kind {
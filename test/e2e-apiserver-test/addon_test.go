@@ -52,10 +52,12 @@ func get(path string) *http.Response {
 var _ = Describe("Test addon rest api", func() {
 	createReq := apis.CreateAddonRegistryRequest{
 		Name: "test-addon-registry-1",
-		Git: &addon.GitAddonSource{
-			URL:   "https://github.com/oam-dev/catalog",
-			Path:  "addons/",
-			Token: os.Getenv("GITHUB_TOKEN"),
+		RegistrySource: addon.RegistrySource{
+			Git: &addon.GitAddonSource{
+				URL:   "https://github.com/oam-dev/catalog",
+				Path:  "addons/",
+				Token: os.Getenv("GITHUB_TOKEN"),
+			},
 		},
 	}
 	It("should add a registry and list addons from it", func() {
@@ -1,6 +1,5 @@
 import (
 	"vela/op"
-	"encoding/base64"
 )
 
 "webhook-notification": {
@@ -13,7 +12,7 @@ template: {
 
 	parameter: {
 		dingding?: {
-			url: value | secretRef
+			url: secretValue
 			message: {
 				text?: *null | {
 					content: string
@@ -58,7 +57,7 @@ template: {
 		}
 
 		slack?: {
-			url: value | secretRef
+			url: secretValue
 			message: {
 				text:         string
 				blocks?:      *null | [...block]
@@ -71,11 +70,27 @@ template: {
 			}
 		}
 
+		lark?: {
+			url: secretValue
+			message: {
+				// +usage=msg_type can be text, post, image, share_chat, interactive
+				msg_type: string
+				content?: {...}
+				card?: {...}
+			}
+		}
+
+		// +usage=Send the message as json to any webhook
+		webhook?: {
+			url: secretValue
+			message: {...}
+		}
+
 		email?: {
 			from: {
 				address:  string
 				alias?:   string
-				password: value | secretRef
+				password: secretValue
 				host:     string
 				port:     *587 | int
 			}
@@ -140,112 +155,71 @@ template: {
 	secretRef: {
 		name: string
 		key:  string
+		// +usage=The cluster where the secret locates, default to the control plane
+		cluster?: string
 	}
 
-	value: string
+	// +usage=Specify the value directly or in a secret of the application namespace, the secret is read when the step runs
+	secretValue: {
+		value?:     string
+		secretRef?: secretRef
+	}
 
-	// send webhook notification
+	// send webhook notification, the string fields in the message can be go templates rendered with the workflow context
 	ding: op.#Steps & {
 		if parameter.dingding != _|_ {
-			if parameter.dingding.url.value != _|_ {
-				ding1: op.#DingTalk & {
-					message: parameter.dingding.message
-					dingUrl: parameter.dingding.url.value
-				}
-			}
-			if parameter.dingding.url.secretRef != _|_ && parameter.dingding.url.value == _|_ {
-				read: op.#Read & {
-					value: {
-						apiVersion: "v1"
-						kind:       "Secret"
-						metadata: {
-							name:      parameter.dingding.url.secretRef.name
-							namespace: context.namespace
-						}
-					}
-				}
-
-				decoded:     base64.Decode(null, read.value.data[parameter.dingding.url.secretRef.key])
-				stringValue: op.#ConvertString & {bt: decoded}
-				ding2:       op.#DingTalk & {
-					message: parameter.dingding.message
-					dingUrl: stringValue.str
-				}
+			notify: op.#Notify & {
+				type:    "dingding"
+				url:     parameter.dingding.url
+				message: parameter.dingding.message
 			}
 		}
 	}
 
 	slack: op.#Steps & {
 		if parameter.slack != _|_ {
-			if parameter.slack.url.value != _|_ {
-				slack1: op.#Slack & {
-					message:  parameter.slack.message
-					slackUrl: parameter.slack.url.value
-				}
+			notify: op.#Notify & {
+				type:    "slack"
+				url:     parameter.slack.url
+				message: parameter.slack.message
 			}
-			if parameter.slack.url.secretRef != _|_ && parameter.slack.url.value == _|_ {
-				read: op.#Read & {
-					value: {
-						kind:       "Secret"
-						apiVersion: "v1"
-						metadata: {
-							name:      parameter.slack.url.secretRef.name
-							namespace: context.namespace
-						}
-					}
-				}
+		}
+	}
 
-				decoded:     base64.Decode(null, read.value.data[parameter.slack.url.secretRef.key])
-				stringValue: op.#ConvertString & {bt: decoded}
-				slack2:      op.#Slack & {
-					message:  parameter.slack.message
-					slackUrl: stringValue.str
-				}
+	lark: op.#Steps & {
+		if parameter.lark != _|_ {
+			notify: op.#Notify & {
+				type:    "lark"
+				url:     parameter.lark.url
+				message: parameter.lark.message
 			}
 		}
 	}
 
-	email: op.#Steps & {
-		if parameter.email != _|_ {
-			if parameter.email.from.password.value != _|_ {
-				email1: op.#SendEmail & {
-					from: {
-						address:  parameter.email.from.value
-						alias:    parameter.email.from.alias
-						password: parameter.email.from.password.value
-						host:     parameter.email.from.host
-						port:     parameter.email.from.port
-					}
-					to:      parameter.email.to
-					content: parameter.email.content
-				}
+	webhook: op.#Steps & {
+		if parameter.webhook != _|_ {
+			notify: op.#Notify & {
+				type:    "webhook"
+				url:     parameter.webhook.url
+				message: parameter.webhook.message
 			}
+		}
+	}
 
-			if parameter.email.from.password.secretRef != _|_ && parameter.email.from.password.value == _|_ {
-				read: op.#Read & {
-					value: {
-						kind:       "Secret"
-						apiVersion: "v1"
-						metadata: {
-							name:      parameter.email.from.password.secretRef.name
-							namespace: context.namespace
-						}
-					}
-				}
-
-				decoded:     base64.Decode(null, read.value.data[parameter.email.from.password.secretRef.key])
-				stringValue: op.#ConvertString & {bt: decoded}
-				email2:      op.#SendEmail & {
-					from: {
-						address:  parameter.email.from.value
-						alias:    parameter.email.from.alias
-						password: stringValue.str
-						host:     parameter.email.from.host
-						port:     parameter.email.from.port
+	email: op.#Steps & {
+		if parameter.email != _|_ {
+			send: op.#SendEmail & {
+				from: {
+					address: parameter.email.from.address
+					if parameter.email.from.alias != _|_ {
+						alias: parameter.email.from.alias
 					}
-					to:      parameter.email.to
-					content: parameter.email.content
+					password: parameter.email.from.password
+					host:     parameter.email.from.host
+					port:     parameter.email.from.port
 				}
+				to:      parameter.email.to
+				content: parameter.email.content
 			}
 		}
 	}